@@ -0,0 +1,116 @@
+// Package archive implements the finch archive format, a single indexed file of
+// optionally compressed and checksummed blobs that can be read as an fs.FS.
+//
+// An archive is laid out as:
+//
+//	header  | magic "FARC", uint16 version, uint16 reserved
+//	blobs   | entry data, stored or deflate compressed
+//	index   | uint32 entry count, followed by each entry's record
+//	trailer | uint64 index offset, uint32 index crc32, magic "FARC"
+//
+// Each index record holds the entry name, compression method, offset, stored size,
+// raw size, modification time and the SHA-256 of the raw data. All integers are
+// little endian.
+package archive
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"time"
+)
+
+const (
+	Magic   = "FARC"
+	Version = 1
+
+	// Extension is the conventional file extension for finch archives.
+	Extension = ".farc"
+
+	headerSize  = 8
+	trailerSize = 16
+
+	// minEntrySize is the size of an index record with an empty name.
+	minEntrySize = 2 + 1 + 8*4 + 32
+)
+
+var (
+	ErrInvalidArchive   = errors.New("invalid finch archive")
+	ErrInvalidVersion   = errors.New("unsupported finch archive version")
+	ErrChecksumMismatch = errors.New("finch archive checksum mismatch")
+	ErrDuplicateEntry   = errors.New("duplicate finch archive entry")
+	ErrWriterClosed     = errors.New("finch archive writer is closed")
+)
+
+// ======================================================
+// Compression
+// ======================================================
+
+// Compression is the method used to store an entry's data.
+type Compression uint8
+
+const (
+	Store Compression = iota
+	Deflate
+)
+
+func (c Compression) String() string {
+	switch c {
+	case Store:
+		return "store"
+	case Deflate:
+		return "deflate"
+	default:
+		return "unknown"
+	}
+}
+
+func (c Compression) IsValid() bool {
+	return c <= Deflate
+}
+
+// ======================================================
+// Entry
+// ======================================================
+
+// Entry describes a single file stored in an archive.
+type Entry struct {
+	Name        string
+	Compression Compression
+	Offset      int64
+	StoredSize  int64
+	Size        int64
+	ModTime     time.Time
+	Checksum    [32]byte
+}
+
+// entryInfo implements fs.FileInfo for an archive entry.
+type entryInfo struct {
+	entry *Entry
+}
+
+func (i entryInfo) Name() string       { return path.Base(i.entry.Name) }
+func (i entryInfo) Size() int64        { return i.entry.Size }
+func (i entryInfo) Mode() fs.FileMode  { return 0o444 }
+func (i entryInfo) ModTime() time.Time { return i.entry.ModTime }
+func (i entryInfo) IsDir() bool        { return false }
+func (i entryInfo) Sys() any           { return i.entry }
+
+func (i entryInfo) Info() (fs.FileInfo, error) { return i, nil }
+func (i entryInfo) Type() fs.FileMode          { return 0 }
+
+// dirInfo implements fs.FileInfo for a directory implied by entry names.
+type dirInfo struct {
+	name    string
+	modTime time.Time
+}
+
+func (i dirInfo) Name() string       { return path.Base(i.name) }
+func (i dirInfo) Size() int64        { return 0 }
+func (i dirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0o555 }
+func (i dirInfo) ModTime() time.Time { return i.modTime }
+func (i dirInfo) IsDir() bool        { return true }
+func (i dirInfo) Sys() any           { return nil }
+
+func (i dirInfo) Info() (fs.FileInfo, error) { return i, nil }
+func (i dirInfo) Type() fs.FileMode          { return fs.ModeDir }
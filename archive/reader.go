@@ -0,0 +1,379 @@
+package archive
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Archive is a read-only finch archive. It implements fs.FS, fs.ReadFileFS, fs.ReadDirFS
+// and fs.StatFS, so it can be registered directly as an asset filesystem.
+//
+// Entry data is verified against its checksum every time it is read.
+type Archive struct {
+	r       io.ReaderAt
+	closer  io.Closer
+	entries map[string]*Entry
+	order   []*Entry
+	dirs    map[string][]fs.DirEntry
+}
+
+// Open opens the archive file at the given path.
+func Open(name string) (*Archive, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	a, err := NewReader(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	a.closer = file
+
+	return a, nil
+}
+
+// NewReader reads the index of an archive of the given size.
+func NewReader(r io.ReaderAt, size int64) (*Archive, error) {
+	if size < headerSize+trailerSize {
+		return nil, ErrInvalidArchive
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+	if string(header[:4]) != Magic {
+		return nil, ErrInvalidArchive
+	}
+	if version := binary.LittleEndian.Uint16(header[4:]); version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidVersion, version)
+	}
+
+	trailer := make([]byte, trailerSize)
+	if _, err := r.ReadAt(trailer, size-trailerSize); err != nil {
+		return nil, err
+	}
+	if string(trailer[12:]) != Magic {
+		return nil, ErrInvalidArchive
+	}
+
+	indexOffset := int64(binary.LittleEndian.Uint64(trailer))
+	indexEnd := size - trailerSize
+
+	if indexOffset < headerSize || indexOffset > indexEnd {
+		return nil, ErrInvalidArchive
+	}
+
+	index := make([]byte, indexEnd-indexOffset)
+	if _, err := r.ReadAt(index, indexOffset); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(index) != binary.LittleEndian.Uint32(trailer[8:]) {
+		return nil, fmt.Errorf("%w: index", ErrChecksumMismatch)
+	}
+
+	entries, err := decodeIndex(index, indexOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Archive{
+		r:       r,
+		entries: make(map[string]*Entry, len(entries)),
+		order:   entries,
+	}
+
+	for _, entry := range entries {
+		if !fs.ValidPath(entry.Name) {
+			return nil, fmt.Errorf("%w: invalid entry name %q", ErrInvalidArchive, entry.Name)
+		}
+		if _, exists := a.entries[entry.Name]; exists {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateEntry, entry.Name)
+		}
+		a.entries[entry.Name] = entry
+	}
+
+	a.buildDirs()
+
+	return a, nil
+}
+
+// Close closes the underlying file if the archive was opened with Open.
+func (a *Archive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// Entries returns every entry of the archive in the order they were written.
+func (a *Archive) Entries() []Entry {
+	entries := make([]Entry, len(a.order))
+	for i, entry := range a.order {
+		entries[i] = *entry
+	}
+	return entries
+}
+
+// Verify reads every entry and checks it against its checksum.
+func (a *Archive) Verify() error {
+	errs := make([]error, 0)
+	for _, entry := range a.order {
+		if _, err := a.read(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (a *Archive) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if entry, exists := a.entries[name]; exists {
+		data, err := a.read(entry)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return &file{info: entryInfo{entry: entry}, Reader: bytes.NewReader(data)}, nil
+	}
+
+	if entries, exists := a.dirs[name]; exists {
+		return &dir{info: dirInfo{name: name}, entries: entries}, nil
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (a *Archive) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+
+	entry, exists := a.entries[name]
+	if !exists {
+		if _, isDir := a.dirs[name]; isDir {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+		}
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+
+	data, err := a.read(entry)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+
+	return data, nil
+}
+
+func (a *Archive) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	entries, exists := a.dirs[name]
+	if !exists {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return slices.Clone(entries), nil
+}
+
+func (a *Archive) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if entry, exists := a.entries[name]; exists {
+		return entryInfo{entry: entry}, nil
+	}
+
+	if _, exists := a.dirs[name]; exists {
+		return dirInfo{name: name}, nil
+	}
+
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// read returns the raw data of an entry after decompressing and verifying it.
+func (a *Archive) read(entry *Entry) ([]byte, error) {
+	var data []byte
+	var err error
+
+	stored := io.NewSectionReader(a.r, entry.Offset, entry.StoredSize)
+
+	switch entry.Compression {
+	case Store:
+		data = make([]byte, entry.StoredSize)
+		_, err = io.ReadFull(stored, data)
+	case Deflate:
+		fr := flate.NewReader(stored)
+		data, err = io.ReadAll(io.LimitReader(fr, entry.Size+1))
+		fr.Close()
+	default:
+		err = fmt.Errorf("%w: unknown compression %d", ErrInvalidArchive, entry.Compression)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", entry.Name, err)
+	}
+
+	if int64(len(data)) != entry.Size || sha256.Sum256(data) != entry.Checksum {
+		return nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, entry.Name)
+	}
+
+	return data, nil
+}
+
+// buildDirs derives the directory tree from the entry names.
+func (a *Archive) buildDirs() {
+	a.dirs = map[string][]fs.DirEntry{".": nil}
+
+	for _, entry := range a.order {
+		var child fs.DirEntry = entryInfo{entry: entry}
+
+		for name := entry.Name; name != "."; {
+			parent := path.Dir(name)
+			_, known := a.dirs[parent]
+
+			a.dirs[parent] = append(a.dirs[parent], child)
+
+			if known {
+				break
+			}
+
+			child = dirInfo{name: parent}
+			name = parent
+		}
+	}
+
+	for _, entries := range a.dirs {
+		slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+			return strings.Compare(a.Name(), b.Name())
+		})
+	}
+}
+
+func decodeIndex(index []byte, limit int64) ([]*Entry, error) {
+	if len(index) < 4 {
+		return nil, ErrInvalidArchive
+	}
+
+	count := binary.LittleEndian.Uint32(index)
+	index = index[4:]
+
+	// The count is not trusted to size the entries, as every record takes at least minEntrySize bytes.
+	if uint64(count) > uint64(len(index)/minEntrySize) {
+		return nil, fmt.Errorf("%w: %d entries do not fit in the index", ErrInvalidArchive, count)
+	}
+
+	entries := make([]*Entry, 0, count)
+
+	for range count {
+		if len(index) < 2 {
+			return nil, ErrInvalidArchive
+		}
+
+		nameLen := int(binary.LittleEndian.Uint16(index))
+		index = index[2:]
+
+		if len(index) < nameLen+minEntrySize-2 {
+			return nil, ErrInvalidArchive
+		}
+
+		entry := &Entry{
+			Name:        string(index[:nameLen]),
+			Compression: Compression(index[nameLen]),
+		}
+		index = index[nameLen+1:]
+
+		entry.Offset = int64(binary.LittleEndian.Uint64(index))
+		entry.StoredSize = int64(binary.LittleEndian.Uint64(index[8:]))
+		entry.Size = int64(binary.LittleEndian.Uint64(index[16:]))
+
+		if modTime := int64(binary.LittleEndian.Uint64(index[24:])); modTime != 0 {
+			entry.ModTime = time.Unix(0, modTime)
+		}
+
+		copy(entry.Checksum[:], index[32:64])
+		index = index[64:]
+
+		// The stored size is compared against the space left after the offset, as their sum may overflow.
+		if !entry.Compression.IsValid() || entry.Offset < headerSize || entry.Offset > limit ||
+			entry.StoredSize < 0 || entry.StoredSize > limit-entry.Offset || entry.Size < 0 {
+			return nil, fmt.Errorf("%w: entry %s out of range", ErrInvalidArchive, entry.Name)
+		}
+		if entry.Compression == Store && entry.StoredSize != entry.Size {
+			return nil, fmt.Errorf("%w: stored entry %s has a stored size of %d, expected %d", ErrInvalidArchive, entry.Name, entry.StoredSize, entry.Size)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// ======================================================
+// Files
+// ======================================================
+
+type file struct {
+	*bytes.Reader
+	info entryInfo
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Close() error               { return nil }
+
+type dir struct {
+	info    dirInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dir) Close() error               { return nil }
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return slices.Clone(remaining), nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	n = min(n, len(remaining))
+	d.offset += n
+
+	return slices.Clone(remaining[:n]), nil
+}
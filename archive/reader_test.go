@@ -0,0 +1,194 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestDecodeIndex(t *testing.T) {
+	entry := &Entry{Name: "sprites/hero.png", Compression: Store, Offset: headerSize, StoredSize: 4, Size: 4}
+	valid := encodeIndex([]*Entry{entry})
+
+	withCount := func(count uint32) []byte {
+		index := bytes.Clone(valid)
+		binary.LittleEndian.PutUint32(index, count)
+		return index
+	}
+	withSizes := func(stored, size int64) []byte {
+		index := bytes.Clone(valid)
+		binary.LittleEndian.PutUint64(index[4+2+len(entry.Name)+1+8:], uint64(stored))
+		binary.LittleEndian.PutUint64(index[4+2+len(entry.Name)+1+16:], uint64(size))
+		return index
+	}
+	withCompression := func(c Compression) []byte {
+		index := bytes.Clone(valid)
+		index[4+2+len(entry.Name)] = byte(c)
+		return index
+	}
+
+	tests := []struct {
+		name    string
+		index   []byte
+		limit   int64
+		entries int
+		err     error
+	}{
+		{name: "valid", index: valid, limit: 64, entries: 1},
+		{name: "empty", index: encodeIndex(nil), limit: 64},
+		{name: "no count", index: []byte{1, 0}, limit: 64, err: ErrInvalidArchive},
+		{name: "count too large", index: withCount(1 << 31), limit: 64, err: ErrInvalidArchive},
+		{name: "count past records", index: withCount(2), limit: 64, err: ErrInvalidArchive},
+		{name: "truncated record", index: valid[:len(valid)-1], limit: 64, err: ErrInvalidArchive},
+		{name: "entry out of range", index: valid, limit: headerSize + 3, err: ErrInvalidArchive},
+		{name: "invalid compression", index: withCompression(Compression(9)), limit: 64, err: ErrInvalidArchive},
+		{name: "stored size overflows", index: withSizes(math.MaxInt64-4, math.MaxInt64-4), limit: 64, err: ErrInvalidArchive},
+		{name: "negative stored size", index: withSizes(-1, 4), limit: 64, err: ErrInvalidArchive},
+		{name: "negative size", index: withSizes(4, -1), limit: 64, err: ErrInvalidArchive},
+		{name: "stored size differs from size", index: withSizes(4, 5), limit: 64, err: ErrInvalidArchive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := decodeIndex(tt.index, tt.limit)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("decodeIndex() error = %v, want %v", err, tt.err)
+			}
+			if len(entries) != tt.entries {
+				t.Fatalf("decodeIndex() returned %d entries, want %d", len(entries), tt.entries)
+			}
+		})
+	}
+}
+
+func TestEncodeIndexRoundTrip(t *testing.T) {
+	want := []*Entry{
+		{Name: "a.txt", Compression: Store, Offset: headerSize, StoredSize: 3, Size: 3, Checksum: [32]byte{1}},
+		{Name: "dir/b.txt", Compression: Deflate, Offset: headerSize + 3, StoredSize: 5, Size: 9, ModTime: time.Unix(0, 42)},
+	}
+
+	got, err := decodeIndex(encodeIndex(want), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("decoded %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].Compression != want[i].Compression ||
+			got[i].Offset != want[i].Offset || got[i].StoredSize != want[i].StoredSize ||
+			got[i].Size != want[i].Size || !got[i].ModTime.Equal(want[i].ModTime) ||
+			got[i].Checksum != want[i].Checksum {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	files := fstest.MapFS{
+		"a.txt":       {Data: []byte("hello")},
+		"dir/b.txt":   {Data: bytes.Repeat([]byte("finch "), 100)},
+		"dir/c/d.bin": {Data: []byte{0, 1, 2, 3}},
+	}
+
+	for _, compression := range []Compression{Store, Deflate} {
+		t.Run(compression.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewWriter(&buf, compression)
+			if err := w.AddFS(files); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			a, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := a.Verify(); err != nil {
+				t.Fatal(err)
+			}
+			if err := fstest.TestFS(a, "a.txt", "dir/b.txt", "dir/c/d.bin"); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestNewReaderRejectsCorruption(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, Store)
+	if err := w.Add("a.txt", []byte("hello"), time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	valid := buf.Bytes()
+
+	corrupt := func(at int) []byte {
+		data := bytes.Clone(valid)
+		data[at] ^= 0xff
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{name: "too short", data: valid[:headerSize], err: ErrInvalidArchive},
+		{name: "magic", data: corrupt(0), err: ErrInvalidArchive},
+		{name: "version", data: corrupt(4), err: ErrInvalidVersion},
+		{name: "index", data: corrupt(len(valid) - trailerSize - 1), err: ErrChecksumMismatch},
+		{name: "trailer magic", data: corrupt(len(valid) - 1), err: ErrInvalidArchive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReader(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("NewReader() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+
+	t.Run("entry size", func(t *testing.T) {
+		// An index whose entry claims an enormous size, with a recomputed checksum, must fail to open
+		// instead of panicking when the entry is read.
+		data := bytes.Clone(valid)
+		trailer := data[len(data)-trailerSize:]
+		index := data[binary.LittleEndian.Uint64(trailer) : len(data)-trailerSize]
+		binary.LittleEndian.PutUint64(index[4+2+len("a.txt")+1+8:], math.MaxInt64-4)
+		binary.LittleEndian.PutUint32(trailer[8:], crc32.ChecksumIEEE(index))
+
+		if _, err := NewReader(bytes.NewReader(data), int64(len(data))); !errors.Is(err, ErrInvalidArchive) {
+			t.Fatalf("NewReader() error = %v, want %v", err, ErrInvalidArchive)
+		}
+	})
+
+	t.Run("blob", func(t *testing.T) {
+		data := corrupt(headerSize)
+		a, err := NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := a.Verify(); !errors.Is(err, ErrChecksumMismatch) {
+			t.Fatalf("Verify() error = %v, want %v", err, ErrChecksumMismatch)
+		}
+		f, err := a.Open("a.txt")
+		if err == nil {
+			_, err = io.ReadAll(f)
+		}
+		if !errors.Is(err, ErrChecksumMismatch) {
+			t.Fatalf("Open() error = %v, want %v", err, ErrChecksumMismatch)
+		}
+	})
+}
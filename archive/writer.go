@@ -0,0 +1,208 @@
+package archive
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"math"
+	"path"
+	"time"
+
+	"github.com/adm87/finch-core/hashset"
+)
+
+// Writer builds an archive by streaming entry data to an underlying writer.
+//
+// The index is written when the writer is closed, so the underlying writer does not
+// need to support seeking.
+type Writer struct {
+	w           io.Writer
+	offset      int64
+	compression Compression
+	entries     []*Entry
+	names       hashset.Set[string]
+	closed      bool
+}
+
+// NewWriter returns a writer that stores new entries with the given compression.
+//
+// Compressed entries are stored uncompressed if compression does not reduce their size.
+func NewWriter(w io.Writer, compression Compression) *Writer {
+	return &Writer{
+		w:           w,
+		compression: compression,
+		names:       hashset.New[string](),
+	}
+}
+
+// Add writes a file to the archive. Names must be valid fs.FS paths and unique within the archive.
+func (w *Writer) Add(name string, data []byte, modTime time.Time) error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	if !fs.ValidPath(name) || name == "." || len(name) > math.MaxUint16 {
+		return &fs.PathError{Op: "add", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if w.names.Contains(name) {
+		return fmt.Errorf("%w: %s", ErrDuplicateEntry, name)
+	}
+
+	if w.offset == 0 {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	stored, compression, err := w.compress(data)
+	if err != nil {
+		return err
+	}
+
+	entry := &Entry{
+		Name:        name,
+		Compression: compression,
+		Offset:      w.offset,
+		StoredSize:  int64(len(stored)),
+		Size:        int64(len(data)),
+		ModTime:     modTime,
+		Checksum:    sha256.Sum256(data),
+	}
+
+	if err := w.write(stored); err != nil {
+		return err
+	}
+
+	w.entries = append(w.entries, entry)
+	w.names.Add(name)
+
+	return nil
+}
+
+// AddFS adds every regular file of a filesystem to the archive, keeping their paths.
+func (w *Writer) AddFS(fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return w.AddFile(fsys, name, name)
+	})
+}
+
+// AddFile copies a single file from a filesystem into the archive under the given name.
+func (w *Writer) AddFile(fsys fs.FS, src, name string) error {
+	info, err := fs.Stat(fsys, src)
+	if err != nil {
+		return err
+	}
+
+	data, err := fs.ReadFile(fsys, src)
+	if err != nil {
+		return err
+	}
+
+	return w.Add(path.Clean(name), data, info.ModTime())
+}
+
+// Close writes the index and trailer. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	if w.offset == 0 {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	index := encodeIndex(w.entries)
+	indexOffset := w.offset
+
+	if err := w.write(index); err != nil {
+		return err
+	}
+
+	trailer := make([]byte, 0, trailerSize)
+	trailer = binary.LittleEndian.AppendUint64(trailer, uint64(indexOffset))
+	trailer = binary.LittleEndian.AppendUint32(trailer, crc32.ChecksumIEEE(index))
+	trailer = append(trailer, Magic...)
+
+	if err := w.write(trailer); err != nil {
+		return err
+	}
+
+	w.closed = true
+
+	return nil
+}
+
+func (w *Writer) writeHeader() error {
+	header := make([]byte, 0, headerSize)
+	header = append(header, Magic...)
+	header = binary.LittleEndian.AppendUint16(header, Version)
+	header = binary.LittleEndian.AppendUint16(header, 0)
+	return w.write(header)
+}
+
+func (w *Writer) write(data []byte) error {
+	n, err := w.w.Write(data)
+	w.offset += int64(n)
+	return err
+}
+
+func (w *Writer) compress(data []byte) ([]byte, Compression, error) {
+	if w.compression != Deflate || len(data) == 0 {
+		return data, Store, nil
+	}
+
+	var buf bytes.Buffer
+
+	fw, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, Store, err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return nil, Store, err
+	}
+	if err := fw.Close(); err != nil {
+		return nil, Store, err
+	}
+
+	if buf.Len() >= len(data) {
+		return data, Store, nil
+	}
+
+	return buf.Bytes(), Deflate, nil
+}
+
+func encodeIndex(entries []*Entry) []byte {
+	index := binary.LittleEndian.AppendUint32(nil, uint32(len(entries)))
+
+	for _, entry := range entries {
+		modTime := int64(0)
+		if !entry.ModTime.IsZero() {
+			modTime = entry.ModTime.UnixNano()
+		}
+
+		index = binary.LittleEndian.AppendUint16(index, uint16(len(entry.Name)))
+		index = append(index, entry.Name...)
+		index = append(index, byte(entry.Compression))
+		index = binary.LittleEndian.AppendUint64(index, uint64(entry.Offset))
+		index = binary.LittleEndian.AppendUint64(index, uint64(entry.StoredSize))
+		index = binary.LittleEndian.AppendUint64(index, uint64(entry.Size))
+		index = binary.LittleEndian.AppendUint64(index, uint64(modTime))
+		index = append(index, entry.Checksum[:]...)
+	}
+
+	return index
+}
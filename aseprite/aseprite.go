@@ -0,0 +1,266 @@
+// Package aseprite decodes Aseprite (.aseprite, .ase) files into their frames, layers,
+// cels and tags, and composites frames into images.
+//
+// Layer blend modes other than normal are composited as normal. Tilemap layers are
+// decoded but not composited.
+package aseprite
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"time"
+)
+
+const (
+	fileMagic       = 0xA5E0
+	frameMagic      = 0xF1FA
+	headerSize      = 128
+	frameHeaderSize = 16
+
+	// MaxPaletteSize is the largest palette a file may declare.
+	MaxPaletteSize = 65536
+)
+
+var (
+	ErrInvalidMagic      = errors.New("aseprite: invalid magic number")
+	ErrInvalidColorDepth = errors.New("aseprite: invalid color depth")
+	ErrInvalidChunk      = errors.New("aseprite: invalid chunk")
+	ErrInvalidFrame      = errors.New("aseprite: frame index out of range")
+	ErrInvalidFrameSize  = errors.New("aseprite: invalid frame size")
+	ErrInvalidPalette    = errors.New("aseprite: invalid palette")
+	ErrInvalidCel        = errors.New("aseprite: invalid cel")
+)
+
+// ======================================================
+// Color Depth
+// ======================================================
+
+// ColorDepth is the number of bits per pixel of an Aseprite file.
+type ColorDepth int
+
+const (
+	ColorDepthIndexed   ColorDepth = 8
+	ColorDepthGrayscale ColorDepth = 16
+	ColorDepthRGBA      ColorDepth = 32
+)
+
+func (d ColorDepth) String() string {
+	switch d {
+	case ColorDepthIndexed:
+		return "indexed"
+	case ColorDepthGrayscale:
+		return "grayscale"
+	case ColorDepthRGBA:
+		return "rgba"
+	default:
+		return "unknown"
+	}
+}
+
+func (d ColorDepth) IsValid() bool {
+	return d == ColorDepthIndexed || d == ColorDepthGrayscale || d == ColorDepthRGBA
+}
+
+// ======================================================
+// Layer
+// ======================================================
+
+// LayerType is the kind of an Aseprite layer.
+type LayerType int
+
+const (
+	LayerNormal LayerType = iota
+	LayerGroup
+	LayerTilemap
+)
+
+func (t LayerType) String() string {
+	switch t {
+	case LayerNormal:
+		return "normal"
+	case LayerGroup:
+		return "group"
+	case LayerTilemap:
+		return "tilemap"
+	default:
+		return "unknown"
+	}
+}
+
+func (t LayerType) IsValid() bool {
+	return t >= LayerNormal && t <= LayerTilemap
+}
+
+// LayerFlags are the flags of an Aseprite layer.
+type LayerFlags uint16
+
+const (
+	LayerVisible LayerFlags = 1 << iota
+	LayerEditable
+	LayerLockMovement
+	LayerBackground
+	LayerPreferLinkedCels
+	LayerCollapsed
+	LayerReference
+)
+
+// Layer is a layer of an Aseprite file. Layers are listed bottom to top, and groups
+// precede their children, which have a higher child level.
+type Layer struct {
+	Name       string
+	Type       LayerType
+	Flags      LayerFlags
+	ChildLevel int
+	BlendMode  int
+	Opacity    uint8
+	Tileset    int
+}
+
+// Visible reports whether the layer's own visibility flag is set. See File.LayerVisible
+// for visibility that accounts for parent groups.
+func (l Layer) Visible() bool {
+	return l.Flags&LayerVisible != 0
+}
+
+// ======================================================
+// Frame
+// ======================================================
+
+// Frame is a frame of an Aseprite file.
+type Frame struct {
+	Duration time.Duration
+	Cels     []Cel
+}
+
+// Cel is the image of a layer in a frame.
+type Cel struct {
+	Layer   int
+	X       int
+	Y       int
+	Opacity uint8
+	ZIndex  int
+	Image   *image.NRGBA // Nil for tilemap cels. Linked cels share the image of the cel they link to
+	Linked  int          // Frame the cel links to, or -1
+}
+
+// ======================================================
+// Tag
+// ======================================================
+
+// Direction is the playback direction of an animation tag.
+type Direction int
+
+const (
+	DirectionForward Direction = iota
+	DirectionReverse
+	DirectionPingPong
+	DirectionPingPongReverse
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionForward:
+		return "forward"
+	case DirectionReverse:
+		return "reverse"
+	case DirectionPingPong:
+		return "pingpong"
+	case DirectionPingPongReverse:
+		return "pingpong_reverse"
+	default:
+		return "unknown"
+	}
+}
+
+func (d Direction) IsValid() bool {
+	return d >= DirectionForward && d <= DirectionPingPongReverse
+}
+
+// Tag is a named range of frames played as an animation.
+type Tag struct {
+	Name      string
+	From      int
+	To        int
+	Direction Direction
+	Repeat    int // Number of times the animation plays, or zero for infinitely
+}
+
+// Frames returns the frame indices of one pass of the tag's animation, in playback order.
+func (t Tag) Frames() []int {
+	forward := make([]int, 0, t.To-t.From+1)
+	for i := t.From; i <= t.To; i++ {
+		forward = append(forward, i)
+	}
+
+	reverse := make([]int, len(forward))
+	for i, frame := range forward {
+		reverse[len(forward)-1-i] = frame
+	}
+
+	switch t.Direction {
+	case DirectionReverse:
+		return reverse
+	case DirectionPingPong:
+		if len(forward) < 2 {
+			return forward
+		}
+		return append(forward, reverse[1:len(reverse)-1]...)
+	case DirectionPingPongReverse:
+		if len(reverse) < 2 {
+			return reverse
+		}
+		return append(reverse, forward[1:len(forward)-1]...)
+	default:
+		return forward
+	}
+}
+
+// ======================================================
+// File
+// ======================================================
+
+// File is a decoded Aseprite file.
+type File struct {
+	Width            int
+	Height           int
+	ColorDepth       ColorDepth
+	TransparentIndex uint8
+	Palette          color.Palette
+	Layers           []Layer
+	Frames           []Frame
+	Tags             []Tag
+}
+
+// Tag returns the tag with the given name.
+func (f *File) Tag(name string) (Tag, bool) {
+	for _, tag := range f.Tags {
+		if tag.Name == name {
+			return tag, true
+		}
+	}
+	return Tag{}, false
+}
+
+// LayerVisible reports whether a layer and all of its parent groups are visible.
+func (f *File) LayerVisible(index int) bool {
+	if index < 0 || index >= len(f.Layers) {
+		return false
+	}
+
+	level := f.Layers[index].ChildLevel
+	if !f.Layers[index].Visible() {
+		return false
+	}
+
+	for i := index - 1; i >= 0 && level > 0; i-- {
+		if f.Layers[i].ChildLevel < level {
+			if !f.Layers[i].Visible() {
+				return false
+			}
+			level = f.Layers[i].ChildLevel
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,47 @@
+package aseprite
+
+import (
+	"cmp"
+	"image"
+	"image/color"
+	"image/draw"
+	"slices"
+)
+
+// Composite flattens the visible layers of a frame into an image the size of the file.
+func (f *File) Composite(frame int) (*image.NRGBA, error) {
+	if frame < 0 || frame >= len(f.Frames) {
+		return nil, ErrInvalidFrame
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, f.Width, f.Height))
+
+	cels := slices.Clone(f.Frames[frame].Cels)
+	slices.SortStableFunc(cels, func(a, b Cel) int {
+		if order := cmp.Compare(a.Layer+a.ZIndex, b.Layer+b.ZIndex); order != 0 {
+			return order
+		}
+		return cmp.Compare(a.ZIndex, b.ZIndex)
+	})
+
+	for _, cel := range cels {
+		if cel.Image == nil || !f.LayerVisible(cel.Layer) {
+			continue
+		}
+
+		layer := f.Layers[cel.Layer]
+		if layer.Type != LayerNormal || layer.Flags&LayerReference != 0 {
+			continue
+		}
+
+		opacity := uint8(int(cel.Opacity) * int(layer.Opacity) / 255)
+		if opacity == 0 {
+			continue
+		}
+
+		bounds := cel.Image.Bounds().Add(image.Pt(cel.X, cel.Y))
+		draw.DrawMask(dst, bounds, cel.Image, image.Point{}, image.NewUniform(color.Alpha{A: opacity}), image.Point{}, draw.Over)
+	}
+
+	return dst, nil
+}
@@ -0,0 +1,442 @@
+package aseprite
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"time"
+)
+
+const (
+	chunkOldPalette = 0x0004
+	chunkLayer      = 0x2004
+	chunkCel        = 0x2005
+	chunkTags       = 0x2018
+	chunkPalette    = 0x2019
+
+	celRaw        = 0
+	celLinked     = 1
+	celCompressed = 2
+	celTilemap    = 3
+
+	headerFlagLayerOpacity = 1
+)
+
+// Decode reads an Aseprite file.
+func Decode(r io.Reader) (*File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBytes(data)
+}
+
+// DecodeBytes decodes an Aseprite file held in memory.
+func DecodeBytes(data []byte) (*File, error) {
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("%w: file is too short", ErrInvalidMagic)
+	}
+
+	header := reader{data: data[:headerSize]}
+	header.skip(4)
+	if header.u16() != fileMagic {
+		return nil, ErrInvalidMagic
+	}
+
+	frameCount := int(header.u16())
+
+	d := &decoder{
+		file: &File{
+			Width:  int(header.u16()),
+			Height: int(header.u16()),
+		},
+	}
+
+	d.file.ColorDepth = ColorDepth(header.u16())
+	if !d.file.ColorDepth.IsValid() {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidColorDepth, d.file.ColorDepth)
+	}
+
+	d.layerOpacity = header.u32()&headerFlagLayerOpacity != 0
+	header.skip(10)
+	d.file.TransparentIndex = header.u8()
+
+	body := reader{data: data[headerSize:]}
+	for i := range frameCount {
+		if err := d.decodeFrame(&body); err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, err)
+		}
+	}
+
+	if err := d.resolveLinks(); err != nil {
+		return nil, err
+	}
+
+	return d.file, nil
+}
+
+type decoder struct {
+	file         *File
+	layerOpacity bool
+	newPalette   bool
+}
+
+func (d *decoder) decodeFrame(r *reader) error {
+	start := r.pos
+
+	size := int(r.u32())
+	if r.u16() != frameMagic {
+		return ErrInvalidMagic
+	}
+	if size < frameHeaderSize {
+		return fmt.Errorf("%w: %d", ErrInvalidFrameSize, size)
+	}
+
+	oldChunks := int(r.u16())
+	duration := time.Duration(r.u16()) * time.Millisecond
+	r.skip(2)
+	chunks := int(r.u32())
+	if chunks == 0 {
+		chunks = oldChunks
+	}
+
+	if r.err != nil {
+		return r.err
+	}
+
+	frame := Frame{Duration: duration}
+
+	for range chunks {
+		chunkSize := int(r.u32())
+		chunkType := r.u16()
+		if r.err != nil || chunkSize < 6 {
+			return fmt.Errorf("%w: size %d", ErrInvalidChunk, chunkSize)
+		}
+
+		chunk := reader{data: r.bytes(chunkSize - 6)}
+		if r.err != nil {
+			return fmt.Errorf("%w: type %#04x is truncated", ErrInvalidChunk, chunkType)
+		}
+
+		if err := d.decodeChunk(chunkType, &chunk, &frame); err != nil {
+			return fmt.Errorf("%w: type %#04x: %w", ErrInvalidChunk, chunkType, err)
+		}
+	}
+
+	d.file.Frames = append(d.file.Frames, frame)
+
+	r.pos = start + size
+	return nil
+}
+
+func (d *decoder) decodeChunk(chunkType uint16, r *reader, frame *Frame) error {
+	switch chunkType {
+	case chunkLayer:
+		d.decodeLayer(r)
+	case chunkCel:
+		cel, err := d.decodeCel(r)
+		if err != nil {
+			return err
+		}
+		frame.Cels = append(frame.Cels, cel)
+	case chunkTags:
+		d.decodeTags(r)
+	case chunkPalette:
+		if err := d.decodePalette(r); err != nil {
+			return err
+		}
+	case chunkOldPalette:
+		if !d.newPalette {
+			if err := d.decodeOldPalette(r); err != nil {
+				return err
+			}
+		}
+	}
+	return r.err
+}
+
+func (d *decoder) decodeLayer(r *reader) {
+	layer := Layer{
+		Flags:      LayerFlags(r.u16()),
+		Type:       LayerType(r.u16()),
+		ChildLevel: int(r.u16()),
+	}
+	r.skip(4)
+	layer.BlendMode = int(r.u16())
+	layer.Opacity = r.u8()
+	r.skip(3)
+	layer.Name = r.string()
+
+	if !d.layerOpacity {
+		layer.Opacity = 255
+	}
+
+	if layer.Type == LayerTilemap {
+		layer.Tileset = int(r.u32())
+	}
+
+	d.file.Layers = append(d.file.Layers, layer)
+}
+
+func (d *decoder) decodeCel(r *reader) (Cel, error) {
+	cel := Cel{
+		Layer:   int(r.u16()),
+		X:       int(r.i16()),
+		Y:       int(r.i16()),
+		Opacity: r.u8(),
+		Linked:  -1,
+	}
+	celType := r.u16()
+	cel.ZIndex = int(r.i16())
+	r.skip(5)
+
+	switch celType {
+	case celRaw:
+		w, h, err := d.celSize(r)
+		if err != nil {
+			return cel, err
+		}
+		img, err := d.decodePixels(r.bytes(w*h*d.bytesPerPixel()), w, h)
+		if err != nil {
+			return cel, err
+		}
+		cel.Image = img
+	case celLinked:
+		cel.Linked = int(r.u16())
+	case celCompressed:
+		w, h, err := d.celSize(r)
+		if err != nil {
+			return cel, err
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(r.rest()))
+		if err != nil {
+			return cel, err
+		}
+		defer zr.Close()
+
+		// The pixels are read as they decompress, so a cel that claims more pixels than it holds
+		// does not allocate them up front.
+		pixels, err := io.ReadAll(io.LimitReader(zr, int64(w*h*d.bytesPerPixel())))
+		if err != nil {
+			return cel, err
+		}
+
+		img, err := d.decodePixels(pixels, w, h)
+		if err != nil {
+			return cel, err
+		}
+		cel.Image = img
+	case celTilemap:
+	default:
+		return cel, fmt.Errorf("unknown cel type %d", celType)
+	}
+
+	return cel, r.err
+}
+
+// celSize reads the size of a cel image, which must fit within the sprite.
+func (d *decoder) celSize(r *reader) (int, int, error) {
+	w, h := int(r.u16()), int(r.u16())
+	if r.err != nil {
+		return 0, 0, r.err
+	}
+	if w > d.file.Width || h > d.file.Height {
+		return 0, 0, fmt.Errorf("%w: %dx%d is larger than the %dx%d sprite", ErrInvalidCel, w, h, d.file.Width, d.file.Height)
+	}
+	return w, h, nil
+}
+
+func (d *decoder) decodeTags(r *reader) {
+	count := int(r.u16())
+	r.skip(8)
+
+	for range count {
+		tag := Tag{
+			From:      int(r.u16()),
+			To:        int(r.u16()),
+			Direction: Direction(r.u8()),
+			Repeat:    int(r.u16()),
+		}
+		r.skip(10)
+		tag.Name = r.string()
+
+		d.file.Tags = append(d.file.Tags, tag)
+	}
+}
+
+func (d *decoder) decodePalette(r *reader) error {
+	size := int(r.u32())
+	first := int(r.u32())
+	last := int(r.u32())
+	r.skip(8)
+
+	if size > MaxPaletteSize || first > last || last >= size {
+		return fmt.Errorf("%w: entries %d to %d of %d", ErrInvalidPalette, first, last, size)
+	}
+
+	d.newPalette = true
+	d.growPalette(size)
+
+	for i := first; i <= last && r.err == nil; i++ {
+		flags := r.u16()
+		c := color.NRGBA{R: r.u8(), G: r.u8(), B: r.u8(), A: r.u8()}
+		if flags&1 != 0 {
+			r.string()
+		}
+		d.file.Palette[i] = c
+	}
+
+	return nil
+}
+
+func (d *decoder) decodeOldPalette(r *reader) error {
+	packets := int(r.u16())
+	index := 0
+
+	for range packets {
+		index += int(r.u8())
+		count := int(r.u8())
+		if count == 0 {
+			count = 256
+		}
+
+		if index+count > MaxPaletteSize {
+			return fmt.Errorf("%w: %d entries", ErrInvalidPalette, index+count)
+		}
+
+		d.growPalette(index + count)
+		for range count {
+			d.file.Palette[index] = color.NRGBA{R: r.u8(), G: r.u8(), B: r.u8(), A: 255}
+			index++
+		}
+	}
+
+	return nil
+}
+
+func (d *decoder) growPalette(size int) {
+	for len(d.file.Palette) < size {
+		d.file.Palette = append(d.file.Palette, color.NRGBA{})
+	}
+}
+
+func (d *decoder) bytesPerPixel() int {
+	return int(d.file.ColorDepth) / 8
+}
+
+// decodePixels converts pixels of the file's color depth to an NRGBA image.
+func (d *decoder) decodePixels(pixels []byte, w, h int) (*image.NRGBA, error) {
+	if len(pixels) != w*h*d.bytesPerPixel() {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	switch d.file.ColorDepth {
+	case ColorDepthRGBA:
+		copy(img.Pix, pixels)
+	case ColorDepthGrayscale:
+		for i := range w * h {
+			v, a := pixels[i*2], pixels[i*2+1]
+			copy(img.Pix[i*4:], []byte{v, v, v, a})
+		}
+	case ColorDepthIndexed:
+		for i, index := range pixels {
+			if index == d.file.TransparentIndex || int(index) >= len(d.file.Palette) {
+				continue
+			}
+			c := color.NRGBAModel.Convert(d.file.Palette[index]).(color.NRGBA)
+			copy(img.Pix[i*4:], []byte{c.R, c.G, c.B, c.A})
+		}
+	}
+
+	return img, nil
+}
+
+// resolveLinks points linked cels at the image of the cel they link to.
+func (d *decoder) resolveLinks() error {
+	for i, frame := range d.file.Frames {
+		for j, cel := range frame.Cels {
+			if cel.Linked < 0 {
+				continue
+			}
+
+			if cel.Linked >= len(d.file.Frames) {
+				return fmt.Errorf("%w: frame %d links to frame %d", ErrInvalidFrame, i, cel.Linked)
+			}
+
+			for _, target := range d.file.Frames[cel.Linked].Cels {
+				if target.Layer == cel.Layer && target.Linked < 0 {
+					d.file.Frames[i].Cels[j].Image = target.Image
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ======================================================
+// Reader
+// ======================================================
+
+// reader reads little endian values from a byte slice, recording the first out of bounds read.
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.data) {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) rest() []byte {
+	return r.bytes(len(r.data) - r.pos)
+}
+
+func (r *reader) skip(n int) {
+	r.bytes(n)
+}
+
+func (r *reader) u8() uint8 {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *reader) u16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *reader) i16() int16 {
+	return int16(r.u16())
+}
+
+func (r *reader) u32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *reader) string() string {
+	return string(r.bytes(int(r.u16())))
+}
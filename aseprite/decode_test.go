@@ -0,0 +1,201 @@
+package aseprite
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+// builder writes little endian values of a test file.
+type builder struct {
+	bytes.Buffer
+}
+
+func (b *builder) u8(v uint8)   { b.WriteByte(v) }
+func (b *builder) u16(v uint16) { binary.Write(b, binary.LittleEndian, v) }
+func (b *builder) u32(v uint32) { binary.Write(b, binary.LittleEndian, v) }
+func (b *builder) zeros(n int)  { b.Write(make([]byte, n)) }
+
+// chunk returns a chunk of a type with its size prepended.
+func chunk(chunkType uint16, body []byte) []byte {
+	var b builder
+	b.u32(uint32(6 + len(body)))
+	b.u16(chunkType)
+	b.Write(body)
+	return b.Bytes()
+}
+
+// file returns an RGBA file of a size with a single frame of chunks.
+func file(w, h int, chunks ...[]byte) []byte {
+	var frame builder
+	for _, c := range chunks {
+		frame.Write(c)
+	}
+
+	var b builder
+	b.u32(0)
+	b.u16(fileMagic)
+	b.u16(1)
+	b.u16(uint16(w))
+	b.u16(uint16(h))
+	b.u16(uint16(ColorDepthRGBA))
+	b.u32(headerFlagLayerOpacity)
+	b.zeros(headerSize - b.Len())
+
+	b.u32(uint32(frameHeaderSize + frame.Len()))
+	b.u16(frameMagic)
+	b.u16(uint16(len(chunks)))
+	b.u16(100)
+	b.zeros(2)
+	b.u32(uint32(len(chunks)))
+	b.Write(frame.Bytes())
+
+	return b.Bytes()
+}
+
+func layerChunk(name string) []byte {
+	var b builder
+	b.u16(uint16(LayerVisible))
+	b.u16(uint16(LayerNormal))
+	b.u16(0)
+	b.zeros(4)
+	b.u16(0)
+	b.u8(255)
+	b.zeros(3)
+	b.u16(uint16(len(name)))
+	b.WriteString(name)
+	return chunk(chunkLayer, b.Bytes())
+}
+
+func celHeader(b *builder, celType uint16) {
+	b.u16(0)
+	b.u16(0)
+	b.u16(0)
+	b.u8(255)
+	b.u16(celType)
+	b.u16(0)
+	b.zeros(5)
+}
+
+func rawCelChunk(w, h int, pixels []byte) []byte {
+	var b builder
+	celHeader(&b, celRaw)
+	b.u16(uint16(w))
+	b.u16(uint16(h))
+	b.Write(pixels)
+	return chunk(chunkCel, b.Bytes())
+}
+
+func compressedCelChunk(w, h int, pixels []byte) []byte {
+	var b builder
+	celHeader(&b, celCompressed)
+	b.u16(uint16(w))
+	b.u16(uint16(h))
+	zw := zlib.NewWriter(&b)
+	zw.Write(pixels)
+	zw.Close()
+	return chunk(chunkCel, b.Bytes())
+}
+
+func paletteChunk(size, first, last uint32) []byte {
+	var b builder
+	b.u32(size)
+	b.u32(first)
+	b.u32(last)
+	b.zeros(8)
+	for i := first; i <= last && i-first < 4; i++ {
+		b.u16(0)
+		b.u8(uint8(i))
+		b.u8(0)
+		b.u8(0)
+		b.u8(255)
+	}
+	return chunk(chunkPalette, b.Bytes())
+}
+
+func oldPaletteChunk(skip uint8, count uint8) []byte {
+	var b builder
+	b.u16(1)
+	b.u8(skip)
+	b.u8(count)
+	n := int(count)
+	if n == 0 {
+		n = 256
+	}
+	b.zeros(n * 3)
+	return chunk(chunkOldPalette, b.Bytes())
+}
+
+func TestDecodeBytes(t *testing.T) {
+	pixels := bytes.Repeat([]byte{1, 2, 3, 255}, 4)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		err     error
+		cels    int
+		palette int
+	}{
+		{name: "empty frame", data: file(2, 2)},
+		{name: "raw cel", data: file(2, 2, layerChunk("a"), rawCelChunk(2, 2, pixels)), cels: 1},
+		{name: "compressed cel", data: file(2, 2, layerChunk("a"), compressedCelChunk(2, 2, pixels)), cels: 1},
+		{name: "palette", data: file(2, 2, paletteChunk(4, 0, 3)), palette: 4},
+		{name: "old palette", data: file(2, 2, oldPaletteChunk(0, 4)), palette: 4},
+		{name: "too short", data: []byte{1, 2, 3}, err: ErrInvalidMagic},
+		{name: "truncated frame", data: file(2, 2, rawCelChunk(2, 2, pixels))[:headerSize+8], err: io.ErrUnexpectedEOF},
+		{name: "raw cel larger than sprite", data: file(2, 2, rawCelChunk(3, 2, bytes.Repeat(pixels, 2))), err: ErrInvalidCel},
+		{name: "compressed cel larger than sprite", data: file(2, 2, compressedCelChunk(65535, 65535, pixels)), err: ErrInvalidCel},
+		{name: "compressed cel short", data: file(2, 2, compressedCelChunk(2, 2, pixels[:8])), err: io.ErrUnexpectedEOF},
+		{name: "raw cel short", data: file(2, 2, rawCelChunk(2, 2, pixels[:8])), err: io.ErrUnexpectedEOF},
+		{name: "palette too large", data: file(2, 2, paletteChunk(MaxPaletteSize+1, 0, 3)), err: ErrInvalidPalette},
+		{name: "palette range past size", data: file(2, 2, paletteChunk(4, 0, 4)), err: ErrInvalidPalette},
+		{name: "palette range reversed", data: file(2, 2, paletteChunk(4, 3, 0)), err: ErrInvalidPalette},
+		{name: "old palette skip", data: file(2, 2, oldPaletteChunk(255, 0)), palette: 511},
+		{name: "chunk size too small", data: file(2, 2, []byte{2, 0, 0, 0, 0x04, 0x20}), err: ErrInvalidChunk},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := DecodeBytes(tt.data)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("DecodeBytes() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if len(f.Frames) != 1 || len(f.Frames[0].Cels) != tt.cels {
+				t.Fatalf("decoded %d frames with %d cels, want 1 with %d", len(f.Frames), len(f.Frames[0].Cels), tt.cels)
+			}
+			if len(f.Palette) != tt.palette {
+				t.Fatalf("palette has %d entries, want %d", len(f.Palette), tt.palette)
+			}
+		})
+	}
+}
+
+func TestDecodeOldPaletteBound(t *testing.T) {
+	var b builder
+	b.u16(257)
+	for range 257 {
+		b.u8(255)
+		b.u8(0)
+		b.zeros(256 * 3)
+	}
+
+	_, err := DecodeBytes(file(2, 2, chunk(chunkOldPalette, b.Bytes())))
+	if !errors.Is(err, ErrInvalidPalette) {
+		t.Fatalf("DecodeBytes() error = %v, want %v", err, ErrInvalidPalette)
+	}
+}
+
+func TestDecodeFrameSize(t *testing.T) {
+	data := file(2, 2)
+	binary.LittleEndian.PutUint32(data[headerSize:], 0)
+
+	if _, err := DecodeBytes(data); !errors.Is(err, ErrInvalidFrameSize) {
+		t.Fatalf("DecodeBytes() error = %v, want %v", err, ErrInvalidFrameSize)
+	}
+}
@@ -0,0 +1,231 @@
+// Command finch-archive builds, lists and verifies finch archives.
+//
+// Usage:
+//
+//	finch-archive build [-o assets.farc] [-store] [-manifest manifest.yaml -root assets] <dir>
+//	finch-archive list <archive>
+//	finch-archive verify <archive>
+//
+// When a manifest is given, only the files matched by its groups are packed. The root
+// names the AssetRoot the manifest patterns are written against, which maps to dir.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/adm87/finch-core/archive"
+	"github.com/adm87/finch-core/fsys"
+	"github.com/adm87/finch-core/hashset"
+	"github.com/adm87/finch-core/internal/assets"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+
+	switch os.Args[1] {
+	case "build":
+		err = build(os.Args[2:])
+	case "list":
+		err = list(os.Args[2:])
+	case "verify":
+		err = verify(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "finch-archive:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  finch-archive build [-o assets.farc] [-store] [-manifest manifest.yaml -root assets] <dir>")
+	fmt.Fprintln(os.Stderr, "  finch-archive list <archive>")
+	fmt.Fprintln(os.Stderr, "  finch-archive verify <archive>")
+}
+
+func build(args []string) error {
+	flags := flag.NewFlagSet("build", flag.ExitOnError)
+	output := flags.String("o", "assets"+archive.Extension, "output archive path")
+	store := flags.Bool("store", false, "store entries without compression")
+	manifestPath := flags.String("manifest", "", "only pack files matched by the groups of this manifest")
+	root := flags.String("root", "assets", "asset root the manifest patterns are relative to")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("build requires exactly one source directory")
+	}
+
+	dir := flags.Arg(0)
+	src := os.DirFS(dir)
+
+	// An output inside the source directory, such as the archive of a previous build, is not packed
+	// into itself.
+	skip, err := outputName(dir, *output)
+	if err != nil {
+		return err
+	}
+
+	names, err := sourceFiles(src, assets.AssetRoot(*root), *manifestPath)
+	if err != nil {
+		return err
+	}
+	names = slices.DeleteFunc(names, func(name string) bool {
+		return name == skip
+	})
+
+	compression := archive.Deflate
+	if *store {
+		compression = archive.Store
+	}
+
+	err = fsys.WriteAtomic(*output, 0o644, func(out io.Writer) error {
+		w := archive.NewWriter(out, compression)
+		for _, name := range names {
+			if err := w.AddFile(src, name, name); err != nil {
+				return err
+			}
+		}
+		return w.Close()
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("packed %d files into %s\n", len(names), *output)
+
+	return nil
+}
+
+// outputName returns the name of the output file relative to the source directory, or "" if the output
+// is not inside it.
+func outputName(dir, output string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	output, err = filepath.Abs(output)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(dir, output)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// sourceFiles returns the sorted names of the files to pack, relative to the source directory.
+func sourceFiles(src fs.FS, root assets.AssetRoot, manifestPath string) ([]string, error) {
+	if manifestPath == "" {
+		names := make([]string, 0)
+		err := fs.WalkDir(src, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				names = append(names, name)
+			}
+			return nil
+		})
+		return names, err
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := assets.ParseAssetManifest(assets.AssetType(strings.TrimPrefix(filepath.Ext(manifestPath), ".")), data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", manifestPath, err)
+	}
+
+	if err := assets.RegisterAssetFilesystem(root, src); err != nil {
+		return nil, err
+	}
+
+	names := hashset.New[string]()
+	for group := range manifest.Groups {
+		files, err := manifest.Resolve(group)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			name, ok := strings.CutPrefix(file.Path(), root.String()+"/")
+			if !ok {
+				return nil, fmt.Errorf("group %s: %s is outside of root %s", group, file, root)
+			}
+			names.Add(name)
+		}
+	}
+
+	sorted := names.ToSlice()
+	slices.Sort(sorted)
+
+	return sorted, nil
+}
+
+func list(args []string) error {
+	if len(args) != 1 {
+		return errors.New("list requires exactly one archive")
+	}
+
+	a, err := archive.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSIZE\tSTORED\tMETHOD\tSHA256")
+
+	var size, stored int64
+	for _, entry := range a.Entries() {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%x\n", entry.Name, entry.Size, entry.StoredSize, entry.Compression, entry.Checksum[:8])
+		size += entry.Size
+		stored += entry.StoredSize
+	}
+
+	fmt.Fprintf(w, "%d files\t%d\t%d\t\t\n", len(a.Entries()), size, stored)
+
+	return w.Flush()
+}
+
+func verify(args []string) error {
+	if len(args) != 1 {
+		return errors.New("verify requires exactly one archive")
+	}
+
+	a, err := archive.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	if err := a.Verify(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d files ok\n", args[0], len(a.Entries()))
+
+	return nil
+}
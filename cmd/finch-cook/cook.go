@@ -0,0 +1,302 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/adm87/finch-core/cmd/internal/importers"
+	"github.com/adm87/finch-core/fsys"
+	"github.com/adm87/finch-core/internal/assets"
+)
+
+// ManifestName is the name of the cook manifest written to the root of the cooked tree.
+const ManifestName = "cook-manifest.json"
+
+// packSourcePrefix prefixes the manifest keys of atlas packs, which have many sources.
+const packSourcePrefix = "pack:"
+
+// ======================================================
+// Cook Manifest
+// ======================================================
+
+// cookOptions are the processor options of a cook. Changing them cooks every source again.
+type cookOptions struct {
+	Palette     int      `json:"palette"`
+	BinaryData  bool     `json:"binary_data"`
+	Pack        []string `json:"pack"`
+	PackSize    int      `json:"pack_size"`
+	PackPadding int      `json:"pack_padding"`
+}
+
+// cookManifest records the outputs of every source of a cook, and the content hashes they were cooked from.
+type cookManifest struct {
+	Options cookOptions            `json:"options"`
+	Sources map[string]*cookSource `json:"sources"`
+}
+
+type cookSource struct {
+	Hash    string       `json:"hash"`
+	Outputs []cookOutput `json:"outputs"`
+}
+
+type cookOutput struct {
+	Name       string   `json:"name"`
+	Hash       string   `json:"hash"`
+	Size       int64    `json:"size"`
+	Processors []string `json:"processors,omitempty"`
+}
+
+type cookReport struct {
+	Sources   int
+	Processed int
+	Skipped   int
+	Removed   int
+	Outputs   []string
+}
+
+// ======================================================
+// Cooker
+// ======================================================
+
+type cooker struct {
+	src      string
+	out      string
+	options  cookOptions
+	force    bool
+	previous *cookManifest
+	manifest *cookManifest
+}
+
+func (c *cooker) cook() (cookReport, error) {
+	report := cookReport{}
+
+	c.previous = &cookManifest{Sources: make(map[string]*cookSource)}
+	if err := fsys.ReadJson(filepath.Join(c.out, ManifestName), c.previous); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return report, err
+	}
+
+	c.manifest = &cookManifest{
+		Options: c.options,
+		Sources: make(map[string]*cookSource),
+	}
+
+	names, packs, err := c.sources()
+	if err != nil {
+		return report, err
+	}
+
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(c.src, filepath.FromSlash(name)))
+		if err != nil {
+			return report, err
+		}
+
+		processed, err := c.cookSource(name, c.hash(name, data), func() ([]cookFile, error) {
+			return c.process(name, data)
+		})
+		if err != nil {
+			return report, err
+		}
+		report.count(processed)
+	}
+
+	for _, dir := range c.options.Pack {
+		frames := packs[dir]
+		if len(frames) == 0 {
+			continue
+		}
+
+		hash := sha256.New()
+		inputs := make(map[string][]byte, len(frames))
+		for _, name := range frames {
+			data, err := os.ReadFile(filepath.Join(c.src, filepath.FromSlash(name)))
+			if err != nil {
+				return report, err
+			}
+			inputs[name] = data
+			hash.Write([]byte(c.hash(name, data)))
+		}
+
+		processed, err := c.cookSource(packSourcePrefix+dir, hex.EncodeToString(hash.Sum(nil)), func() ([]cookFile, error) {
+			return packAtlas(dir, frames, inputs, c.options.PackSize, c.options.PackPadding)
+		})
+		if err != nil {
+			return report, err
+		}
+		report.count(processed)
+	}
+
+	if err := c.checkOutputConflicts(); err != nil {
+		return report, err
+	}
+
+	removed, err := c.removeStaleOutputs()
+	if err != nil {
+		return report, err
+	}
+	report.Removed = removed
+
+	for _, source := range c.manifest.Sources {
+		for _, output := range source.Outputs {
+			report.Outputs = append(report.Outputs, output.Name)
+		}
+	}
+	slices.Sort(report.Outputs)
+
+	if err := os.MkdirAll(c.out, 0o755); err != nil {
+		return report, err
+	}
+
+	return report, fsys.WriteJsonIndent(filepath.Join(c.out, ManifestName), c.manifest)
+}
+
+func (r *cookReport) count(processed bool) {
+	r.Sources++
+	if processed {
+		r.Processed++
+	} else {
+		r.Skipped++
+	}
+}
+
+// sources returns the names of the source files cooked on their own, and the png files of every pack
+// directory, relative to the source directory and sorted.
+func (c *cooker) sources() ([]string, map[string][]string, error) {
+	names := make([]string, 0)
+	packs := make(map[string][]string)
+
+	err := fs.WalkDir(os.DirFS(c.src), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		dir := path.Dir(name)
+		if slices.Contains(c.options.Pack, dir) && assets.AssetFile(name).Type() == importers.PngAssetType {
+			packs[dir] = append(packs[dir], name)
+			return nil
+		}
+
+		names = append(names, name)
+		return nil
+	})
+
+	return names, packs, err
+}
+
+// cookSource reuses the outputs of a source if its hash is unchanged and they still exist, and cooks
+// it otherwise. It reports whether the source was cooked.
+func (c *cooker) cookSource(key, hash string, cook func() ([]cookFile, error)) (bool, error) {
+	if previous, exists := c.previous.Sources[key]; exists && !c.force && previous.Hash == hash && c.outputsExist(previous) {
+		c.manifest.Sources[key] = previous
+		return false, nil
+	}
+
+	files, err := cook()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", key, err)
+	}
+
+	source := &cookSource{Hash: hash}
+	for _, file := range files {
+		target := filepath.Join(c.out, filepath.FromSlash(file.name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(target, file.data, 0o644); err != nil {
+			return false, err
+		}
+
+		sum := sha256.Sum256(file.data)
+		source.Outputs = append(source.Outputs, cookOutput{
+			Name:       file.name,
+			Hash:       hex.EncodeToString(sum[:]),
+			Size:       int64(len(file.data)),
+			Processors: file.processors,
+		})
+	}
+
+	c.manifest.Sources[key] = source
+	return true, nil
+}
+
+func (c *cooker) outputsExist(source *cookSource) bool {
+	for _, output := range source.Outputs {
+		if _, err := os.Stat(filepath.Join(c.out, filepath.FromSlash(output.Name))); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// checkOutputConflicts reports outputs produced by more than one source, such as a pack named like a file.
+func (c *cooker) checkOutputConflicts() error {
+	producers := make(map[string]string)
+	errs := make([]error, 0)
+
+	keys := make([]string, 0, len(c.manifest.Sources))
+	for key := range c.manifest.Sources {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		for _, output := range c.manifest.Sources[key].Outputs {
+			if producer, exists := producers[output.Name]; exists {
+				errs = append(errs, fmt.Errorf("%s is produced by both %s and %s", output.Name, producer, key))
+				continue
+			}
+			producers[output.Name] = key
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// removeStaleOutputs deletes the outputs of the previous cook that the current cook did not produce.
+func (c *cooker) removeStaleOutputs() (int, error) {
+	current := make(map[string]bool)
+	for _, source := range c.manifest.Sources {
+		for _, output := range source.Outputs {
+			current[output.Name] = true
+		}
+	}
+
+	removed := 0
+	for _, source := range c.previous.Sources {
+		for _, output := range source.Outputs {
+			if current[output.Name] {
+				continue
+			}
+			err := os.Remove(filepath.Join(c.out, filepath.FromSlash(output.Name)))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return removed, err
+			}
+			current[output.Name] = true
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+// hash returns the content hash of a source, which covers the cook options so changing them cooks it again.
+func (c *cooker) hash(name string, data []byte) string {
+	options, _ := json.Marshal(c.options)
+
+	hash := sha256.New()
+	hash.Write(options)
+	hash.Write([]byte(name))
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil))
+}
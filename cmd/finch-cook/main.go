@@ -0,0 +1,102 @@
+// Command finch-cook cooks a source asset tree into a tree, or archive, ready to ship.
+//
+// Usage:
+//
+//	finch-cook [-o cooked] [-archive assets.farc] [-root assets] [-palette 256] [-binary-data]
+//	           [-pack sprites/ui,sprites/fx] [-pack-size 2048] [-pack-padding 1] [-force]
+//	           [-no-validate] <dir>
+//
+// Files are copied from dir to the output tree and run through the processors enabled for
+// their asset type:
+//
+//   - png files are reduced to a palette when they have few enough colors.
+//   - yaml data files are rewritten in the finch binary data format, which the data importers
+//     decode exactly as they decode the source document.
+//   - png files of pack directories are packed into a TexturePacker atlas named after the directory.
+//
+// A cook manifest listing every output and its content hash is written to the output tree. Sources
+// whose content and options have not changed since the last cook are not processed again. Every
+// output is then loaded through headless importers of the built-in asset types, which reports files
+// that would fail to load at runtime, such as corrupted images or shaders with syntax errors. The
+// command needs neither cgo nor a display.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "finch-cook:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("finch-cook", flag.ExitOnError)
+	output := flags.String("o", "cooked", "output directory of the cooked tree")
+	archivePath := flags.String("archive", "", "also pack the cooked tree into this archive")
+	root := flags.String("root", "assets", "asset root the cooked tree is mounted at, used to validate outputs")
+	palette := flags.Int("palette", 0, "reduce png files with at most this many colors to a palette, up to 256")
+	binaryData := flags.Bool("binary-data", false, "rewrite yaml data files in the binary data format")
+	pack := flags.String("pack", "", "comma separated directories whose png files are packed into atlases")
+	packSize := flags.Int("pack-size", 2048, "maximum width of packed atlas images")
+	packPadding := flags.Int("pack-padding", 1, "padding between packed atlas frames")
+	force := flags.Bool("force", false, "cook every source, even if it has not changed")
+	noValidate := flags.Bool("no-validate", false, "skip loading the outputs through the asset importers")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("exactly one source directory is required")
+	}
+
+	if *palette < 0 || *palette > 256 {
+		return fmt.Errorf("palette must be between 0 and 256, got %d", *palette)
+	}
+
+	options := cookOptions{
+		Palette:     *palette,
+		BinaryData:  *binaryData,
+		PackSize:    *packSize,
+		PackPadding: *packPadding,
+	}
+	for _, dir := range strings.Split(*pack, ",") {
+		if dir = strings.Trim(strings.TrimSpace(dir), "/"); dir != "" {
+			options.Pack = append(options.Pack, dir)
+		}
+	}
+
+	c := &cooker{
+		src:     flags.Arg(0),
+		out:     *output,
+		options: options,
+		force:   *force,
+	}
+
+	report, err := c.cook()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("cooked %d sources into %s: %d processed, %d up to date, %d removed\n",
+		report.Sources, *output, report.Processed, report.Skipped, report.Removed)
+
+	if !*noValidate {
+		if err := validate(*output, *root, report.Outputs); err != nil {
+			return err
+		}
+		fmt.Printf("validated %d outputs\n", len(report.Outputs))
+	}
+
+	if *archivePath != "" {
+		if err := writeArchive(*output, *archivePath, report.Outputs); err != nil {
+			return err
+		}
+		fmt.Printf("packed %s into %s\n", *output, *archivePath)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"path"
+	"slices"
+
+	"github.com/adm87/finch-core/cmd/internal/importers"
+)
+
+// ======================================================
+// Atlas Packing
+// ======================================================
+
+// packedFrame is a frame of a packed atlas, in the TexturePacker hash format the atlas importer reads.
+type packedFrame struct {
+	Frame            packedRect `json:"frame"`
+	Rotated          bool       `json:"rotated"`
+	Trimmed          bool       `json:"trimmed"`
+	SpriteSourceSize packedRect `json:"spriteSourceSize"`
+	SourceSize       packedSize `json:"sourceSize"`
+}
+
+type packedRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type packedSize struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type packedAtlas struct {
+	Frames map[string]packedFrame `json:"frames"`
+	Meta   struct {
+		Image string     `json:"image"`
+		Size  packedSize `json:"size"`
+		Scale string     `json:"scale"`
+	} `json:"meta"`
+}
+
+// packAtlas packs the png files of a directory into an atlas image and an atlas file named after the
+// directory, next to it. Frames are named after their file, relative to the directory.
+//
+// Frames are placed on shelves, tallest first, in an image no wider than maxWidth.
+func packAtlas(dir string, names []string, inputs map[string][]byte, maxWidth, padding int) ([]cookFile, error) {
+	type frame struct {
+		name string
+		img  image.Image
+		x, y int
+	}
+
+	frames := make([]*frame, 0, len(names))
+	area := 0
+	widest := 0
+	for _, name := range names {
+		img, err := png.Decode(bytes.NewReader(inputs[name]))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		size := img.Bounds().Size()
+		if size.X+padding*2 > maxWidth {
+			return nil, fmt.Errorf("%s: %d pixels wide, larger than the pack size %d", name, size.X, maxWidth)
+		}
+
+		frames = append(frames, &frame{name: name, img: img})
+		area += (size.X + padding) * (size.Y + padding)
+		widest = max(widest, size.X+padding*2)
+	}
+
+	slices.SortStableFunc(frames, func(a, b *frame) int {
+		return cmp.Compare(b.img.Bounds().Dy(), a.img.Bounds().Dy())
+	})
+
+	// Aim for a square image, as wide as the widest frame at least.
+	width := max(widest, 1)
+	for width*width < area && width*2 <= maxWidth {
+		width *= 2
+	}
+	width = min(max(width, widest), maxWidth)
+
+	x, y, shelf := padding, padding, 0
+	for _, f := range frames {
+		size := f.img.Bounds().Size()
+		if x+size.X+padding > width {
+			x, y, shelf = padding, y+shelf+padding, 0
+		}
+		f.x, f.y = x, y
+		x += size.X + padding
+		shelf = max(shelf, size.Y)
+	}
+	height := y + shelf + padding
+
+	sheet := image.NewNRGBA(image.Rect(0, 0, width, height))
+	atlas := packedAtlas{Frames: make(map[string]packedFrame, len(frames))}
+
+	for _, f := range frames {
+		bounds := f.img.Bounds()
+		target := image.Rect(f.x, f.y, f.x+bounds.Dx(), f.y+bounds.Dy())
+		draw.Draw(sheet, target, f.img, bounds.Min, draw.Src)
+
+		name := f.name[len(dir)+1:]
+		atlas.Frames[name] = packedFrame{
+			Frame:            packedRect{X: f.x, Y: f.y, W: bounds.Dx(), H: bounds.Dy()},
+			SpriteSourceSize: packedRect{W: bounds.Dx(), H: bounds.Dy()},
+			SourceSize:       packedSize{W: bounds.Dx(), H: bounds.Dy()},
+		}
+	}
+
+	imageName := dir + "." + importers.PngAssetType
+	atlas.Meta.Image = path.Base(imageName)
+	atlas.Meta.Size = packedSize{W: width, H: height}
+	atlas.Meta.Scale = "1"
+
+	imageData, err := encodePNG(sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	atlasData, err := json.MarshalIndent(atlas, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return []cookFile{
+		{name: imageName, data: imageData, processors: []string{"pack"}},
+		{name: dir + "." + importers.AtlasAssetType, data: atlasData, processors: []string{"pack"}},
+	}, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"github.com/adm87/finch-core/cmd/internal/importers"
+	"github.com/adm87/finch-core/internal/assets"
+	"github.com/adm87/finch-core/internal/bindata"
+)
+
+// cookFile is an output file of a processor, named relative to the cooked tree.
+type cookFile struct {
+	name       string
+	data       []byte
+	processors []string
+}
+
+// process runs a source file through the processors enabled for its asset type.
+// Files without an enabled processor are copied as is.
+func (c *cooker) process(name string, data []byte) ([]cookFile, error) {
+	file := cookFile{name: name, data: data}
+
+	t := assets.AssetFile(name).Type()
+	switch {
+	case t == importers.PngAssetType:
+		if c.options.Palette == 0 {
+			break
+		}
+
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		paletted, ok := reducePalette(toNRGBA(img), c.options.Palette)
+		if !ok {
+			break
+		}
+
+		file.data, err = encodePNG(paletted)
+		if err != nil {
+			return nil, err
+		}
+		file.processors = append(file.processors, "palette")
+	case isYamlData(t):
+		if !c.options.BinaryData {
+			break
+		}
+
+		encoded, err := bindata.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		file.data = encoded
+		file.processors = append(file.processors, "binary")
+	}
+
+	return []cookFile{file}, nil
+}
+
+// ======================================================
+// PNG Processors
+// ======================================================
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok && nrgba.Rect.Min == (image.Point{}) {
+		return nrgba
+	}
+
+	bounds := img.Bounds()
+	nrgba := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(nrgba, nrgba.Rect, img, bounds.Min, draw.Src)
+	return nrgba
+}
+
+// reducePalette returns a paletted copy of an image, if it has no more than the given number of colors.
+// The reduction is lossless, so images with more colors are left as they are.
+func reducePalette(img *image.NRGBA, colors int) (*image.Paletted, bool) {
+	indices := make(map[color.NRGBA]uint8)
+	palette := make(color.Palette, 0, colors)
+
+	for i := 0; i < len(img.Pix); i += 4 {
+		c := color.NRGBA{R: img.Pix[i], G: img.Pix[i+1], B: img.Pix[i+2], A: img.Pix[i+3]}
+		if _, exists := indices[c]; exists {
+			continue
+		}
+		if len(palette) == colors {
+			return nil, false
+		}
+		indices[c] = uint8(len(palette))
+		palette = append(palette, c)
+	}
+
+	paletted := image.NewPaletted(img.Rect, palette)
+	for i, j := 0, 0; i < len(img.Pix); i, j = i+4, j+1 {
+		c := color.NRGBA{R: img.Pix[i], G: img.Pix[i+1], B: img.Pix[i+2], A: img.Pix[i+3]}
+		paletted.Pix[j] = indices[c]
+	}
+
+	return paletted, true
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ======================================================
+// Data Processors
+// ======================================================
+
+// isYamlData reports whether files of an asset type are yaml decoded through the data importers,
+// which read the binary data format as well.
+func isYamlData(t assets.AssetType) bool {
+	format, err := assets.AssetDataFormat(t)
+	return err == nil && format == assets.YamlAssetType
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/adm87/finch-core/archive"
+	"github.com/adm87/finch-core/cmd/internal/importers"
+	"github.com/adm87/finch-core/fsys"
+	"github.com/adm87/finch-core/internal/assets"
+)
+
+// validate loads every output of a cook through the headless importers of the built-in asset types,
+// with the cooked tree mounted at the asset root, so files that would fail to load at runtime are
+// reported at cook time.
+//
+// Outputs of types without an importer are reported as warnings, since games may register their own.
+func validate(out, root string, outputs []string) error {
+	if err := importers.Register(); err != nil {
+		return err
+	}
+
+	if err := assets.RegisterAssetFilesystem(assets.AssetRoot(root), os.DirFS(out)); err != nil {
+		return err
+	}
+
+	errs := make([]error, 0)
+	for _, name := range outputs {
+		file := assets.AssetFile(root + "/" + name)
+
+		if !assets.HasAssetTypeSupport(file.Type()) {
+			fmt.Fprintf(os.Stderr, "finch-cook: warning: %s: no importer for type %q\n", name, file.Type())
+			continue
+		}
+
+		if assets.IsAssetLoaded(file) {
+			continue
+		}
+
+		if err := assets.LoadAssets(file); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// writeArchive packs the outputs of a cook into a finch archive, without the cook manifest.
+func writeArchive(out, archivePath string, outputs []string) error {
+	src := os.DirFS(out)
+	return fsys.WriteAtomic(archivePath, 0o644, func(f io.Writer) error {
+		w := archive.NewWriter(f, archive.Deflate)
+		for _, name := range outputs {
+			if err := w.AddFile(src, name, name); err != nil {
+				return err
+			}
+		}
+		return w.Close()
+	})
+}
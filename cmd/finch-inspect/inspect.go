@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adm87/finch-core/cmd/internal/importers"
+	"github.com/adm87/finch-core/hashset"
+	"github.com/adm87/finch-core/internal/assets"
+)
+
+type inspector struct {
+	root     assets.AssetRoot
+	src      fs.FS
+	recorder *recordingFS
+	closer   io.Closer
+	manifest *assets.AssetManifest
+}
+
+// mount opens a source and registers it as the filesystem of the asset root, through a recording
+// filesystem.
+func (i *inspector) mount(name string) error {
+	src, closer, err := openSource(name)
+	if err != nil {
+		return err
+	}
+	i.closer = closer
+
+	if i.src, err = rootSource(src, i.root); err != nil {
+		return err
+	}
+
+	i.recorder = newRecordingFS(i.src)
+
+	return assets.RegisterAssetFilesystemMapped(i.root, i.recorder, assets.AssetPathRelative)
+}
+
+func (i *inspector) close() {
+	if i.closer != nil {
+		i.closer.Close()
+	}
+}
+
+func (i *inspector) readManifest(name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := assets.ParseAssetManifest(assets.NewAssetType(filepath.Ext(name)), data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	i.manifest = manifest
+	return nil
+}
+
+// inspect loads every file of the source and builds the report.
+func (i *inspector) inspect() (*report, error) {
+	if err := importers.Register(); err != nil {
+		return nil, err
+	}
+
+	r := &report{Root: i.root.String(), Manifest: i.manifest != nil}
+
+	names, err := i.files()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[assets.AssetFile]*fileReport, len(names))
+	hashes := make(map[assets.AssetHash][]assets.AssetFile)
+	edges := make(map[assets.AssetFile][]assets.AssetFile)
+
+	for _, name := range names {
+		file := assets.AssetFile(i.root.String() + "/" + name)
+
+		data, err := fs.ReadFile(i.src, name)
+		if err != nil {
+			return nil, err
+		}
+
+		stamp := assets.NewAssetStamp(data)
+		hashes[stamp.Hash] = append(hashes[stamp.Hash], file)
+
+		fr := &fileReport{
+			File: file,
+			Root: file.Root().String(),
+			Type: file.Type().String(),
+			Size: stamp.Size,
+			Hash: stamp.Hash,
+		}
+		files[file] = fr
+		r.Files = append(r.Files, fr)
+
+		edges[file] = append(edges[file], i.dataReferences(file, data)...)
+	}
+
+	i.recorder.take()
+	for _, fr := range r.Files {
+		i.load(fr)
+		for _, read := range i.recorder.take() {
+			read := assets.AssetFile(i.root.String() + "/" + read)
+			if _, exists := files[read]; exists && read != fr.File {
+				edges[fr.File] = append(edges[fr.File], read)
+			}
+		}
+		edges[fr.File] = append(edges[fr.File], assets.AssetDependencies(fr.File)...)
+	}
+
+	for file, refs := range edges {
+		missing := hashset.New[assets.AssetFile]()
+		for _, ref := range refs {
+			if _, exists := files[ref.Base()]; !exists && !missing.Contains(ref.Base()) {
+				missing.Add(ref.Base())
+				r.Missing = append(r.Missing, reference{From: file, File: ref.Base()})
+			}
+		}
+	}
+
+	if i.manifest != nil {
+		r.Missing = append(r.Missing, i.manifestMissing()...)
+		r.Unused = i.unused(files, edges)
+	}
+
+	for _, group := range hashes {
+		if len(group) > 1 {
+			slices.Sort(group)
+			r.Duplicates = append(r.Duplicates, group)
+		}
+	}
+
+	r.sort()
+
+	return r, nil
+}
+
+// files returns the names of the files of the source, relative to the root and sorted.
+func (i *inspector) files() ([]string, error) {
+	names := make([]string, 0)
+	err := fs.WalkDir(i.src, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names, err
+}
+
+// load loads a file through its importer and records the outcome.
+func (i *inspector) load(fr *fileReport) {
+	if !assets.HasAssetTypeSupport(assets.AssetType(fr.Type)) {
+		fr.Status = statusNoImporter
+		return
+	}
+	fr.Importer = importers.Name(assets.AssetType(fr.Type))
+
+	if !assets.IsAssetLoaded(fr.File) {
+		if err := assets.LoadAssets(fr.File); err != nil {
+			fr.Status = statusFailed
+			fr.Error = err.Error()
+			return
+		}
+	}
+
+	fr.Status = statusOK
+}
+
+// dataReferences returns the asset files named by the string values of a json or yaml file, including
+// multi-part types such as enemy.yaml and cooked binary data. Files of other types, or that do not
+// decode, have none.
+func (i *inspector) dataReferences(file assets.AssetFile, data []byte) []assets.AssetFile {
+	if _, err := assets.AssetDataFormat(file.Type()); err != nil {
+		return nil
+	}
+
+	var value any
+	if err := assets.DecodeAssetData(file.Type(), data, &value); err != nil {
+		return nil
+	}
+
+	refs := hashset.New[assets.AssetFile]()
+	prefix := i.root.String() + "/"
+
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			if strings.HasPrefix(v, prefix) && path.Ext(v) != "" && !strings.ContainsAny(v, " *?[") {
+				if ref, err := assets.NewAssetFile(v); err == nil && ref != file {
+					refs.Add(ref)
+				}
+			}
+		case map[string]any:
+			for _, child := range v {
+				walk(child)
+			}
+		case map[any]any:
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(value)
+
+	sorted := refs.ToSlice()
+	slices.Sort(sorted)
+	return sorted
+}
+
+// manifestMissing returns the manifest paths that do not exist and the patterns that match no file.
+func (i *inspector) manifestMissing() []reference {
+	missing := make([]reference, 0)
+	prefix := i.root.String() + "/"
+
+	for group, patterns := range i.manifest.Groups {
+		for _, pattern := range patterns {
+			name, inRoot := strings.CutPrefix(pattern, prefix)
+			if !inRoot {
+				missing = append(missing, reference{Group: group, File: assets.AssetFile(pattern), Error: "outside of root " + i.root.String()})
+				continue
+			}
+
+			if !strings.ContainsAny(name, "*?[") {
+				if _, err := fs.Stat(i.src, name); err != nil {
+					missing = append(missing, reference{Group: group, File: assets.AssetFile(pattern)})
+				}
+				continue
+			}
+
+			matches, err := fs.Glob(i.src, name)
+			if err != nil {
+				missing = append(missing, reference{Group: group, File: assets.AssetFile(pattern), Error: err.Error()})
+			} else if len(matches) == 0 {
+				missing = append(missing, reference{Group: group, File: assets.AssetFile(pattern), Error: assets.ErrAssetManifestNoMatches.Error()})
+			}
+		}
+	}
+
+	return missing
+}
+
+// unused returns the files that cannot be reached from the manifest groups.
+func (i *inspector) unused(files map[assets.AssetFile]*fileReport, edges map[assets.AssetFile][]assets.AssetFile) []assets.AssetFile {
+	used := hashset.New[assets.AssetFile]()
+	queue := make([]assets.AssetFile, 0)
+
+	for group := range i.manifest.Groups {
+		resolved, _ := i.manifest.Resolve(group)
+		queue = append(queue, resolved...)
+	}
+
+	for len(queue) > 0 {
+		file := queue[0].Base()
+		queue = queue[1:]
+
+		if used.Contains(file) {
+			continue
+		}
+		used.Add(file)
+		queue = append(queue, edges[file]...)
+	}
+
+	unused := make([]assets.AssetFile, 0)
+	for file := range files {
+		if !used.Contains(file) {
+			unused = append(unused, file)
+		}
+	}
+	slices.Sort(unused)
+
+	return unused
+}
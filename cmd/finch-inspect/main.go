@@ -0,0 +1,82 @@
+// Command finch-inspect reports on the asset files of a directory or archive, headless, for use in CI.
+//
+// Usage:
+//
+//	finch-inspect [-root assets] [-manifest manifest.yaml] [-json] [-strict] <dir|archive>
+//
+// Every file is listed with its asset file, root, type, the name of its importer, its size
+// and whether it loads. Archives may be zip, tar, tar.gz, tgz or finch archives.
+//
+// The command also reports:
+//
+//   - missing references: manifest paths and patterns that match no file, and asset paths named in json
+//     and yaml data files that do not exist.
+//   - unused files: files not reachable from the manifest through the files read while loading them,
+//     their dependencies and the references of data files. Only reported when a manifest is given.
+//   - duplicates: files with identical contents.
+//
+// It exits with status 1 if a file fails to load or a reference is missing, and with -strict also if a
+// file is unused or duplicated.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/adm87/finch-core/internal/assets"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "finch-inspect:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("finch-inspect", flag.ExitOnError)
+	root := flags.String("root", "assets", "asset root the source is mounted at")
+	manifestPath := flags.String("manifest", "", "asset manifest whose groups reference the files in use")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	strict := flags.Bool("strict", false, "also fail on unused and duplicate files")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("exactly one source directory or archive is required")
+	}
+
+	i := &inspector{root: assets.AssetRoot(*root)}
+
+	if err := i.mount(flags.Arg(0)); err != nil {
+		return err
+	}
+	defer i.close()
+
+	if *manifestPath != "" {
+		if err := i.readManifest(*manifestPath); err != nil {
+			return err
+		}
+	}
+
+	report, err := i.inspect()
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		err = report.writeJSON(os.Stdout)
+	} else {
+		err = report.writeText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if report.failed(*strict) {
+		return errors.New("inspection failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/adm87/finch-core/internal/assets"
+)
+
+const (
+	statusOK         = "ok"
+	statusFailed     = "failed"
+	statusNoImporter = "no importer"
+)
+
+type report struct {
+	Root       string               `json:"root"`
+	Manifest   bool                 `json:"manifest"`
+	Files      []*fileReport        `json:"files"`
+	Missing    []reference          `json:"missing"`
+	Unused     []assets.AssetFile   `json:"unused"`
+	Duplicates [][]assets.AssetFile `json:"duplicates"`
+}
+
+type fileReport struct {
+	File     assets.AssetFile `json:"file"`
+	Root     string           `json:"root"`
+	Type     string           `json:"type"`
+	Importer string           `json:"importer,omitempty"`
+	Size     int64            `json:"size"`
+	Hash     assets.AssetHash `json:"hash"`
+	Status   string           `json:"status"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// reference is a missing file, named by a data file or by a manifest group.
+type reference struct {
+	From  assets.AssetFile `json:"from,omitempty"`
+	Group string           `json:"group,omitempty"`
+	File  assets.AssetFile `json:"file"`
+	Error string           `json:"error,omitempty"`
+}
+
+func (r reference) String() string {
+	var b strings.Builder
+	b.WriteString(r.File.Path())
+	if r.From != "" {
+		fmt.Fprintf(&b, " (referenced by %s)", r.From)
+	}
+	if r.Group != "" {
+		fmt.Fprintf(&b, " (manifest group %s)", r.Group)
+	}
+	if r.Error != "" {
+		fmt.Fprintf(&b, ": %s", r.Error)
+	}
+	return b.String()
+}
+
+// failed reports whether the inspection found errors, counting unused and duplicate files as errors if strict.
+func (r *report) failed(strict bool) bool {
+	if len(r.Missing) > 0 {
+		return true
+	}
+	if slices.ContainsFunc(r.Files, func(f *fileReport) bool { return f.Status == statusFailed }) {
+		return true
+	}
+	return strict && (len(r.Unused) > 0 || len(r.Duplicates) > 0)
+}
+
+func (r *report) sort() {
+	slices.SortFunc(r.Files, func(a, b *fileReport) int {
+		return cmp.Compare(a.File, b.File)
+	})
+	slices.SortFunc(r.Missing, func(a, b reference) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.From, b.From), cmp.Compare(a.Group, b.Group))
+	})
+	slices.SortFunc(r.Duplicates, func(a, b []assets.AssetFile) int {
+		return cmp.Compare(a[0], b[0])
+	})
+}
+
+func (r *report) writeJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+func (r *report) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tROOT\tTYPE\tIMPORTER\tSIZE\tSTATUS")
+
+	var size int64
+	failed := 0
+	for _, f := range r.Files {
+		importer := cmp.Or(f.Importer, "-")
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n", f.File, f.Root, cmp.Or(f.Type, "-"), importer, f.Size, f.Status)
+		size += f.Size
+		if f.Status == statusFailed {
+			failed++
+		}
+	}
+	fmt.Fprintf(tw, "%d files\t\t\t\t%d\t%d failed\n", len(r.Files), size, failed)
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		fmt.Fprintln(w, "\nload errors:")
+		for _, f := range r.Files {
+			if f.Status == statusFailed {
+				fmt.Fprintf(w, "  %s\n", f.Error)
+			}
+		}
+	}
+
+	if len(r.Missing) > 0 {
+		fmt.Fprintln(w, "\nmissing references:")
+		for _, ref := range r.Missing {
+			fmt.Fprintf(w, "  %s\n", ref)
+		}
+	}
+
+	if len(r.Unused) > 0 {
+		fmt.Fprintln(w, "\nunused files:")
+		for _, file := range r.Unused {
+			fmt.Fprintf(w, "  %s\n", file)
+		}
+	} else if !r.Manifest {
+		fmt.Fprintln(w, "\nunused files are not reported without a manifest")
+	}
+
+	if len(r.Duplicates) > 0 {
+		fmt.Fprintln(w, "\nduplicates:")
+		for _, group := range r.Duplicates {
+			names := make([]string, len(group))
+			for i, file := range group {
+				names[i] = file.Path()
+			}
+			fmt.Fprintf(w, "  %s\n", strings.Join(names, ", "))
+		}
+	}
+
+	return nil
+}
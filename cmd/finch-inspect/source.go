@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/adm87/finch-core/archive"
+	"github.com/adm87/finch-core/fsys"
+	"github.com/adm87/finch-core/hashset"
+	"github.com/adm87/finch-core/internal/assets"
+)
+
+// openSource opens a directory, or a zip, tar or finch archive, as a filesystem.
+func openSource(name string) (fs.FS, io.Closer, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		return os.DirFS(name), nil, nil
+	}
+
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		r, err := fsys.OpenZip(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		return r, r, nil
+	case strings.HasSuffix(lower, ".tar"), strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		t, err := fsys.OpenTar(name)
+		return t, nil, err
+	case strings.HasSuffix(lower, archive.Extension):
+		a, err := archive.Open(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		return a, a, nil
+	default:
+		return nil, nil, fmt.Errorf("%s: unsupported archive %q", name, filepath.Ext(name))
+	}
+}
+
+// rootSource returns the part of a source that holds the files of an asset root: the directory named
+// after the root if the source contains one, as archives of the root directory do, or the whole source.
+func rootSource(src fs.FS, root assets.AssetRoot) (fs.FS, error) {
+	if info, err := fs.Stat(src, root.String()); err == nil && info.IsDir() {
+		return fs.Sub(src, root.String())
+	}
+	return src, nil
+}
+
+// ======================================================
+// Recording Filesystem
+// ======================================================
+
+// recordingFS records the files read through it, so the files an asset reads while it loads are known
+// without knowing how its importer finds them.
+type recordingFS struct {
+	fs fs.FS
+
+	mu    sync.Mutex
+	reads hashset.Set[string]
+}
+
+func newRecordingFS(src fs.FS) *recordingFS {
+	return &recordingFS{fs: src, reads: hashset.New[string]()}
+}
+
+// take returns the files read since the last call, and forgets them.
+func (r *recordingFS) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reads := r.reads.ToSlice()
+	r.reads = hashset.New[string]()
+	return reads
+}
+
+func (r *recordingFS) record(name string) {
+	r.mu.Lock()
+	r.reads.Add(name)
+	r.mu.Unlock()
+}
+
+func (r *recordingFS) Open(name string) (fs.File, error) {
+	f, err := r.fs.Open(name)
+	if err == nil {
+		r.record(name)
+	}
+	return f, err
+}
+
+func (r *recordingFS) ReadFile(name string) ([]byte, error) {
+	data, err := fs.ReadFile(r.fs, name)
+	if err == nil {
+		r.record(name)
+	}
+	return data, err
+}
+
+func (r *recordingFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(r.fs, name)
+}
+
+func (r *recordingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(r.fs, name)
+}
@@ -0,0 +1,156 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/adm87/finch-core/internal/assets"
+	"github.com/hajimehoshi/go-mp3"
+	"github.com/jfreymuth/oggvorbis"
+)
+
+var (
+	ErrAudioInvalid = errors.New("audio file is invalid")
+)
+
+// ======================================================
+// Audio
+// ======================================================
+
+// registerAudio registers the importers of audio clips and streams, which decode every sample of
+// a file and produce its duration.
+func registerAudio() error {
+	return errors.Join(
+		register("audio", &assets.AssetImporter{
+			AssetTypes: []assets.AssetType{
+				WavAssetType,
+				OggAssetType,
+				Mp3AssetType,
+			},
+			ProcessAssetFile: func(file assets.AssetFile, data []byte) (any, error) {
+				return decodeAudio(file.Type(), data)
+			},
+			OutputType: reflect.TypeFor[time.Duration](),
+		}),
+		register("audio stream", &assets.AssetImporter{
+			AssetTypes: []assets.AssetType{
+				WavStreamAssetType,
+				OggStreamAssetType,
+				Mp3StreamAssetType,
+			},
+			ProcessAssetFile: func(file assets.AssetFile, data []byte) (any, error) {
+				return decodeAudio(assets.AssetType(strings.TrimPrefix(file.Type().String(), "stream.")), data)
+			},
+			OutputType: reflect.TypeFor[time.Duration](),
+		}),
+	)
+}
+
+func decodeAudio(t assets.AssetType, data []byte) (time.Duration, error) {
+	switch t {
+	case WavAssetType:
+		return decodeWav(data)
+	case OggAssetType:
+		return decodeOgg(data)
+	case Mp3AssetType:
+		return decodeMp3(data)
+	default:
+		return 0, fmt.Errorf("%w: %s", assets.ErrAssetInvalidType, t)
+	}
+}
+
+// decodeWav checks the header of a wav file, which must hold 8 or 16 bit linear PCM in one or two
+// channels, as the finch wav decoder requires.
+func decodeWav(data []byte) (time.Duration, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return 0, fmt.Errorf("%w: wav: RIFF WAVE header not found", ErrAudioInvalid)
+	}
+
+	rate, blockAlign := 0, 0
+	for chunks := data[12:]; len(chunks) >= 8; {
+		id, size := string(chunks[:4]), uint64(binary.LittleEndian.Uint32(chunks[4:8]))
+		chunks = chunks[8:]
+
+		switch id {
+		case "fmt ":
+			if size < 16 || size > uint64(len(chunks)) {
+				return 0, fmt.Errorf("%w: wav: fmt chunk of %d bytes", ErrAudioInvalid, size)
+			}
+
+			format := binary.LittleEndian.Uint16(chunks[0:])
+			channels := int(binary.LittleEndian.Uint16(chunks[2:]))
+			rate = int(binary.LittleEndian.Uint32(chunks[4:]))
+			bits := int(binary.LittleEndian.Uint16(chunks[14:]))
+
+			switch {
+			case format != 1:
+				return 0, fmt.Errorf("%w: wav: format must be linear PCM", ErrAudioInvalid)
+			case channels != 1 && channels != 2:
+				return 0, fmt.Errorf("%w: wav: number of channels must be 1 or 2 but was %d", ErrAudioInvalid, channels)
+			case bits != 8 && bits != 16:
+				return 0, fmt.Errorf("%w: wav: bits per sample must be 8 or 16 but was %d", ErrAudioInvalid, bits)
+			case rate <= 0:
+				return 0, fmt.Errorf("%w: wav: sample rate %d", ErrAudioInvalid, rate)
+			}
+			blockAlign = channels * bits / 8
+		case "data":
+			if blockAlign == 0 {
+				return 0, fmt.Errorf("%w: wav: data chunk before fmt chunk", ErrAudioInvalid)
+			}
+			samples := int(min(size, uint64(len(chunks)))) / blockAlign
+			return time.Duration(samples) * time.Second / time.Duration(rate), nil
+		}
+
+		// Chunks are padded to an even size.
+		chunks = chunks[min(size+size%2, uint64(len(chunks))):]
+	}
+
+	return 0, fmt.Errorf("%w: wav: data chunk not found", ErrAudioInvalid)
+}
+
+func decodeOgg(data []byte) (time.Duration, error) {
+	r, err := oggvorbis.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+
+	if r.SampleRate() <= 0 || r.Channels() <= 0 {
+		return 0, fmt.Errorf("%w: ogg: %d channels at %d Hz", ErrAudioInvalid, r.Channels(), r.SampleRate())
+	}
+
+	samples := 0
+	buf := make([]float32, 8192)
+	for {
+		n, err := r.Read(buf)
+		samples += n
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return time.Duration(samples/r.Channels()) * time.Second / time.Duration(r.SampleRate()), nil
+}
+
+func decodeMp3(data []byte) (time.Duration, error) {
+	d, err := mp3.NewDecoder(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+
+	// Decoded samples are always 16 bit stereo.
+	n, err := io.Copy(io.Discard, d)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(n/4) * time.Second / time.Duration(d.SampleRate()), nil
+}
@@ -0,0 +1,30 @@
+package importers
+
+import (
+	"errors"
+
+	"github.com/adm87/finch-core/internal/assets"
+)
+
+// ======================================================
+// Data
+// ======================================================
+
+// registerData registers the importers of json and yaml data files and of string tables, which
+// decode them without a schema.
+func registerData() error {
+	dataTypes := []assets.AssetType{assets.JsonAssetType, assets.YamlAssetType, assets.YmlAssetType}
+	tableTypes := []assets.AssetType{StringsJsonAssetType, StringsYamlAssetType, StringsYmlAssetType}
+
+	if err := errors.Join(
+		assets.RegisterDataImporter[any](dataTypes...),
+		assets.RegisterDataImporter[map[string]any](tableTypes...),
+	); err != nil {
+		return err
+	}
+
+	setName("data", dataTypes...)
+	setName("string table", tableTypes...)
+
+	return nil
+}
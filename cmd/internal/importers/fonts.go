@@ -0,0 +1,234 @@
+package importers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/adm87/finch-core/internal/assets"
+	"github.com/go-text/typesetting/font"
+	"github.com/go-text/typesetting/font/opentype"
+)
+
+// fontSidecarExtension is appended to the path of a font file to locate its sidecar file.
+const fontSidecarExtension = ".yaml"
+
+var (
+	ErrFontSizeInvalid   = errors.New("font size is invalid")
+	ErrBitmapFontInvalid = errors.New("bitmap font is invalid")
+)
+
+// ======================================================
+// Fonts
+// ======================================================
+
+// fontSidecar is the contents of a font's sidecar file.
+type fontSidecar struct {
+	Sizes []float64 `json:"sizes" yaml:"sizes"`
+}
+
+// registerFonts registers the importers of fonts and of their sidecar files. A font loads its
+// sidecar file as a dependency and produces its declared sizes.
+func registerFonts() error {
+	return errors.Join(
+		register("font sidecar", &assets.AssetImporter{
+			AssetTypes: []assets.AssetType{
+				TtfSidecarAssetType,
+				OtfSidecarAssetType,
+			},
+			ProcessAssetFile: func(file assets.AssetFile, data []byte) (any, error) {
+				sidecar := &fontSidecar{}
+				if err := assets.DecodeAssetData(file.Type(), data, sidecar); err != nil {
+					return nil, err
+				}
+				for _, size := range sidecar.Sizes {
+					if size <= 0 {
+						return nil, fmt.Errorf("%w: %g", ErrFontSizeInvalid, size)
+					}
+				}
+				return sidecar, nil
+			},
+			OutputType: reflect.TypeFor[*fontSidecar](),
+		}),
+		register("font", &assets.AssetImporter{
+			AssetTypes: []assets.AssetType{
+				TtfAssetType,
+				OtfAssetType,
+			},
+			ProcessAssetFile: func(file assets.AssetFile, data []byte) (any, error) {
+				loader, err := opentype.NewLoader(bytes.NewReader(data))
+				if err != nil {
+					return nil, err
+				}
+				if _, err := font.NewFont(loader); err != nil {
+					return nil, err
+				}
+				return fontSizes(file)
+			},
+			OutputType: reflect.TypeFor[[]float64](),
+		}),
+	)
+}
+
+// fontSizes returns the size variants of a font file, read from its path and its sidecar file.
+func fontSizes(file assets.AssetFile) ([]float64, error) {
+	sizes, err := pathFontSizes(file)
+	if err != nil {
+		return nil, err
+	}
+
+	sidecarFile := assets.AssetFile(file.Path() + fontSidecarExtension)
+	exists, err := assets.AssetFileExists(sidecarFile)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if err := assets.LoadAssetDependencies(file, sidecarFile); err != nil {
+			return nil, err
+		}
+		sidecar, err := assets.GetAsset[*fontSidecar](sidecarFile)
+		if err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, sidecar.Sizes...)
+	}
+
+	slices.Sort(sizes)
+
+	return slices.Compact(sizes), nil
+}
+
+// pathFontSizes returns the sizes listed after the last @ of a font's name, as in title@32,48.ttf. A
+// suffix that is not a list of numbers, as in ui@2x.ttf, is part of the name and lists no sizes.
+func pathFontSizes(file assets.AssetFile) ([]float64, error) {
+	name := strings.TrimSuffix(path.Base(file.Path()), path.Ext(file.Path()))
+	i := strings.LastIndex(name, "@")
+	if i < 0 {
+		return make([]float64, 0), nil
+	}
+
+	fields := strings.Split(name[i+1:], ",")
+	sizes := make([]float64, 0, len(fields))
+	for _, field := range fields {
+		size, err := strconv.ParseFloat(field, 64)
+		if err != nil || math.IsNaN(size) || math.IsInf(size, 0) {
+			return make([]float64, 0), nil
+		}
+		if size <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrFontSizeInvalid, field)
+		}
+		sizes = append(sizes, size)
+	}
+
+	return sizes, nil
+}
+
+// ======================================================
+// Bitmap Fonts
+// ======================================================
+
+// registerBitmapFonts registers the importer of BMFont text descriptors, which loads their page
+// images as dependencies and produces the number of glyphs.
+func registerBitmapFonts() error {
+	return register("bitmap font", &assets.AssetImporter{
+		AssetTypes: []assets.AssetType{
+			FntAssetType,
+		},
+		ProcessAssetFile: func(file assets.AssetFile, data []byte) (any, error) {
+			return parseBitmapFont(file, data)
+		},
+		OutputType: reflect.TypeFor[int](),
+	})
+}
+
+func parseBitmapFont(file assets.AssetFile, data []byte) (int, error) {
+	pages := make(map[int]string)
+	chars := make([]map[string]string, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		tag, attrs, err := parseBitmapFontLine(scanner.Text())
+		if err != nil {
+			return 0, fmt.Errorf("%w: line %d: %w", ErrBitmapFontInvalid, line, err)
+		}
+
+		switch tag {
+		case "page":
+			id, err := strconv.Atoi(attrs["id"])
+			if err != nil {
+				return 0, fmt.Errorf("%w: line %d: page id: %w", ErrBitmapFontInvalid, line, err)
+			}
+			pages[id] = attrs["file"]
+		case "char":
+			chars = append(chars, attrs)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	pageFiles := make([]assets.AssetFile, len(pages))
+	for id, name := range pages {
+		if id < 0 || id >= len(pages) || name == "" {
+			return 0, fmt.Errorf("%w: page %d", ErrBitmapFontInvalid, id)
+		}
+		pageFiles[id] = assets.AssetFile(path.Join(path.Dir(file.Path()), name))
+	}
+
+	if err := assets.LoadAssetDependencies(file, pageFiles...); err != nil {
+		return 0, err
+	}
+
+	for _, attrs := range chars {
+		values := make(map[string]int, len(attrs))
+		for _, key := range []string{"id", "x", "y", "width", "height", "xoffset", "yoffset", "xadvance", "page"} {
+			value, err := strconv.Atoi(attrs[key])
+			if err != nil && key != "page" {
+				return 0, fmt.Errorf("%w: char %s: %s: %w", ErrBitmapFontInvalid, attrs["id"], key, err)
+			}
+			values[key] = value
+		}
+
+		if page := values["page"]; page < 0 || page >= len(pages) {
+			return 0, fmt.Errorf("%w: char %d: page %d", ErrBitmapFontInvalid, values["id"], page)
+		}
+	}
+
+	return len(chars), nil
+}
+
+// parseBitmapFontLine splits a BMFont descriptor line into its tag and key=value attributes.
+func parseBitmapFontLine(line string) (string, map[string]string, error) {
+	line = strings.TrimSpace(line)
+	tag, rest, _ := strings.Cut(line, " ")
+	attrs := make(map[string]string)
+
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		key, value, found := strings.Cut(rest, "=")
+		if !found {
+			return "", nil, fmt.Errorf("attribute without value: %s", rest)
+		}
+
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				return "", nil, fmt.Errorf("unterminated value: %s", key)
+			}
+			attrs[key] = value[1 : end+1]
+			rest = value[end+2:]
+			continue
+		}
+
+		value, rest, _ = strings.Cut(value, " ")
+		attrs[key] = value
+	}
+
+	return tag, attrs, nil
+}
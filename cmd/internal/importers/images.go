@@ -0,0 +1,171 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"path"
+	"reflect"
+
+	"github.com/adm87/finch-core/aseprite"
+	"github.com/adm87/finch-core/internal/assets"
+)
+
+var (
+	ErrAtlasInvalid = errors.New("atlas is invalid")
+)
+
+// ======================================================
+// Images
+// ======================================================
+
+// registerImages registers the importer of images, which decodes them in full and produces their bounds.
+func registerImages() error {
+	return register("image", &assets.AssetImporter{
+		AssetTypes: []assets.AssetType{
+			PngAssetType,
+			JpgAssetType,
+			JpegAssetType,
+			BmpAssetType,
+		},
+		ProcessAssetFile: func(file assets.AssetFile, data []byte) (any, error) {
+			img, _, err := image.Decode(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			return img.Bounds(), nil
+		},
+		OutputType: reflect.TypeFor[image.Rectangle](),
+	})
+}
+
+// ======================================================
+// Atlases
+// ======================================================
+
+// atlasFile is the TexturePacker JSON format, reduced to the fields the importer checks.
+type atlasFile struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image string `json:"image"`
+	} `json:"meta"`
+}
+
+type atlasFileFrame struct {
+	Filename string `json:"filename"`
+	Frame    struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"frame"`
+	Rotated bool `json:"rotated"`
+}
+
+// registerAtlases registers the importer of TexturePacker atlases, which loads the atlas image as a
+// dependency and checks that every frame lies within it. It produces the names of the frames.
+func registerAtlases() error {
+	return register("atlas", &assets.AssetImporter{
+		AssetTypes: []assets.AssetType{
+			AtlasAssetType,
+			AtlasJsonAssetType,
+		},
+		ProcessAssetFile: func(file assets.AssetFile, data []byte) (any, error) {
+			return parseAtlas(file, data)
+		},
+		OutputType: reflect.TypeFor[[]string](),
+	})
+}
+
+func parseAtlas(file assets.AssetFile, data []byte) ([]string, error) {
+	contents := atlasFile{}
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return nil, err
+	}
+
+	if contents.Meta.Image == "" {
+		return nil, fmt.Errorf("%w: meta.image is empty", ErrAtlasInvalid)
+	}
+
+	frames, err := parseAtlasFrames(contents.Frames)
+	if err != nil {
+		return nil, err
+	}
+
+	imageFile := assets.AssetFile(path.Join(path.Dir(file.Path()), contents.Meta.Image))
+	if err := assets.LoadAssetDependencies(file, imageFile); err != nil {
+		return nil, err
+	}
+
+	bounds, err := assets.GetAsset[image.Rectangle](imageFile)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		w, h := frame.Frame.W, frame.Frame.H
+		if frame.Rotated {
+			w, h = h, w
+		}
+
+		if !image.Rect(frame.Frame.X, frame.Frame.Y, frame.Frame.X+w, frame.Frame.Y+h).In(bounds) {
+			return nil, fmt.Errorf("%w: frame %s is outside of the image", ErrAtlasInvalid, frame.Filename)
+		}
+		names = append(names, frame.Filename)
+	}
+
+	return names, nil
+}
+
+// parseAtlasFrames decodes the frames of a TexturePacker atlas, which are either a map keyed by
+// frame name or an array of frames with a filename.
+func parseAtlasFrames(data json.RawMessage) ([]atlasFileFrame, error) {
+	frames := make([]atlasFileFrame, 0)
+	if err := json.Unmarshal(data, &frames); err == nil {
+		return frames, nil
+	}
+
+	byName := make(map[string]atlasFileFrame)
+	if err := json.Unmarshal(data, &byName); err != nil {
+		return nil, fmt.Errorf("%w: frames: %w", ErrAtlasInvalid, err)
+	}
+
+	for name, frame := range byName {
+		frame.Filename = name
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+// ======================================================
+// Aseprite
+// ======================================================
+
+// registerAseprite registers the importer of Aseprite files, which composites every frame.
+func registerAseprite() error {
+	return register("aseprite", &assets.AssetImporter{
+		AssetTypes: []assets.AssetType{
+			AsepriteAssetType,
+			AseAssetType,
+		},
+		ProcessAssetFile: func(file assets.AssetFile, data []byte) (any, error) {
+			decoded, err := aseprite.Decode(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			for i := range decoded.Frames {
+				if _, err := decoded.Composite(i); err != nil {
+					return nil, err
+				}
+			}
+			return decoded, nil
+		},
+		OutputType: reflect.TypeFor[*aseprite.File](),
+	})
+}
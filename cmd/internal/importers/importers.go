@@ -0,0 +1,106 @@
+// Package importers registers headless importers of the built-in asset types for the offline tools.
+//
+// Each importer decodes and checks files the way the finch importer of its type does, including the
+// dependencies it loads, but produces plain values instead of images, audio players, font faces or
+// compiled shaders. The tools so validate assets without cgo, a graphics device or an audio device.
+//
+// Kage shaders are only checked for syntax, since compiling them needs a graphics device.
+package importers
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/adm87/finch-core/internal/assets"
+)
+
+// Asset types of the built-in importers, matching the types registered by the finch package.
+const (
+	PngAssetType  = "png"
+	JpgAssetType  = "jpg"
+	JpegAssetType = "jpeg"
+	BmpAssetType  = "bmp"
+
+	WavAssetType       = "wav"
+	OggAssetType       = "ogg"
+	Mp3AssetType       = "mp3"
+	WavStreamAssetType = "stream.wav"
+	OggStreamAssetType = "stream.ogg"
+	Mp3StreamAssetType = "stream.mp3"
+
+	TtfAssetType        = "ttf"
+	OtfAssetType        = "otf"
+	TtfSidecarAssetType = "ttf.yaml"
+	OtfSidecarAssetType = "otf.yaml"
+	FntAssetType        = "fnt"
+
+	AtlasAssetType     = "atlas"
+	AtlasJsonAssetType = "atlas.json"
+	AsepriteAssetType  = "aseprite"
+	AseAssetType       = "ase"
+
+	TmxAssetType = "tmx"
+	TmjAssetType = "tmj"
+	TsxAssetType = "tsx"
+	TsjAssetType = "tsj"
+
+	KageAssetType = "kage"
+
+	StringsJsonAssetType = "strings.json"
+	StringsYamlAssetType = "strings.yaml"
+	StringsYmlAssetType  = "strings.yml"
+)
+
+var (
+	namesMu sync.RWMutex
+	names   = make(map[assets.AssetType]string)
+)
+
+// Register registers the importers of every built-in asset type. Json and yaml files are decoded
+// without a schema, which checks their syntax.
+func Register() error {
+	return errors.Join(
+		registerImages(),
+		registerAudio(),
+		registerFonts(),
+		registerBitmapFonts(),
+		registerAtlases(),
+		registerAseprite(),
+		registerTiled(),
+		registerShaders(),
+		registerData(),
+	)
+}
+
+func MustRegister() {
+	if err := Register(); err != nil {
+		panic(err)
+	}
+}
+
+// Name returns the name of the importer registered for an asset type, or an empty string if the
+// type has no built-in importer.
+func Name(t assets.AssetType) string {
+	namesMu.RLock()
+	defer namesMu.RUnlock()
+
+	return names[assets.NewAssetType(t.String())]
+}
+
+// register registers an importer under a name reported by Name.
+func register(name string, importer *assets.AssetImporter) error {
+	if err := assets.RegisterAssetImporter(importer); err != nil {
+		return err
+	}
+	setName(name, importer.AssetTypes...)
+	return nil
+}
+
+func setName(name string, types ...assets.AssetType) {
+	namesMu.Lock()
+	defer namesMu.Unlock()
+
+	for _, t := range types {
+		names[assets.NewAssetType(t.String())] = name
+	}
+}
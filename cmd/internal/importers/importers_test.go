@@ -0,0 +1,229 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adm87/finch-core/internal/assets"
+	"github.com/adm87/finch-core/internal/bindata"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestMain(m *testing.M) {
+	MustRegister()
+	os.Exit(m.Run())
+}
+
+func pngData(w, h int) []byte {
+	var b bytes.Buffer
+	if err := png.Encode(&b, image.NewNRGBA(image.Rect(0, 0, w, h))); err != nil {
+		panic(err)
+	}
+	return b.Bytes()
+}
+
+func wavData(format, channels, bits uint16, samples int) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(0))
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, uint32(16))
+	binary.Write(&b, binary.LittleEndian, format)
+	binary.Write(&b, binary.LittleEndian, channels)
+	binary.Write(&b, binary.LittleEndian, uint32(1000))
+	binary.Write(&b, binary.LittleEndian, uint32(1000*int(channels*bits/8)))
+	binary.Write(&b, binary.LittleEndian, channels*bits/8)
+	binary.Write(&b, binary.LittleEndian, bits)
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(samples*int(channels*bits/8)))
+	b.Write(make([]byte, samples*int(channels*bits/8)))
+	return b.Bytes()
+}
+
+func binaryData(document string) []byte {
+	data, err := bindata.Marshal([]byte(document))
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func TestImporters(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		files fstest.MapFS
+		err   error
+		fails bool
+		deps  []string
+	}{
+		{name: "image", file: "a.png", files: fstest.MapFS{"a.png": {Data: pngData(4, 4)}}},
+		{name: "corrupted image", file: "a.png", files: fstest.MapFS{"a.png": {Data: []byte("not a png")}}, fails: true},
+		{name: "wav", file: "a.wav", files: fstest.MapFS{"a.wav": {Data: wavData(1, 2, 16, 100)}}},
+		{name: "wav stream", file: "a.stream.wav", files: fstest.MapFS{"a.stream.wav": {Data: wavData(1, 1, 8, 100)}}},
+		{name: "wav not pcm", file: "a.wav", files: fstest.MapFS{"a.wav": {Data: wavData(3, 2, 16, 100)}}, err: ErrAudioInvalid},
+		{name: "wav 24 bit", file: "a.wav", files: fstest.MapFS{"a.wav": {Data: wavData(1, 2, 24, 100)}}, err: ErrAudioInvalid},
+		{name: "wav without header", file: "a.wav", files: fstest.MapFS{"a.wav": {Data: []byte("RIFF")}}, err: ErrAudioInvalid},
+		{name: "corrupted ogg", file: "a.ogg", files: fstest.MapFS{"a.ogg": {Data: []byte("OggS garbage")}}, fails: true},
+		{name: "corrupted mp3", file: "a.mp3", files: fstest.MapFS{"a.mp3": {Data: []byte("garbage")}}, fails: true},
+		{name: "corrupted font", file: "a.ttf", files: fstest.MapFS{"a.ttf": {Data: []byte("garbage")}}, fails: true},
+		{name: "font", file: "title@32.ttf", files: fstest.MapFS{"title@32.ttf": {Data: goregular.TTF}}},
+		{name: "font named like a scale", file: "ui@2x.ttf", files: fstest.MapFS{"ui@2x.ttf": {Data: goregular.TTF}}},
+		{name: "font size in path", file: "title@0.ttf", files: fstest.MapFS{"title@0.ttf": {Data: goregular.TTF}}, err: ErrFontSizeInvalid},
+		{
+			name: "font with sidecar",
+			file: "body.ttf",
+			files: fstest.MapFS{
+				"body.ttf":      {Data: goregular.TTF},
+				"body.ttf.yaml": {Data: []byte("sizes: [12, 16]\n")},
+			},
+			deps: []string{"body.ttf.yaml"},
+		},
+		{
+			name: "bitmap font corrupted page",
+			file: "a.fnt",
+			files: fstest.MapFS{
+				"a.fnt":  {Data: []byte("page id=0 file=\"a0.png\"\n")},
+				"a0.png": {Data: []byte("not a png")},
+			},
+			fails: true,
+		},
+		{name: "font sidecar", file: "a.ttf.yaml", files: fstest.MapFS{"a.ttf.yaml": {Data: []byte("sizes: [12, 16]\n")}}},
+		{name: "font sidecar size", file: "a.ttf.yaml", files: fstest.MapFS{"a.ttf.yaml": {Data: []byte("sizes: [0]\n")}}, err: ErrFontSizeInvalid},
+		{
+			name: "atlas",
+			file: "ui.atlas",
+			files: fstest.MapFS{
+				"ui.atlas": {Data: []byte(`{"frames": {"a": {"frame": {"x": 0, "y": 0, "w": 4, "h": 2}}}, "meta": {"image": "ui.png"}}`)},
+				"ui.png":   {Data: pngData(4, 4)},
+			},
+			deps: []string{"ui.png"},
+		},
+		{
+			name: "atlas frame outside of image",
+			file: "ui.atlas",
+			files: fstest.MapFS{
+				"ui.atlas": {Data: []byte(`{"frames": [{"filename": "a", "frame": {"x": 0, "y": 0, "w": 2, "h": 5}}], "meta": {"image": "ui.png"}}`)},
+				"ui.png":   {Data: pngData(4, 4)},
+			},
+			err: ErrAtlasInvalid,
+		},
+		{
+			name:  "atlas without image",
+			file:  "ui.atlas",
+			files: fstest.MapFS{"ui.atlas": {Data: []byte(`{"frames": [], "meta": {"image": "ui.png"}}`)}},
+			fails: true,
+		},
+		{
+			name: "bitmap font",
+			file: "a.fnt",
+			files: fstest.MapFS{
+				"a.fnt":  {Data: []byte("info face=\"a b\"\npage id=0 file=\"a0.png\"\nchar id=65 x=0 y=0 width=2 height=2 xoffset=0 yoffset=0 xadvance=2 page=0\n")},
+				"a0.png": {Data: pngData(4, 4)},
+			},
+			deps: []string{"a0.png"},
+		},
+		{
+			name: "bitmap font missing page",
+			file: "a.fnt",
+			files: fstest.MapFS{
+				"a.fnt":  {Data: []byte("page id=0 file=\"a0.png\"\nchar id=65 x=0 y=0 width=2 height=2 xoffset=0 yoffset=0 xadvance=2 page=1\n")},
+				"a0.png": {Data: pngData(4, 4)},
+			},
+			err: ErrBitmapFontInvalid,
+		},
+		{
+			name: "shader",
+			file: "a.kage",
+			files: fstest.MapFS{
+				"a.kage":         {Data: []byte("//kage:unit pixels\npackage main\n\n#include \"lib/noise.kage\"\n\nfunc Fragment(dst vec4, src vec2, color vec4) vec4 {\n\treturn color * noise(src)\n}\n")},
+				"lib/noise.kage": {Data: []byte("func noise(p vec2) float {\n\treturn fract(p.x)\n}\n")},
+			},
+			deps: []string{"lib/noise.kage"},
+		},
+		{name: "shader syntax", file: "a.kage", files: fstest.MapFS{"a.kage": {Data: []byte("package main\n\nfunc Fragment( {\n")}}, fails: true},
+		{name: "shader include", file: "a.kage", files: fstest.MapFS{"a.kage": {Data: []byte("#include noise.kage\n")}}, err: ErrShaderIncludeInvalid},
+		{name: "data", file: "a.yaml", files: fstest.MapFS{"a.yaml": {Data: []byte("a: [1, 2]\n")}}},
+		{name: "binary data", file: "a.enemy.yaml", files: fstest.MapFS{"a.enemy.yaml": {Data: binaryData("a: [1, 2]\n")}}, err: nil},
+		{name: "data syntax", file: "a.json", files: fstest.MapFS{"a.json": {Data: []byte(`{"a": `)}}, fails: true},
+		{name: "string table", file: "en.strings.yaml", files: fstest.MapFS{"en.strings.yaml": {Data: []byte("menu:\n  play: Play\n")}}},
+		{name: "string table not a map", file: "en.strings.json", files: fstest.MapFS{"en.strings.json": {Data: []byte(`["play"]`)}}, fails: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := assets.AssetRoot("case" + strconv.Itoa(i))
+			if err := assets.RegisterAssetFilesystem(root, tt.files); err != nil {
+				t.Fatal(err)
+			}
+
+			file := assets.AssetFile(root.String() + "/" + tt.file)
+			if !assets.HasAssetTypeSupport(file.Type()) {
+				t.Fatalf("no importer for type %q", file.Type())
+			}
+
+			err := assets.LoadAssets(file)
+			if tt.fails {
+				if err == nil {
+					t.Fatal("LoadAssets() succeeded, want an error")
+				}
+				return
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("LoadAssets() error = %v, want %v", err, tt.err)
+			}
+
+			if err == nil {
+				deps := make([]string, 0)
+				for _, dep := range assets.AssetDependencies(file) {
+					deps = append(deps, strings.TrimPrefix(dep.Path(), root.String()+"/"))
+				}
+				if len(deps) != len(tt.deps) || (len(deps) > 0 && deps[0] != tt.deps[0]) {
+					t.Fatalf("AssetDependencies() = %v, want %v", deps, tt.deps)
+				}
+			}
+		})
+	}
+}
+
+func TestShaderErrorLines(t *testing.T) {
+	files := fstest.MapFS{"a.kage": {Data: []byte("//kage:unit pixels\npackage main\n\nfunc Fragment( {\n")}}
+	if err := assets.RegisterAssetFilesystem("shaders", files); err != nil {
+		t.Fatal(err)
+	}
+
+	err := assets.LoadAssets("shaders/a.kage")
+	if err == nil || !strings.Contains(err.Error(), ": shaders/a.kage:4:") {
+		t.Fatalf("LoadAssets() error = %v, want an error on line 4", err)
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		t    assets.AssetType
+		want string
+	}{
+		{t: "png", want: "image"},
+		{t: ".PNG", want: "image"},
+		{t: "stream.ogg", want: "audio stream"},
+		{t: "ttf.yaml", want: "font sidecar"},
+		{t: "yml", want: "data"},
+		{t: "strings.json", want: "string table"},
+		{t: "enemy.yaml", want: ""},
+		{t: "txt", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := Name(tt.t); got != tt.want {
+			t.Errorf("Name(%q) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
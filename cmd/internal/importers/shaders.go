@@ -0,0 +1,101 @@
+package importers
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/adm87/finch-core/internal/assets"
+)
+
+// shaderIncludeDirective includes another shader asset, by path relative to the including file.
+const shaderIncludeDirective = "#include"
+
+var (
+	ErrShaderIncludeInvalid = errors.New("shader include is invalid")
+)
+
+// ======================================================
+// Shaders
+// ======================================================
+
+// registerShaders registers the importer of Kage shaders, which loads their includes as
+// dependencies and checks the syntax of each file. It produces the source of the shader.
+func registerShaders() error {
+	return register("shader", &assets.AssetImporter{
+		AssetTypes: []assets.AssetType{
+			KageAssetType,
+		},
+		ProcessAssetFile: func(file assets.AssetFile, data []byte) (any, error) {
+			if err := checkShader(file, data); err != nil {
+				return nil, err
+			}
+			return data, nil
+		},
+		OutputType: reflect.TypeFor[[]byte](),
+	})
+}
+
+// checkShader loads the includes of a shader and parses its source as Go, which Kage is a subset of.
+// Include directives, package clauses and //kage: directives are blanked out, so errors are reported
+// against the lines of the file.
+func checkShader(file assets.AssetFile, data []byte) error {
+	lines := strings.Split(string(data), "\n")
+	includes := make([]assets.AssetFile, 0)
+
+	for i, line := range lines {
+		include, ok, err := parseShaderInclude(file, line)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", file.Path(), i+1, err)
+		}
+		if ok {
+			includes = append(includes, include)
+			lines[i] = ""
+		} else if isShaderHeader(line) {
+			lines[i] = ""
+		}
+	}
+
+	if err := assets.LoadAssetDependencies(file, includes...); err != nil {
+		return err
+	}
+
+	// Included files may have no package clause, so one is added to the first line.
+	if strings.TrimSpace(lines[0]) == "" {
+		lines[0] = "package main"
+	} else {
+		lines[0] = "package main; " + lines[0]
+	}
+
+	source := strings.Join(lines, "\n")
+	if _, err := parser.ParseFile(token.NewFileSet(), file.Path(), source, parser.SkipObjectResolution); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func parseShaderInclude(file assets.AssetFile, line string) (assets.AssetFile, bool, error) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, shaderIncludeDirective) {
+		return "", false, nil
+	}
+
+	name, err := strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(line, shaderIncludeDirective)))
+	if err != nil || name == "" {
+		return "", false, fmt.Errorf("%w: %s", ErrShaderIncludeInvalid, line)
+	}
+
+	return assets.AssetFile(path.Join(path.Dir(file.Path()), name)), true, nil
+}
+
+// isShaderHeader reports whether a line is a package clause or a //kage: directive.
+func isShaderHeader(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "package ") || strings.HasPrefix(line, "//kage:")
+}
@@ -0,0 +1,135 @@
+package importers
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"reflect"
+
+	"github.com/adm87/finch-core/internal/assets"
+	"github.com/adm87/finch-core/tiled"
+)
+
+// ======================================================
+// Tiled Maps
+// ======================================================
+
+// registerTiled registers the importers of Tiled maps and tilesets. External tilesets and every
+// image used by a map are loaded as its dependencies.
+func registerTiled() error {
+	return errors.Join(
+		register("tiled map", &assets.AssetImporter{
+			AssetTypes: []assets.AssetType{
+				TmxAssetType,
+				TmjAssetType,
+			},
+			ProcessAssetFile: func(file assets.AssetFile, data []byte) (any, error) {
+				return parseTiledMap(file, data)
+			},
+			OutputType: reflect.TypeFor[*tiled.Map](),
+		}),
+		register("tiled tileset", &assets.AssetImporter{
+			AssetTypes: []assets.AssetType{
+				TsxAssetType,
+				TsjAssetType,
+			},
+			ProcessAssetFile: func(file assets.AssetFile, data []byte) (any, error) {
+				return parseTiledTileset(file, data)
+			},
+			OutputType: reflect.TypeFor[*tiled.Tileset](),
+		}),
+	)
+}
+
+func parseTiledMap(file assets.AssetFile, data []byte) (*tiled.Map, error) {
+	var (
+		m   *tiled.Map
+		err error
+	)
+
+	switch file.Type() {
+	case TmjAssetType:
+		m, err = tiled.DecodeTMJ(bytes.NewReader(data))
+	default:
+		m, err = tiled.DecodeTMX(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dir := path.Dir(file.Path())
+
+	// Embedded tilesets and image layers reference images relative to the map.
+	images := make([]assets.AssetFile, 0)
+	for _, ref := range m.Tilesets {
+		if ref.Tileset != nil {
+			images = append(images, resolveTiledImages(dir, ref.Tileset)...)
+		}
+	}
+	for _, layer := range m.AllLayers() {
+		if layer.Image != nil {
+			layer.Image.Source = path.Join(dir, layer.Image.Source)
+			images = append(images, assets.AssetFile(layer.Image.Source))
+		}
+	}
+
+	err = m.ResolveTilesets(func(source string) (*tiled.Tileset, error) {
+		tilesetFile := assets.AssetFile(path.Join(dir, source))
+		if err := assets.LoadAssetDependencies(file, tilesetFile); err != nil {
+			return nil, err
+		}
+		return assets.GetAsset[*tiled.Tileset](tilesetFile)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := assets.LoadAssetDependencies(file, images...); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func parseTiledTileset(file assets.AssetFile, data []byte) (*tiled.Tileset, error) {
+	var (
+		tileset *tiled.Tileset
+		err     error
+	)
+
+	switch file.Type() {
+	case TsjAssetType:
+		tileset, err = tiled.DecodeTSJ(bytes.NewReader(data))
+	default:
+		tileset, err = tiled.DecodeTSX(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	images := resolveTiledImages(path.Dir(file.Path()), tileset)
+	if err := assets.LoadAssetDependencies(file, images...); err != nil {
+		return nil, err
+	}
+
+	return tileset, nil
+}
+
+// resolveTiledImages rewrites the image sources of a tileset to asset paths and returns them.
+func resolveTiledImages(dir string, tileset *tiled.Tileset) []assets.AssetFile {
+	images := make([]assets.AssetFile, 0, 1)
+
+	if tileset.Image != nil {
+		tileset.Image.Source = path.Join(dir, tileset.Image.Source)
+		images = append(images, assets.AssetFile(tileset.Image.Source))
+	}
+
+	for _, tile := range tileset.Tiles {
+		if tile.Image != nil {
+			tile.Image.Source = path.Join(dir, tile.Image.Source)
+			images = append(images, assets.AssetFile(tile.Image.Source))
+		}
+	}
+
+	return images
+}
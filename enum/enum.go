@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/adm87/finch-core/linq"
+	"gopkg.in/yaml.v3"
 )
 
 type Enum[T ~int] interface {
@@ -26,6 +27,19 @@ func UnmarshalEnum[T Enum[T]](data []byte) (T, error) {
 	return Value[T](str)
 }
 
+func MarshalEnumYAML[T Enum[T]](e T) (any, error) {
+	return e.String(), nil
+}
+
+func UnmarshalEnumYAML[T Enum[T]](node *yaml.Node) (T, error) {
+	var str string
+	if err := node.Decode(&str); err != nil {
+		var zero T
+		return zero, err
+	}
+	return Value[T](str)
+}
+
 func Mapping[T Enum[T]]() map[string]T {
 	m := make(map[string]T)
 	for _, v := range Values[T]() {
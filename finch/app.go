@@ -3,7 +3,9 @@ package finch
 import (
 	"context"
 	"log/slog"
+	"time"
 
+	"github.com/adm87/finch-core/internal/assets"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -66,6 +68,7 @@ func NewApp() *App {
 func NewAppWithContext(ctx context.Context) *App {
 	s := NewScreen(800, 600, 1.0, false)
 	t := NewTime(60.0)
+	SetAssetLogger(slog.Default())
 	return &App{ctx: NewContext(ctx, slog.Default(), s, t)}
 }
 
@@ -114,6 +117,13 @@ func (a *App) WithWindow(window *Window) *App {
 
 func (a *App) WithLogger(logger *slog.Logger) *App {
 	a.ctx = a.ctx.SetLogger(logger)
+	SetAssetLogger(logger)
+	return a
+}
+
+// WithAssetHotReload enables polling of loaded assets for modifications. Intended for development builds.
+func (a *App) WithAssetHotReload(interval time.Duration) *App {
+	EnableAssetHotReload(interval)
 	return a
 }
 
@@ -143,6 +153,9 @@ func (a *App) Update() error {
 
 	a.ctx.Time().tick()
 
+	assets.PollAssetHotReload(a.ctx.Logger())
+	updateAudio(time.Duration(a.ctx.Time().DeltaMilli() * float64(time.Millisecond)))
+
 	if update := a.UpdateFn; update != nil {
 		update(a.ctx)
 	}
@@ -0,0 +1,272 @@
+package finch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/adm87/finch-core/aseprite"
+	"github.com/adm87/finch-core/internal/assets"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	AsepriteAssetType = "aseprite"
+	AseAssetType      = "ase"
+)
+
+var (
+	ErrAsepriteSpriteEmpty = errors.New("aseprite sprite has no frames")
+)
+
+// ======================================================
+// Aseprite Sprite
+// ======================================================
+
+// AsepriteSprite is a loaded Aseprite file with every frame composited into an image.
+//
+// Single frames can be fetched through GetAsset with their index as fragment:
+//
+//	img := finch.MustGetAsset[*ebiten.Image]("assets/hero.aseprite#3")
+type AsepriteSprite struct {
+	file   *aseprite.File
+	frames []*ebiten.Image
+}
+
+// File returns the decoded Aseprite file, with its layers, cels and tags.
+func (s *AsepriteSprite) File() *aseprite.File {
+	return s.file
+}
+
+func (s *AsepriteSprite) FrameCount() int {
+	return len(s.frames)
+}
+
+func (s *AsepriteSprite) Frame(index int) *ebiten.Image {
+	return s.frames[index]
+}
+
+func (s *AsepriteSprite) Duration(index int) time.Duration {
+	return s.file.Frames[index].Duration
+}
+
+func (s *AsepriteSprite) Tag(name string) (aseprite.Tag, bool) {
+	return s.file.Tag(name)
+}
+
+func (s *AsepriteSprite) AssetFragment(name string) (any, bool) {
+	index, err := strconv.Atoi(name)
+	if err != nil || index < 0 || index >= len(s.frames) {
+		return nil, false
+	}
+	return s.frames[index], true
+}
+
+func RegisterAsepriteAssetImport() {
+	RegisterAssetImporter(&AssetImporter{
+		AssetTypes: []AssetType{
+			AsepriteAssetType,
+			AseAssetType,
+		},
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			decoded, err := aseprite.Decode(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+
+			sprite := &AsepriteSprite{
+				file:   decoded,
+				frames: make([]*ebiten.Image, len(decoded.Frames)),
+			}
+			for i := range decoded.Frames {
+				img, err := decoded.Composite(i)
+				if err != nil {
+					return nil, err
+				}
+				sprite.frames[i] = ebiten.NewImageFromImage(img)
+			}
+
+			return sprite, nil
+		},
+		CleanupAssetFile: func(file AssetFile, data any) error {
+			sprite, ok := data.(*AsepriteSprite)
+			if !ok {
+				return fmt.Errorf("%w: expected *AsepriteSprite, got %T", ErrAssetTypeMismatch, data)
+			}
+			for _, frame := range sprite.frames {
+				frame.Deallocate()
+			}
+			return nil
+		},
+		EstimateAssetSize: func(file AssetFile, data any) int64 {
+			sprite, ok := data.(*AsepriteSprite)
+			if !ok {
+				return 0
+			}
+			return int64(sprite.file.Width) * int64(sprite.file.Height) * 4 * int64(len(sprite.frames))
+		},
+		OutputType: reflect.TypeFor[*AsepriteSprite](),
+	})
+}
+
+func GetAsepriteSprite(file AssetFile) (*AsepriteSprite, error) {
+	sprite, err := GetAsset[*AsepriteSprite](file)
+	if err != nil {
+		return nil, err
+	}
+	return sprite, nil
+}
+
+func MustGetAsepriteSprite(file AssetFile) *AsepriteSprite {
+	return MustGetAsset[*AsepriteSprite](file)
+}
+
+// ======================================================
+// Aseprite Animation
+// ======================================================
+
+// AsepriteAnimation plays a tag of an Aseprite sprite.
+//
+// The animation refers to its sprite by asset file and fetches it on every update, so it picks up
+// changes made while the sprite is hot reloaded. An empty tag plays every frame of the sprite.
+type AsepriteAnimation struct {
+	file     AssetFile
+	tag      string
+	position int
+	elapsed  time.Duration
+	loops    int
+	finished bool
+
+	// Speed scales the playback rate. It is 1 by default.
+	Speed float64
+}
+
+func NewAsepriteAnimation(file AssetFile, tag string) *AsepriteAnimation {
+	return &AsepriteAnimation{
+		file:  file,
+		tag:   tag,
+		Speed: 1,
+	}
+}
+
+func (a *AsepriteAnimation) File() AssetFile {
+	return a.file
+}
+
+func (a *AsepriteAnimation) Tag() string {
+	return a.tag
+}
+
+// SetTag switches the animation to another tag and restarts it, unless the tag is already playing.
+func (a *AsepriteAnimation) SetTag(tag string) {
+	if a.tag == tag {
+		return
+	}
+	a.tag = tag
+	a.Reset()
+}
+
+func (a *AsepriteAnimation) Reset() {
+	a.position = 0
+	a.elapsed = 0
+	a.loops = 0
+	a.finished = false
+}
+
+// IsFinished reports whether a tag with a repeat count has played that many times.
+func (a *AsepriteAnimation) IsFinished() bool {
+	return a.finished
+}
+
+// Frame returns the index of the current frame in the sprite.
+func (a *AsepriteAnimation) Frame() (int, error) {
+	_, frame, err := a.current()
+	return frame, err
+}
+
+// Image returns the image of the current frame.
+func (a *AsepriteAnimation) Image() (*ebiten.Image, error) {
+	sprite, frame, err := a.current()
+	if err != nil {
+		return nil, err
+	}
+	return sprite.Frame(frame), nil
+}
+
+// Update advances the animation by the time elapsed since the last update.
+func (a *AsepriteAnimation) Update(delta time.Duration) error {
+	sprite, frames, repeat, err := a.sequence()
+	if err != nil {
+		return err
+	}
+
+	if a.finished || len(frames) == 0 {
+		return nil
+	}
+
+	a.position = min(a.position, len(frames)-1)
+	a.elapsed += time.Duration(float64(delta) * a.Speed)
+
+	for {
+		duration := sprite.Duration(frames[a.position])
+		if duration <= 0 || a.elapsed < duration {
+			return nil
+		}
+		a.elapsed -= duration
+
+		if a.position < len(frames)-1 {
+			a.position++
+			continue
+		}
+
+		a.loops++
+		if repeat > 0 && a.loops >= repeat {
+			a.finished = true
+			a.elapsed = 0
+			return nil
+		}
+		a.position = 0
+	}
+}
+
+func (a *AsepriteAnimation) current() (*AsepriteSprite, int, error) {
+	sprite, frames, _, err := a.sequence()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(frames) == 0 {
+		return nil, 0, assets.NewAssetError("animate", a.file, ErrAsepriteSpriteEmpty)
+	}
+	return sprite, frames[min(a.position, len(frames)-1)], nil
+}
+
+// sequence returns the sprite, the frames of one pass of the animation and its repeat count.
+func (a *AsepriteAnimation) sequence() (*AsepriteSprite, []int, int, error) {
+	sprite, err := GetAsepriteSprite(a.file)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	if a.tag == "" {
+		frames := make([]int, sprite.FrameCount())
+		for i := range frames {
+			frames[i] = i
+		}
+		return sprite, frames, 0, nil
+	}
+
+	tag, exists := sprite.Tag(a.tag)
+	if !exists {
+		return nil, nil, 0, assets.NewAssetError("animate", a.file, fmt.Errorf("%w: tag %s", ErrAssetFragmentNotFound, a.tag))
+	}
+
+	frames := tag.Frames()
+	for i, frame := range frames {
+		frames[i] = min(max(frame, 0), sprite.FrameCount()-1)
+	}
+
+	return sprite, frames, tag.Repeat, nil
+}
@@ -1,453 +1,810 @@
 package finch
 
 import (
-	"embed"
-	"errors"
-	"fmt"
 	"io/fs"
-	"os"
-	"path/filepath"
-	"strings"
-	"sync"
+	"log/slog"
+	"time"
 
-	"github.com/adm87/finch-core/hashset"
-	"github.com/adm87/finch-core/linq"
+	"github.com/adm87/finch-core/internal/assets"
 )
 
+// The asset runtime lives in internal/assets, which does not depend on ebiten, so that the offline
+// tools can load, hash and validate assets without cgo or a display. The declarations below are its
+// public API, re-exported as part of finch.
+
+// ======================================================
+// Assets
+// ======================================================
+
 var (
-	ErrAssetManagerNotFound    = errors.New("asset manager not found")
-	ErrAssetManagerConflict    = errors.New("asset manager conflict")
-	ErrAssetManagerNil         = errors.New("asset manager is nil")
-	ErrAssetFilesystemConflict = errors.New("asset filesystem conflict")
-	ErrAssetFilesystemNil      = errors.New("asset filesystem is nil")
-	ErrAssetInvalidType        = errors.New("asset has invalid type")
-	ErrAssetTypeMismatch       = errors.New("asset type mismatch")
-	ErrAssetNotLoaded          = errors.New("asset not loaded")
-	ErrAssetIsLoaded           = errors.New("asset is already loaded")
-	ErrAssetIsLoading          = errors.New("asset is currently loading")
-	ErrAssetTypeEmpty          = errors.New("asset type is empty")
-	ErrAssetRootEmpty          = errors.New("asset root is empty")
+	ErrAssetManagerNotFound    = assets.ErrAssetManagerNotFound
+	ErrAssetManagerConflict    = assets.ErrAssetManagerConflict
+	ErrAssetManagerNil         = assets.ErrAssetManagerNil
+	ErrAssetFilesystemConflict = assets.ErrAssetFilesystemConflict
+	ErrAssetFilesystemNil      = assets.ErrAssetFilesystemNil
+	ErrAssetInvalidType        = assets.ErrAssetInvalidType
+	ErrAssetTypeMismatch       = assets.ErrAssetTypeMismatch
+	ErrAssetNotLoaded          = assets.ErrAssetNotLoaded
+	ErrAssetIsLoaded           = assets.ErrAssetIsLoaded
+	ErrAssetIsLoading          = assets.ErrAssetIsLoading
+	ErrAssetTypeEmpty          = assets.ErrAssetTypeEmpty
+	ErrAssetRootEmpty          = assets.ErrAssetRootEmpty
+	ErrAssetLayerNotFound      = assets.ErrAssetLayerNotFound
+	ErrAssetPathEmpty          = assets.ErrAssetPathEmpty
+	ErrAssetPathInvalid        = assets.ErrAssetPathInvalid
+	ErrAssetPathEscapesRoot    = assets.ErrAssetPathEscapesRoot
 )
 
+// AssetImporter manages allocation and deallocation of a specific asset types.
+//
+// OutputType optionally declares the type of the data produced by ProcessAssetFile. When set,
+// AssetRef handles are checked against it. FallbackAsset optionally provides a placeholder that
+// GetAsset returns for files that failed to load.
+//
+// ProcessAssetStream optionally replaces ProcessAssetFile for importers of large files, such as long
+// music tracks, that read their file as they need it instead of all at once. See AssetStream.
+type AssetImporter = assets.AssetImporter
+
+// AssetAllocator is a function that takes raw asset data and converts it into a usable form.
+type AssetAllocator = assets.AssetAllocator
+
+// AssetDeallocator is a function that takes a loaded asset and frees its resources.
+type AssetDeallocator = assets.AssetDeallocator
+
+// AssetType represents the type of an asset, typically derived from its file extension.
+//
+// Types are lower case and may span several extensions, as in "atlas.json".
+type AssetType = assets.AssetType
+
+// NewAssetType returns an asset type written as a lower case extension, without its leading dot.
+func NewAssetType(t string) AssetType {
+	return assets.NewAssetType(t)
+}
+
+// AssetRoot represents the root directory of an asset file.
+type AssetRoot = assets.AssetRoot
+
+// AssetFile is a handle to an asset file and its associated type and data.
+//
+// Asset files are paths relative to the working directory or an asset filesystem, whose first element
+// is their root, as in "assets/sprites/hero.png". Use NewAssetFile to build them from paths that may be
+// written in another form.
+type AssetFile = assets.AssetFile
+
+// NewAssetFile returns the canonical asset file of a path.
+//
+// Backslashes are replaced with slashes, and the path is cleaned of redundant separators, "." elements
+// and leading slashes. Paths that are empty, name a volume such as "C:", or use ".." to escape their root
+// are rejected. A fragment, as in "sprites.atlas#hero", is kept as is.
+func NewAssetFile(p string) (AssetFile, error) {
+	return assets.NewAssetFile(p)
+}
+
+func MustNewAssetFile(p string) AssetFile {
+	return assets.MustNewAssetFile(p)
+}
+
+func HasAssetTypeSupport(t AssetType) bool {
+	return assets.HasAssetTypeSupport(t)
+}
+
+func RegisterAssetImporter(manager *AssetImporter) error {
+	return assets.RegisterAssetImporter(manager)
+}
+
+// RegisterAssetFilesystem registers the base filesystem of an asset root.
+//
+// The base layer is named after the root and mounted at priority 0. Use MountAssetFilesystem
+// to stack additional filesystems, such as DLC archives or mod folders, on top of it.
+func RegisterAssetFilesystem(root AssetRoot, filesystem fs.FS) error {
+	return assets.RegisterAssetFilesystem(root, filesystem)
+}
+
+// RegisterAssetFilesystemMapped registers the base filesystem of an asset root, mapping asset paths into
+// it as the given mapping selects.
+func RegisterAssetFilesystemMapped(root AssetRoot, filesystem fs.FS, mapping AssetPathMapping) error {
+	return assets.RegisterAssetFilesystemMapped(root, filesystem, mapping)
+}
+
+// IsAssetLoaded reports whether an asset file is loaded and has not been evicted.
+func IsAssetLoaded(file AssetFile) bool {
+	return assets.IsAssetLoaded(file)
+}
+
+// GetAsset returns the typed data of a loaded asset.
+//
+// If the asset was evicted from the cache to stay within its memory budget, it is reloaded first.
+// If the asset failed to load and its type has a fallback, the fallback is returned instead.
+//
+// Files with a fragment return a sub-asset of their loaded base asset, provided its data implements AssetFragmenter.
+func GetAsset[T any](file AssetFile) (T, error) {
+	return assets.GetAsset[T](file)
+}
+
+func MustGetAsset[T any](file AssetFile) T {
+	return assets.MustGetAsset[T](file)
+}
+
+func LoadAssets(files ...AssetFile) error {
+	return assets.LoadAssets(files...)
+}
+
+func MustLoadAssets(files ...AssetFile) {
+	assets.MustLoadAssets(files...)
+}
+
+func UnloadAssets(files ...AssetFile) error {
+	return assets.UnloadAssets(files...)
+}
+
+func MustUnloadAssets(files ...AssetFile) {
+	assets.MustUnloadAssets(files...)
+}
+
 // ======================================================
-// Asset Importer
+// Archives
 // ======================================================
 
-// AssetImporter manages allocation and deallocation of a specific asset types.
-type AssetImporter struct {
-	ProcessAssetFile AssetAllocator
-	CleanupAssetFile AssetDeallocator
-	AssetTypes       []AssetType
+var (
+	ErrAssetArchiveUnsupported = assets.ErrAssetArchiveUnsupported
+)
+
+// MountAssetArchive opens an archive file and mounts it as a named filesystem layer of an asset root,
+// as MountAssetFilesystem does. The format is selected by the file extension, which must be .zip, .tar,
+// .tar.gz, .tgz or .farc. The archive is closed when the layer is unmounted.
+//
+//	finch.MustMountAssetArchive("assets", "mod:better-ui", 20, "mods/better-ui.zip")
+func MountAssetArchive(root AssetRoot, layer string, priority int, name string) error {
+	return assets.MountAssetArchive(root, layer, priority, name)
+}
+
+func MustMountAssetArchive(root AssetRoot, layer string, priority int, name string) {
+	assets.MustMountAssetArchive(root, layer, priority, name)
+}
+
+// MountAssetArchiveData mounts an archive held in memory, such as an embedded byte slice, as a named
+// filesystem layer of an asset root. The format is selected by the extension of name, as in MountAssetArchive.
+func MountAssetArchiveData(root AssetRoot, layer string, priority int, name string, data []byte) error {
+	return assets.MountAssetArchiveData(root, layer, priority, name, data)
+}
+
+func MustMountAssetArchiveData(root AssetRoot, layer string, priority int, name string, data []byte) {
+	assets.MustMountAssetArchiveData(root, layer, priority, name, data)
+}
+
+// RegisterAssetArchive opens an archive file and registers it as the base filesystem of an asset root.
+func RegisterAssetArchive(root AssetRoot, name string) error {
+	return assets.RegisterAssetArchive(root, name)
+}
+
+func MustRegisterAssetArchive(root AssetRoot, name string) {
+	assets.MustRegisterAssetArchive(root, name)
+}
+
+// RegisterAssetArchiveData registers an archive held in memory as the base filesystem of an asset root.
+func RegisterAssetArchiveData(root AssetRoot, name string, data []byte) error {
+	return assets.RegisterAssetArchiveData(root, name, data)
+}
+
+func MustRegisterAssetArchiveData(root AssetRoot, name string, data []byte) {
+	assets.MustRegisterAssetArchiveData(root, name, data)
 }
 
 // ======================================================
-// Asset Allocation/Deallocation
+// Cache
 // ======================================================
 
-// AssetAllocator is a function that takes raw asset data and converts it into a usable form.
-type AssetAllocator func(file AssetFile, filedata []byte) (any, error)
+var (
+	ErrAssetEvicted       = assets.ErrAssetEvicted
+	ErrAssetNotRetained   = assets.ErrAssetNotRetained
+	ErrAssetBudgetInvalid = assets.ErrAssetBudgetInvalid
+)
 
-// AssetDeallocator is a function that takes a loaded asset and frees its resources.
-type AssetDeallocator func(file AssetFile, data any) error
+// AssetSizeEstimator returns the approximate number of bytes of memory used by a loaded asset.
+type AssetSizeEstimator = assets.AssetSizeEstimator
+
+// AssetEvictedReloadFunc is called when an asset evicted from the cache has been reloaded
+// in the background, or has failed to reload.
+type AssetEvictedReloadFunc = assets.AssetEvictedReloadFunc
+
+// AssetCacheOptions configures the memory budget of the asset cache.
+//
+// When a budget is exceeded, the least recently used assets that are not retained are
+// evicted until the cache fits again. Evicted assets reload transparently the next time
+// they are requested through GetAsset.
+type AssetCacheOptions = assets.AssetCacheOptions
+
+// AssetCacheStats reports the state of the asset cache.
+type AssetCacheStats = assets.AssetCacheStats
+
+// ConfigureAssetCache sets the memory budget of the asset cache and evicts assets that no longer fit.
+func ConfigureAssetCache(options AssetCacheOptions) error {
+	return assets.ConfigureAssetCache(options)
+}
+
+// GetAssetCacheStats returns the current size of the asset cache and its eviction counters.
+func GetAssetCacheStats() AssetCacheStats {
+	return assets.GetAssetCacheStats()
+}
+
+// RetainAsset marks a loaded asset as referenced, preventing it from being evicted.
+//
+// Every call must be balanced by a call to ReleaseAsset.
+func RetainAsset(file AssetFile) error {
+	return assets.RetainAsset(file)
+}
+
+// ReleaseAsset removes a reference added by RetainAsset, allowing the asset to be evicted again.
+func ReleaseAsset(file AssetFile) error {
+	return assets.ReleaseAsset(file)
+}
+
+func IsAssetEvicted(file AssetFile) bool {
+	return assets.IsAssetEvicted(file)
+}
 
 // ======================================================
-// Asset Type
+// Dependencies
 // ======================================================
 
-// AssetType represents the type of an asset, typically derived from its file extension.
-type AssetType string
+var (
+	ErrAssetDependencyCycle     = assets.ErrAssetDependencyCycle
+	ErrAssetFragmentNotFound    = assets.ErrAssetFragmentNotFound
+	ErrAssetFragmentUnsupported = assets.ErrAssetFragmentUnsupported
+)
+
+// LoadAssetDependencies loads the asset files another asset depends on, and retains them for as long as it stays loaded.
+//
+// Importers call it from ProcessAssetFile or ProcessAssetStream, before using the data of the dependencies.
+// Dependencies that are already loaded are reused, and dependencies being loaded by another goroutine are waited for.
+// When the dependent asset is unloaded or evicted its dependencies are released, but stay loaded.
+// Reloading a dependency also reloads the assets that depend on it.
+//
+//	ProcessAssetFile: func(file finch.AssetFile, data []byte) (any, error) {
+//		page := finch.AssetFile("assets/ui/page.png")
+//		if err := finch.LoadAssetDependencies(file, page); err != nil {
+//			return nil, err
+//		}
+//		img := finch.MustGetImage(page)
+//		...
+//	}
+func LoadAssetDependencies(file AssetFile, dependencies ...AssetFile) error {
+	return assets.LoadAssetDependencies(file, dependencies...)
+}
+
+func MustLoadAssetDependencies(file AssetFile, dependencies ...AssetFile) {
+	assets.MustLoadAssetDependencies(file, dependencies...)
+}
 
-func (t AssetType) String() string {
-	return string(t)
+// AssetDependencies returns the asset files a loaded asset depends on.
+func AssetDependencies(file AssetFile) []AssetFile {
+	return assets.AssetDependencies(file)
 }
 
-func (t AssetType) IsValid() error {
-	if t == "" {
-		return ErrAssetTypeEmpty
-	}
-	return nil
+// AssetDependents returns the loaded asset files that depend on an asset file.
+func AssetDependents(file AssetFile) []AssetFile {
+	return assets.AssetDependents(file)
 }
 
+// AssetFragmenter is implemented by asset data that contains named sub-assets, such as the frames of an atlas.
+//
+// Sub-assets are requested with a fragment in the asset file, as in "assets/sprites.atlas#hero_idle".
+type AssetFragmenter = assets.AssetFragmenter
+
 // ======================================================
-// Asset Root
+// Errors
 // ======================================================
 
-// AssetRoot represents the root directory of an asset file.
-type AssetRoot string
+var (
+	ErrAssetImportFailed  = assets.ErrAssetImportFailed
+	ErrAssetCleanupFailed = assets.ErrAssetCleanupFailed
+	ErrAssetLoadPanicked  = assets.ErrAssetLoadPanicked
+)
+
+// AssetError records an error and the operation and asset file that caused it.
+//
+// Asset errors wrap one of the package's sentinel errors, or the error returned by a
+// filesystem or importer, so they can be tested with errors.Is and errors.As.
+//
+//	if err := finch.LoadAssets(file); errors.Is(err, finch.ErrAssetManagerNotFound) {
+//		...
+//	}
+//
+//	var assetErr *finch.AssetError
+//	if errors.As(err, &assetErr) {
+//		log.Println(assetErr.Op, assetErr.File)
+//	}
+type AssetError = assets.AssetError
+
+// ======================================================
+// Events
+// ======================================================
+
+// AssetEventKind identifies a stage in the lifecycle of an asset file.
+type AssetEventKind = assets.AssetEventKind
+
+const (
+	AssetLoadStarted = assets.AssetLoadStarted
+	AssetLoaded      = assets.AssetLoaded
+	AssetLoadFailed  = assets.AssetLoadFailed
+	AssetUnloaded    = assets.AssetUnloaded
+	AssetReloaded    = assets.AssetReloaded
+	AssetEvicted     = assets.AssetEvicted
+)
+
+// AssetEvent describes something that happened to an asset file.
+//
+// Duration and Bytes are set for loaded and reloaded events, and Err is set for failed loads.
+type AssetEvent = assets.AssetEvent
 
-func (r AssetRoot) String() string {
-	return string(r)
+// AssetEventFunc is called for every asset event.
+type AssetEventFunc = assets.AssetEventFunc
+
+// AssetLoadTiming is the most recent load duration of an asset file.
+type AssetLoadTiming = assets.AssetLoadTiming
+
+// AssetLoadReport aggregates load timings of the assets loaded so far.
+type AssetLoadReport = assets.AssetLoadReport
+
+// SetAssetLogger sets the logger asset events are written to. Apps use their context's logger by default.
+func SetAssetLogger(logger *slog.Logger) {
+	assets.SetAssetLogger(logger)
+}
+
+// SubscribeAssetEvents registers a callback that is invoked for every asset event.
+//
+// Callbacks run on the goroutine that caused the event, which may be a background load
+// batch. The returned function removes the subscription.
+func SubscribeAssetEvents(fn AssetEventFunc) (unsubscribe func()) {
+	return assets.SubscribeAssetEvents(fn)
+}
+
+// GetAssetLoadReport returns the load timings recorded so far, including the given number of slowest assets.
+func GetAssetLoadReport(slowest int) AssetLoadReport {
+	return assets.GetAssetLoadReport(slowest)
 }
 
-func (r AssetRoot) IsValid() error {
-	if r == "" {
-		return ErrAssetRootEmpty
-	}
-	return nil
+// ResetAssetLoadReport clears the recorded load timings.
+func ResetAssetLoadReport() {
+	assets.ResetAssetLoadReport()
 }
 
 // ======================================================
-// Asset File
+// Fallbacks
 // ======================================================
 
-// AssetFile is a handle to an asset file and its associated type and data.
-type AssetFile string
+// AssetFallbackFunc returns a placeholder for an asset file that failed to load.
+//
+// The placeholder is shared by every failed file it is returned for, and is never passed to
+// the importer's CleanupAssetFile.
+type AssetFallbackFunc = assets.AssetFallbackFunc
+
+// AssetFailure records an asset file that failed to load.
+type AssetFailure = assets.AssetFailure
 
-// Path returns the file path of the asset.
-func (f AssetFile) Path() string {
-	return string(f)
+// SetAssetFallback sets the placeholder returned by GetAsset for files of an asset type that failed to load,
+// overriding the FallbackAsset of the type's importer. A nil fallback restores the importer's.
+func SetAssetFallback(t AssetType, fallback AssetFallbackFunc) error {
+	return assets.SetAssetFallback(t, fallback)
 }
 
-// Root returns the root directory of the asset file.
-func (f AssetFile) Root() AssetRoot {
-	fpath := strings.ReplaceAll(filepath.Clean(f.Path()), "\\", "/")
-	fpath = strings.TrimPrefix(fpath, "/")
+func MustSetAssetFallback(t AssetType, fallback AssetFallbackFunc) {
+	assets.MustSetAssetFallback(t, fallback)
+}
 
-	parts := strings.Split(fpath, "/")
-	if len(parts) == 0 {
-		return ""
-	}
+// FailedAssets returns the asset files whose most recent load failed, sorted by path.
+func FailedAssets() []AssetFailure {
+	return assets.FailedAssets()
+}
 
-	return AssetRoot(parts[0])
+func IsAssetFailed(file AssetFile) bool {
+	return assets.IsAssetFailed(file)
 }
 
-// Type returns the asset type based on the file extension.
-func (f AssetFile) Type() AssetType {
-	return AssetType(filepath.Ext(f.Path())[1:])
+// ClearFailedAssets forgets every recorded load failure. GetAsset stops returning fallbacks for the
+// cleared files until they fail again.
+func ClearFailedAssets() {
+	assets.ClearFailedAssets()
 }
 
-func (f AssetFile) Load() error {
-	return LoadAssets(f)
+// ======================================================
+// Hashes
+// ======================================================
+
+var (
+	ErrAssetHashMismatch         = assets.ErrAssetHashMismatch
+	ErrAssetHashNotListed        = assets.ErrAssetHashNotListed
+	ErrAssetHashManifestNil      = assets.ErrAssetHashManifestNil
+	ErrAssetVerifyModeInvalid    = assets.ErrAssetVerifyModeInvalid
+	ErrAssetVerificationDisabled = assets.ErrAssetVerificationDisabled
+)
+
+// AssetHash is the hex encoded SHA-256 hash of the contents of an asset file.
+type AssetHash = assets.AssetHash
+
+// HashAssetData returns the content hash of asset file data.
+func HashAssetData(data []byte) AssetHash {
+	return assets.HashAssetData(data)
 }
 
-func (f AssetFile) MustLoad() {
-	if err := f.Load(); err != nil {
-		panic(err)
-	}
+// AssetStamp records the content hash and size in bytes of an asset file.
+//
+// Streamed files are hashed only while verification is on, so their stamps otherwise have an empty hash.
+type AssetStamp = assets.AssetStamp
+
+// NewAssetStamp returns the stamp of asset file data.
+func NewAssetStamp(data []byte) AssetStamp {
+	return assets.NewAssetStamp(data)
 }
 
-func (f AssetFile) Unload() error {
-	return UnloadAssets(f)
+// GetAssetStamp returns the content hash and size of a loaded asset file, as they were when it was last
+// loaded or reloaded. Evicted files keep their stamp until they are unloaded.
+func GetAssetStamp(file AssetFile) (AssetStamp, error) {
+	return assets.GetAssetStamp(file)
 }
 
-func (f AssetFile) MustUnload() {
-	if err := f.Unload(); err != nil {
-		panic(err)
-	}
+func MustGetAssetStamp(file AssetFile) AssetStamp {
+	return assets.MustGetAssetStamp(file)
 }
 
-func (f AssetFile) Get() (any, error) {
-	return GetAsset[any](f)
+// GetAssetStamps returns the stamps of every loaded asset file.
+func GetAssetStamps() map[AssetFile]AssetStamp {
+	return assets.GetAssetStamps()
 }
 
-func (f AssetFile) MustGet() any {
-	data, err := f.Get()
+// AssetHashManifest maps asset paths to the content hash and size of the files of a build.
+//
+//	version: 1.4.0
+//	files:
+//	  assets/ui/button.png:
+//	    hash: 3f0a...
+//	    size: 1422
+type AssetHashManifest = assets.AssetHashManifest
+
+// AssetHashDiff lists the files that differ between two hash manifests, in lexical order.
+type AssetHashDiff = assets.AssetHashDiff
+
+// BuildAssetHashManifest hashes the asset files matching the given paths or glob patterns (see path.Match),
+// read through the registered asset filesystems.
+func BuildAssetHashManifest(version string, patterns ...string) (*AssetHashManifest, error) {
+	return assets.BuildAssetHashManifest(version, patterns...)
+}
 
-	if err != nil {
-		panic(err)
-	}
+// ParseAssetHashManifest decodes a hash manifest from data. The format is selected by the asset type, which must be json, yaml or yml.
+func ParseAssetHashManifest(t AssetType, data []byte) (*AssetHashManifest, error) {
+	return assets.ParseAssetHashManifest(t, data)
+}
 
-	return data
+// ReadAssetHashManifest reads and decodes a hash manifest file through the registered asset filesystems.
+//
+// The manifest file itself is never verified.
+func ReadAssetHashManifest(file AssetFile) (*AssetHashManifest, error) {
+	return assets.ReadAssetHashManifest(file)
+}
+
+// AssetVerifyMode selects which asset files are verified against the hash manifest as they are read.
+type AssetVerifyMode = assets.AssetVerifyMode
+
+const (
+	// AssetVerifyOff reads every file without verifying it.
+	AssetVerifyOff = assets.AssetVerifyOff
+	// AssetVerifyListed refuses files whose contents differ from the manifest, and reads files it does not list.
+	AssetVerifyListed = assets.AssetVerifyListed
+	// AssetVerifyStrict refuses files whose contents differ from the manifest, and files it does not list.
+	AssetVerifyStrict = assets.AssetVerifyStrict
+)
+
+// SetAssetVerification verifies every asset file read from now on against a hash manifest, including
+// files read by importers such as atlas pages and font sidecars. Files that fail verification fail
+// to load with ErrAssetHashMismatch, or ErrAssetHashNotListed in strict mode.
+//
+// AssetVerifyOff turns verification off, and the manifest may be nil.
+func SetAssetVerification(manifest *AssetHashManifest, mode AssetVerifyMode) error {
+	return assets.SetAssetVerification(manifest, mode)
+}
+
+func MustSetAssetVerification(manifest *AssetHashManifest, mode AssetVerifyMode) {
+	assets.MustSetAssetVerification(manifest, mode)
+}
+
+// AssetVerification returns the hash manifest and mode asset files are verified with.
+func AssetVerification() (*AssetHashManifest, AssetVerifyMode) {
+	return assets.AssetVerification()
+}
+
+// VerifyAssetFiles reads asset files and checks them against the hash manifest, without loading them.
+// If no files are given, every file the manifest lists is checked, which detects a corrupted install
+// up front. Verification must be on, or ErrAssetVerificationDisabled is returned.
+func VerifyAssetFiles(files ...AssetFile) error {
+	return assets.VerifyAssetFiles(files...)
 }
 
 // ======================================================
-// Asset Management
+// Layers
 // ======================================================
 
 var (
-	assetCache       = make(map[AssetFile]any)
-	assetManagers    = make(map[AssetType]*AssetImporter)
-	assetFilesystems = make(map[AssetRoot]fs.FS)
-	assetsLoading    = hashset.New[AssetFile]()
-	assetsMu         = sync.RWMutex{}
+	ErrAssetPathMappingInvalid = assets.ErrAssetPathMappingInvalid
 )
 
-func HasAssetTypeSupport(t AssetType) bool {
-	_, exists := assetManagers[t]
-	return exists
+// AssetPathMapping selects how asset paths map to locations within a mounted filesystem.
+type AssetPathMapping = assets.AssetPathMapping
+
+const (
+	// AssetPathDetect maps paths as AssetPathRooted if the filesystem contains a directory named after
+	// the root, as embedded filesystems and archives of the root directory do, and as AssetPathRelative otherwise.
+	AssetPathDetect = assets.AssetPathDetect
+	// AssetPathRelative strips the root, so "assets/ui/button.png" is read as "ui/button.png".
+	AssetPathRelative = assets.AssetPathRelative
+	// AssetPathRooted keeps the root, so "assets/ui/button.png" is read as "assets/ui/button.png".
+	AssetPathRooted = assets.AssetPathRooted
+)
+
+// MountAssetFilesystem adds a named filesystem layer to the stack of an asset root.
+//
+// Lookups fall through the stack from the highest priority layer to the lowest, so a
+// layer can override individual files of the layers below it. Layers with equal priority
+// are searched in the order they were mounted, most recent first.
+//
+//	finch.RegisterAssetFilesystem("assets", baseFS)               // "assets", priority 0
+//	finch.MountAssetFilesystem("assets", "dlc", 10, dlcArchive)
+//	finch.MountAssetFilesystem("assets", "mods", 20, os.DirFS(modDir))
+//
+// Asset paths are mapped into the filesystem as AssetPathDetect. Use MountAssetFilesystemMapped to
+// choose the mapping.
+func MountAssetFilesystem(root AssetRoot, layer string, priority int, filesystem fs.FS) error {
+	return assets.MountAssetFilesystem(root, layer, priority, filesystem)
 }
 
-func RegisterAssetImporter(manager *AssetImporter) error {
-	if manager == nil {
-		return ErrAssetManagerNil
-	}
+func MustMountAssetFilesystem(root AssetRoot, layer string, priority int, filesystem fs.FS) {
+	assets.MustMountAssetFilesystem(root, layer, priority, filesystem)
+}
 
-	for _, t := range manager.AssetTypes {
-		if err := t.IsValid(); err != nil {
-			return fmt.Errorf("%s: %w", ErrAssetInvalidType, err)
-		}
+// MountAssetFilesystemMapped adds a named filesystem layer to the stack of an asset root, mapping asset
+// paths into it as the given mapping selects.
+func MountAssetFilesystemMapped(root AssetRoot, layer string, priority int, filesystem fs.FS, mapping AssetPathMapping) error {
+	return assets.MountAssetFilesystemMapped(root, layer, priority, filesystem, mapping)
+}
 
-		if _, exists := assetManagers[t]; exists {
-			return fmt.Errorf("%s: %w", ErrAssetManagerConflict, t)
-		}
+func MustMountAssetFilesystemMapped(root AssetRoot, layer string, priority int, filesystem fs.FS, mapping AssetPathMapping) {
+	assets.MustMountAssetFilesystemMapped(root, layer, priority, filesystem, mapping)
+}
 
-		assetManagers[t] = manager
-	}
+// UnmountAssetFilesystem removes a named filesystem layer from the stack of an asset root.
+//
+// Loaded assets are not affected until they are reloaded. Archives mounted with MountAssetArchive
+// are closed.
+func UnmountAssetFilesystem(root AssetRoot, layer string) error {
+	return assets.UnmountAssetFilesystem(root, layer)
+}
 
-	return nil
+// AssetFilesystemLayers returns the layer names of an asset root, highest priority first.
+func AssetFilesystemLayers(root AssetRoot) []string {
+	return assets.AssetFilesystemLayers(root)
 }
 
-func RegisterAssetFilesystem(root AssetRoot, filesystem fs.FS) error {
-	if err := root.IsValid(); err != nil {
-		return err
-	}
+// AssetFileLayer returns the name of the layer that served a loaded asset file.
+//
+// The name is empty if the file was read directly from disk.
+func AssetFileLayer(file AssetFile) (string, error) {
+	return assets.AssetFileLayer(file)
+}
 
-	if filesystem == nil {
-		return errors.New("filesystem is nil")
-	}
+// ResolveAssetFileLayer returns the name of the layer that would currently serve an asset file.
+//
+// The name is empty if no filesystem is registered for the file's root.
+func ResolveAssetFileLayer(file AssetFile) (string, error) {
+	return assets.ResolveAssetFileLayer(file)
+}
 
-	if _, exists := assetFilesystems[root]; exists {
-		return fmt.Errorf("%s: %s", ErrAssetFilesystemConflict, root)
-	}
+// ======================================================
+// Manifests
+// ======================================================
 
-	assetFilesystems[root] = filesystem
+var (
+	ErrAssetGroupNotFound        = assets.ErrAssetGroupNotFound
+	ErrAssetGroupConflict        = assets.ErrAssetGroupConflict
+	ErrAssetGroupNotLoaded       = assets.ErrAssetGroupNotLoaded
+	ErrAssetManifestNil          = assets.ErrAssetManifestNil
+	ErrAssetManifestInvalidType  = assets.ErrAssetManifestInvalidType
+	ErrAssetManifestFileNotFound = assets.ErrAssetManifestFileNotFound
+	ErrAssetManifestNoMatches    = assets.ErrAssetManifestNoMatches
+)
 
-	return nil
+// AssetManifest defines named groups of asset files.
+//
+// Each group is a list of asset paths or glob patterns (see path.Match). Patterns are
+// resolved against the filesystem registered for their root, or against the disk if
+// no filesystem is registered.
+//
+//	groups:
+//	  common:
+//	    - assets/fonts/*.ttf
+//	  ui:
+//	    - assets/ui/*.png
+//	  level1:
+//	    - assets/levels/level1/*.json
+//	    - assets/levels/level1/tiles.png
+type AssetManifest = assets.AssetManifest
+
+// ParseAssetManifest decodes a manifest from data. The format is selected by the asset type, which must be json, yaml or yml.
+func ParseAssetManifest(t AssetType, data []byte) (*AssetManifest, error) {
+	return assets.ParseAssetManifest(t, data)
 }
 
-func GetAsset[T any](file AssetFile) (T, error) {
-	assetsMu.RLock()
-	defer assetsMu.RUnlock()
+// ReadAssetManifest reads and decodes a manifest file through the registered asset filesystems.
+func ReadAssetManifest(file AssetFile) (*AssetManifest, error) {
+	return assets.ReadAssetManifest(file)
+}
 
-	data, ok := assetCache[file]
+// RegisterAssetManifest makes the groups of a manifest available to LoadGroup and UnloadGroup.
+//
+// Group names are global; registering a group that already exists is an error.
+func RegisterAssetManifest(manifest *AssetManifest) error {
+	return assets.RegisterAssetManifest(manifest)
+}
 
-	if !ok {
-		return *new(T), fmt.Errorf("%s: %s", ErrAssetNotLoaded, file)
-	}
+func MustRegisterAssetManifest(manifest *AssetManifest) {
+	assets.MustRegisterAssetManifest(manifest)
+}
 
-	if typed, ok := data.(T); ok {
-		return typed, nil
-	}
+// LoadGroup resolves the patterns of a registered group and loads every matched asset file.
+//
+// Files that are already loaded, for example because they are shared with another group, are skipped.
+// Files of other groups that are no longer in the cache, because they were unloaded directly or evicted,
+// are loaded again.
+// If any file fails to load, the files this call loaded are unloaded again and the group is not
+// loaded. Loading a group that is already loaded does nothing.
+func LoadGroup(group string) error {
+	return assets.LoadGroup(group)
+}
 
-	return *new(T), fmt.Errorf("%s: %s", ErrAssetTypeMismatch, file)
+func MustLoadGroup(group string) {
+	assets.MustLoadGroup(group)
 }
 
-func MustGetAsset[T any](file AssetFile) T {
-	data, err := GetAsset[T](file)
+// UnloadGroup unloads the asset files the group loaded.
+//
+// Files that also belong to another loaded group remain loaded, and so do files that were already
+// loaded when the group was.
+func UnloadGroup(group string) error {
+	return assets.UnloadGroup(group)
+}
 
-	if err != nil {
-		panic(err)
-	}
+func MustUnloadGroup(group string) {
+	assets.MustUnloadGroup(group)
+}
 
-	return data
+// ResolveGroup expands the patterns of a registered group into the asset files they match.
+//
+// Plain paths are always included, so missing files surface as load errors.
+func ResolveGroup(group string) ([]AssetFile, error) {
+	return assets.ResolveGroup(group)
 }
 
-func LoadAssets(files ...AssetFile) error {
-	if len(files) == 0 {
-		return nil
-	}
+func IsGroupLoaded(group string) bool {
+	return assets.IsGroupLoaded(group)
+}
+
+// ======================================================
+// References
+// ======================================================
 
-	requests := hashset.New[AssetFile]()
-	errs := make([]error, 0)
+// AssetRef is a typed handle to an asset file whose data is expected to be of type T.
+//
+// Refs are created with NewAssetRef, which checks T against the output type declared by the
+// importer of the file's type. Refs created before their importer is registered are checked
+// when it is. In data files, refs are marshalled as their path.
+//
+//	type Level struct {
+//		Tiles finch.AssetRef[*ebiten.Image] `json:"tiles"`
+//	}
+type AssetRef[T any] = assets.AssetRef[T]
+
+// NewAssetRef returns a typed handle to the asset file at path.
+func NewAssetRef[T any](path string) (AssetRef[T], error) {
+	return assets.NewAssetRef[T](path)
+}
 
-	if err := buildAssetRequests(requests, files); err != nil {
-		errs = append(errs, err)
-	}
+func MustAssetRef[T any](path string) AssetRef[T] {
+	return assets.MustAssetRef[T](path)
+}
 
-	if len(requests) == 0 {
-		return errors.Join(errs...)
-	}
+// ======================================================
+// Hot Reload
+// ======================================================
 
-	if err := loadAssetBatches(linq.Batch(requests.ToSlice(), 100)); err != nil {
-		errs = append(errs, err)
-	}
+// DefaultAssetHotReloadInterval is the polling interval used when hot reload is enabled without one.
+const DefaultAssetHotReloadInterval = assets.DefaultAssetHotReloadInterval
 
-	return errors.Join(errs...)
+// EnableAssetHotReload turns on polling of loaded asset files for modifications.
+//
+// Hot reload is a development feature. Each poll stats every loaded asset file and
+// reloads the ones whose modification time has changed. Polling is driven by the
+// application's update loop, so importers and event subscribers run on the game thread.
+func EnableAssetHotReload(interval time.Duration) {
+	assets.EnableAssetHotReload(interval)
 }
 
-func MustLoadAssets(files ...AssetFile) {
-	if err := LoadAssets(files...); err != nil {
-		panic(err)
-	}
+// DisableAssetHotReload turns off polling of loaded asset files.
+func DisableAssetHotReload() {
+	assets.DisableAssetHotReload()
 }
 
-func UnloadAssets(files ...AssetFile) error {
-	assetsMu.Lock()
-	defer assetsMu.Unlock()
+func IsAssetHotReloadEnabled() bool {
+	return assets.IsAssetHotReloadEnabled()
+}
 
-	for _, file := range files {
-		asset, exists := assetCache[file]
-		if !exists {
-			return fmt.Errorf("%s: %s", ErrAssetNotLoaded, file)
-		}
+// PollAssetChanges checks every loaded asset file for modifications and reloads the ones that changed.
+// Files that failed to load are loaded again once they change, or once they exist.
+//
+// A file is also considered modified when a different filesystem layer now serves it, for
+// example after a mod has been mounted over its root. Files that can no longer be read are
+// skipped; they remain loaded with their previous data.
+func PollAssetChanges() error {
+	return assets.PollAssetChanges()
+}
 
-		manager, exists := assetManagers[file.Type()]
-		if !exists {
-			return fmt.Errorf("%s: %s", ErrAssetManagerNotFound, file.Type())
-		}
+// ReloadAsset re-imports a loaded asset file in place.
+//
+// The importer processes the current file contents, the previous asset data is passed
+// to the importer's cleanup function, and an AssetReloaded event is emitted. The assets
+// that depend on the file are reloaded afterwards, each emitting its own event.
+//
+// A file that failed to load is loaded again, so that once it is fixed it replaces its fallback.
+func ReloadAsset(file AssetFile) error {
+	return assets.ReloadAsset(file)
+}
 
-		if manager.CleanupAssetFile != nil {
-			if err := manager.CleanupAssetFile(file, asset); err != nil {
-				return fmt.Errorf("failed to deallocate asset %s: %w", file, err)
-			}
-		}
+func MustReloadAsset(file AssetFile) {
+	assets.MustReloadAsset(file)
+}
 
-		delete(assetCache, file)
-	}
+// ======================================================
+// Streams
+// ======================================================
 
-	return nil
-}
+// AssetStream is an open asset file handed to a streaming importer.
+//
+// The stream stays open for as long as the asset is loaded, and is closed after the importer's
+// CleanupAssetFile when the asset is unloaded, evicted or reloaded. Importers must not close it.
+// Files of filesystems that cannot seek, such as compressed zip entries, are copied to a temporary
+// file and streamed from there. The temporary file is removed when the stream is closed.
+type AssetStream = assets.AssetStream
 
-func MustUnloadAssets(files ...AssetFile) {
-	if err := UnloadAssets(files...); err != nil {
-		panic(err)
-	}
-}
+// AssetStreamAllocator is a function that takes an open asset file and converts it into a usable form,
+// reading the file as it needs it instead of all at once.
+type AssetStreamAllocator = assets.AssetStreamAllocator
 
-func buildAssetRequests(requests hashset.Set[AssetFile], files []AssetFile) error {
-	errs := make([]error, 0)
+// ======================================================
+// Data
+// ======================================================
 
-	for _, file := range files {
-		if _, exists := requests[file]; exists {
-			continue
-		}
+const (
+	JsonAssetType = assets.JsonAssetType
+	YamlAssetType = assets.YamlAssetType
+	YmlAssetType  = assets.YmlAssetType
+)
 
-		fileType := file.Type()
+var (
+	ErrAssetDataFormatUnsupported = assets.ErrAssetDataFormatUnsupported
+	ErrAssetDataTrailing          = assets.ErrAssetDataTrailing
+)
 
-		if err := fileType.IsValid(); err != nil {
-			errs = append(errs, err)
-			continue
-		}
-
-		if _, exists := assetManagers[fileType]; !exists {
-			errs = append(errs, fmt.Errorf("%s: %s", ErrAssetManagerNotFound, fileType))
-			continue
-		}
-
-		requests.Add(file)
-	}
-
-	return errors.Join(errs...)
-}
-
-func loadAssetBatches(batches [][]AssetFile) error {
-	if len(batches) == 1 {
-		return loadAssetBatch(batches[0])
-	}
-
-	panicCh := make(chan error, len(batches))
-	wg := sync.WaitGroup{}
-
-	wg.Add(len(batches))
-	for _, batch := range batches {
-		go func(files []AssetFile) {
-			defer wg.Done()
-
-			defer func() {
-				if r := recover(); r != nil {
-					panicCh <- fmt.Errorf("panic in asset load batch: %v", r)
-				}
-			}()
-
-			if err := loadAssetBatch(files); err != nil {
-				panicCh <- err
-			}
-		}(batch)
-	}
-	wg.Wait()
-
-	close(panicCh)
-
-	errs := make([]error, 0, len(panicCh))
-	for err := range panicCh {
-		errs = append(errs, err)
-	}
-
-	if len(errs) > 0 {
-		return errors.Join(errs...)
-	}
-
-	return nil
-}
-
-func loadAssetBatch(files []AssetFile) error {
-	if len(files) == 0 {
-		return nil
-	}
-
-	errs := make([]error, 0)
-
-	// Note: Errors don't interrupt loading subsequent assets.
-	// Instead all errors are returned to be handled upstream.
-	for _, file := range files {
-		if err := loadAssetFile(file); err != nil {
-			errs = append(errs, err)
-		}
-	}
-
-	return errors.Join(errs...)
-}
-
-func loadAssetFile(file AssetFile) error {
-	if err := tryLoad(file); err != nil {
-		return err
-	}
-
-	defer func() {
-		assetsMu.Lock()
-		assetsLoading.Remove(file)
-		assetsMu.Unlock()
-	}()
-
-	var data []byte
-	var err error
+// RegisterDataImporter registers an importer that decodes data files of the given asset types into values of type T.
+//
+// The format of each type is selected by its last part, which must be json, yaml or yml. If no types are
+// given, all three are registered. Multi-part types decode different files of the same format into different
+// values, as in "enemy.yaml" and "item.yaml". Decoding is strict: fields that do not exist in T, and content
+// after the first value or document, are reported as errors. Enum fields can be decoded by implementing the
+// json and yaml marshalers with the enum package helpers.
+//
+//	finch.MustRegisterDataImporter[EnemyStats]("enemy.yaml")
+//	stats := finch.MustGetAsset[EnemyStats]("assets/data/goblin.enemy.yaml")
+func RegisterDataImporter[T any](types ...AssetType) error {
+	return assets.RegisterDataImporter[T](types...)
+}
 
-	froot := file.Root()
-	fpath := file.Path()
-
-	filesystem, exists := assetFilesystems[froot]
-	if exists {
-		switch _, ok := filesystem.(embed.FS); {
-		case ok:
-			fpath = filepath.Join(froot.String(), fpath)
-		default:
-			fpath = strings.TrimPrefix(fpath, froot.String())
-			fpath = strings.TrimPrefix(fpath, string(filepath.Separator))
-		}
-		data, err = fs.ReadFile(filesystem, fpath)
-		if err != nil {
-			return err
-		}
-	} else {
-		data, err = os.ReadFile(fpath)
-		if err != nil {
-			return err
-		}
-	}
-
-	manager, exists := assetManagers[file.Type()]
-	if !exists {
-		return fmt.Errorf("%s: %s", ErrAssetManagerNotFound, file.Type())
-	}
-
-	if manager.ProcessAssetFile == nil {
-		return fmt.Errorf("%s: %s", ErrAssetManagerNil, file.Type())
-	}
-
-	asset, err := manager.ProcessAssetFile(file, data)
-	if err != nil {
-		return fmt.Errorf("failed to import asset %s: %w", file, err)
-	}
-
-	assetsMu.Lock()
-	assetCache[file] = asset
-	assetsMu.Unlock()
-
-	return nil
-}
-
-func tryLoad(file AssetFile) error {
-	assetsMu.Lock()
-	defer assetsMu.Unlock()
-
-	if _, exists := assetCache[file]; exists {
-		return fmt.Errorf("%w: %s", ErrAssetIsLoaded, file)
-	}
-	if assetsLoading.Contains(file) {
-		return fmt.Errorf("%w: %s", ErrAssetIsLoading, file)
-	}
-
-	assetsLoading.Add(file)
-
-	return nil
+func MustRegisterDataImporter[T any](types ...AssetType) {
+	assets.MustRegisterDataImporter[T](types...)
 }
@@ -0,0 +1,267 @@
+package finch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"path"
+	"reflect"
+	"slices"
+
+	"github.com/adm87/finch-core/geom"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	AtlasAssetType     = "atlas"
+	AtlasJsonAssetType = "atlas.json"
+)
+
+var (
+	ErrAtlasInvalid = errors.New("atlas is invalid")
+)
+
+// ======================================================
+// Atlas
+// ======================================================
+
+// AtlasFrame is a named sprite packed into an atlas.
+type AtlasFrame struct {
+	Name       string
+	Image      *ebiten.Image // Sub image of the packed sprite, rotated clockwise if Rotated is set
+	Rotated    bool          // Whether the sprite was packed rotated by 90 degrees clockwise
+	Trimmed    bool          // Whether transparent borders were trimmed from the sprite
+	Offset     image.Point   // Position of the trimmed sprite within its source image
+	SourceSize image.Point   // Size of the source image before trimming
+	Pivot      geom.Point64  // Pivot of the sprite, normalized to its source size
+}
+
+// Size returns the size of the trimmed sprite, unrotated.
+func (f *AtlasFrame) Size() image.Point {
+	size := f.Image.Bounds().Size()
+	if f.Rotated {
+		size.X, size.Y = size.Y, size.X
+	}
+	return size
+}
+
+// GeoM returns the transform that draws the frame image as its untrimmed, unrotated source image,
+// with the source's top left corner at the origin.
+func (f *AtlasFrame) GeoM() ebiten.GeoM {
+	geoM := ebiten.GeoM{}
+	if f.Rotated {
+		geoM.Rotate(-math.Pi / 2)
+		geoM.Translate(0, float64(f.Image.Bounds().Dx()))
+	}
+	geoM.Translate(float64(f.Offset.X), float64(f.Offset.Y))
+	return geoM
+}
+
+// PivotOffset returns the position of the pivot within the source image, in pixels.
+func (f *AtlasFrame) PivotOffset() geom.Point64 {
+	return geom.NewPoint64(f.Pivot.X*float64(f.SourceSize.X), f.Pivot.Y*float64(f.SourceSize.Y))
+}
+
+// Draw draws the frame with its source's top left corner at the origin of the options' transform.
+func (f *AtlasFrame) Draw(dst *ebiten.Image, options *ebiten.DrawImageOptions) {
+	if options == nil {
+		options = &ebiten.DrawImageOptions{}
+	}
+
+	frameOptions := *options
+	frameOptions.GeoM = f.GeoM()
+	frameOptions.GeoM.Concat(options.GeoM)
+
+	dst.DrawImage(f.Image, &frameOptions)
+}
+
+// Atlas is a TexturePacker JSON atlas, in either the hash or array format.
+//
+// The atlas image is loaded as a dependency of the atlas file. Frames are sub images of it, and
+// can be fetched individually through GetAsset with the frame name as fragment:
+//
+//	hero := finch.MustGetAsset[*finch.AtlasFrame]("assets/sprites.atlas#hero_idle.png")
+type Atlas struct {
+	image  AssetFile
+	frames map[string]*AtlasFrame
+}
+
+// Image returns the asset file of the atlas image.
+func (a *Atlas) Image() AssetFile {
+	return a.image
+}
+
+func (a *Atlas) Frame(name string) (*AtlasFrame, bool) {
+	frame, exists := a.frames[name]
+	return frame, exists
+}
+
+// Frames returns the names of the frames in the atlas, sorted.
+func (a *Atlas) Frames() []string {
+	names := make([]string, 0, len(a.frames))
+	for name := range a.frames {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (a *Atlas) AssetFragment(name string) (any, bool) {
+	return a.Frame(name)
+}
+
+// atlasFile is the TexturePacker JSON format.
+type atlasFile struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image string `json:"image"`
+	} `json:"meta"`
+}
+
+type atlasFileFrame struct {
+	Filename string `json:"filename"`
+	Frame    struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"frame"`
+	Rotated          bool `json:"rotated"`
+	Trimmed          bool `json:"trimmed"`
+	SpriteSourceSize struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	} `json:"spriteSourceSize"`
+	SourceSize struct {
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"sourceSize"`
+	Pivot *struct {
+		X float64 `json:"x"`
+		Y float64 `json:"y"`
+	} `json:"pivot"`
+}
+
+// RegisterAtlasAssetImport registers the importer of TexturePacker atlases.
+//
+// The image importer must be registered too, since the atlas image is loaded through it.
+func RegisterAtlasAssetImport() {
+	RegisterAssetImporter(&AssetImporter{
+		AssetTypes: []AssetType{
+			AtlasAssetType,
+			AtlasJsonAssetType,
+		},
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			return parseAtlas(file, data)
+		},
+		OutputType: reflect.TypeFor[*Atlas](),
+	})
+}
+
+func GetAtlas(file AssetFile) (*Atlas, error) {
+	atlas, err := GetAsset[*Atlas](file)
+	if err != nil {
+		return nil, err
+	}
+	return atlas, nil
+}
+
+func MustGetAtlas(file AssetFile) *Atlas {
+	return MustGetAsset[*Atlas](file)
+}
+
+// GetAtlasFrame returns a frame of a loaded atlas.
+func GetAtlasFrame(file AssetFile, name string) (*AtlasFrame, error) {
+	return GetAsset[*AtlasFrame](AssetFile(file.Base().Path() + "#" + name))
+}
+
+func MustGetAtlasFrame(file AssetFile, name string) *AtlasFrame {
+	frame, err := GetAtlasFrame(file, name)
+	if err != nil {
+		panic(err)
+	}
+	return frame
+}
+
+func parseAtlas(file AssetFile, data []byte) (*Atlas, error) {
+	contents := atlasFile{}
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return nil, err
+	}
+
+	if contents.Meta.Image == "" {
+		return nil, fmt.Errorf("%w: meta.image is empty", ErrAtlasInvalid)
+	}
+
+	frames, err := parseAtlasFrames(contents.Frames)
+	if err != nil {
+		return nil, err
+	}
+
+	imageFile := AssetFile(path.Join(path.Dir(file.Path()), contents.Meta.Image))
+	if err := LoadAssetDependencies(file, imageFile); err != nil {
+		return nil, err
+	}
+
+	img, err := GetImage(imageFile)
+	if err != nil {
+		return nil, err
+	}
+
+	atlas := &Atlas{
+		image:  imageFile,
+		frames: make(map[string]*AtlasFrame, len(frames)),
+	}
+
+	for _, frame := range frames {
+		w, h := frame.Frame.W, frame.Frame.H
+		if frame.Rotated {
+			w, h = h, w
+		}
+
+		bounds := image.Rect(frame.Frame.X, frame.Frame.Y, frame.Frame.X+w, frame.Frame.Y+h)
+		if !bounds.In(img.Bounds()) {
+			return nil, fmt.Errorf("%w: frame %s is outside of the image", ErrAtlasInvalid, frame.Filename)
+		}
+
+		pivot := geom.NewPoint64(0.5, 0.5)
+		if frame.Pivot != nil {
+			pivot = geom.NewPoint64(frame.Pivot.X, frame.Pivot.Y)
+		}
+
+		atlas.frames[frame.Filename] = &AtlasFrame{
+			Name:       frame.Filename,
+			Image:      img.SubImage(bounds).(*ebiten.Image),
+			Rotated:    frame.Rotated,
+			Trimmed:    frame.Trimmed,
+			Offset:     image.Pt(frame.SpriteSourceSize.X, frame.SpriteSourceSize.Y),
+			SourceSize: image.Pt(frame.SourceSize.W, frame.SourceSize.H),
+			Pivot:      pivot,
+		}
+	}
+
+	return atlas, nil
+}
+
+// parseAtlasFrames decodes the frames of a TexturePacker atlas, which are either a map keyed by
+// frame name or an array of frames with a filename.
+func parseAtlasFrames(data json.RawMessage) ([]atlasFileFrame, error) {
+	frames := make([]atlasFileFrame, 0)
+	if err := json.Unmarshal(data, &frames); err == nil {
+		return frames, nil
+	}
+
+	byName := make(map[string]atlasFileFrame)
+	if err := json.Unmarshal(data, &byName); err != nil {
+		return nil, fmt.Errorf("%w: frames: %w", ErrAtlasInvalid, err)
+	}
+
+	for name, frame := range byName {
+		frame.Filename = name
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
@@ -0,0 +1,173 @@
+package finch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+const (
+	WavAssetType = "wav"
+	OggAssetType = "ogg"
+	Mp3AssetType = "mp3"
+
+	WavStreamAssetType = "stream.wav"
+	OggStreamAssetType = "stream.ogg"
+	Mp3StreamAssetType = "stream.mp3"
+)
+
+// audioStreamPrefix prefixes the stream types of the audio formats.
+const audioStreamPrefix = "stream."
+
+// AudioSampleRate is the sample rate audio files are decoded to, and the sample rate of the audio service.
+const AudioSampleRate = 44100
+
+// AudioClip is a decoded audio file, stored as 16-bit signed little endian stereo PCM at AudioSampleRate.
+type AudioClip struct {
+	data []byte
+}
+
+// Bytes returns the decoded PCM data of the clip.
+func (c *AudioClip) Bytes() []byte {
+	return c.data
+}
+
+func (c *AudioClip) Len() int64 {
+	return int64(len(c.data))
+}
+
+func RegisterAudioAssetImport() {
+	RegisterAssetImporter(&AssetImporter{
+		AssetTypes: []AssetType{
+			WavAssetType,
+			OggAssetType,
+			Mp3AssetType,
+		},
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			stream, err := decodeAudioFile(file.Type(), bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			pcm, err := io.ReadAll(stream)
+			if err != nil {
+				return nil, err
+			}
+			return &AudioClip{data: pcm}, nil
+		},
+		CleanupAssetFile: func(file AssetFile, data any) error {
+			clip, ok := data.(*AudioClip)
+			if !ok {
+				return fmt.Errorf("%w: expected *AudioClip, got %T", ErrAssetTypeMismatch, data)
+			}
+			stopAudioFile(file)
+			clip.data = nil
+			return nil
+		},
+		EstimateAssetSize: func(file AssetFile, data any) int64 {
+			clip, ok := data.(*AudioClip)
+			if !ok {
+				return 0
+			}
+			return clip.Len()
+		},
+		OutputType: reflect.TypeFor[*AudioClip](),
+	})
+}
+
+// AudioStream is an audio file that is decoded as it plays instead of up front, for long music tracks.
+// Its file stays open while it is loaded.
+//
+// The instances of a stream share its file, so only one plays at a time: playing a stream again
+// restarts it.
+type AudioStream struct {
+	stream AssetStream
+	format AssetType
+}
+
+// decode returns a decoder of the stream from its start.
+func (s *AudioStream) decode() (audioDecoder, error) {
+	if _, err := s.stream.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return decodeAudioFile(s.format, s.stream)
+}
+
+// RegisterAudioStreamAssetImport registers an importer that streams audio files named with a stream
+// type, as in "theme.stream.ogg", into *AudioStream values.
+func RegisterAudioStreamAssetImport() {
+	RegisterAssetImporter(&AssetImporter{
+		AssetTypes: []AssetType{
+			WavStreamAssetType,
+			OggStreamAssetType,
+			Mp3StreamAssetType,
+		},
+		ProcessAssetStream: func(file AssetFile, stream AssetStream) (any, error) {
+			s := &AudioStream{
+				stream: stream,
+				format: AssetType(strings.TrimPrefix(file.Type().String(), audioStreamPrefix)),
+			}
+			// Decode the header once, so unsupported or corrupted files fail to load instead of to play.
+			if _, err := s.decode(); err != nil {
+				return nil, err
+			}
+			return s, nil
+		},
+		CleanupAssetFile: func(file AssetFile, data any) error {
+			if _, ok := data.(*AudioStream); !ok {
+				return fmt.Errorf("%w: expected *AudioStream, got %T", ErrAssetTypeMismatch, data)
+			}
+			stopAudioFile(file)
+			return nil
+		},
+		OutputType: reflect.TypeFor[*AudioStream](),
+	})
+}
+
+func GetAudioStream(file AssetFile) (*AudioStream, error) {
+	return GetAsset[*AudioStream](file)
+}
+
+func MustGetAudioStream(file AssetFile) *AudioStream {
+	return MustGetAsset[*AudioStream](file)
+}
+
+func isAudioStreamType(t AssetType) bool {
+	return strings.HasPrefix(t.String(), audioStreamPrefix)
+}
+
+func GetAudioClip(file AssetFile) (*AudioClip, error) {
+	clip, err := GetAsset[*AudioClip](file)
+	if err != nil {
+		return nil, err
+	}
+	return clip, nil
+}
+
+func MustGetAudioClip(file AssetFile) *AudioClip {
+	return MustGetAsset[*AudioClip](file)
+}
+
+// audioDecoder is a decoded audio file, as returned by the ebiten decoders.
+type audioDecoder interface {
+	io.ReadSeeker
+	Length() int64
+}
+
+func decodeAudioFile(t AssetType, r io.ReadSeeker) (audioDecoder, error) {
+	switch t {
+	case WavAssetType:
+		return wav.DecodeWithSampleRate(AudioSampleRate, r)
+	case OggAssetType:
+		return vorbis.DecodeWithSampleRate(AudioSampleRate, r)
+	case Mp3AssetType:
+		return mp3.DecodeWithSampleRate(AudioSampleRate, r)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrAssetInvalidType, t)
+	}
+}
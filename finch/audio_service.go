@@ -0,0 +1,600 @@
+package finch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+var (
+	ErrAudioBusInvalid           = errors.New("audio bus is invalid")
+	ErrAudioInstanceLimitInvalid = errors.New("audio instance limit is invalid")
+)
+
+// DefaultAudioInstanceLimit is the number of instances of a sound that may play at once, unless limited otherwise.
+const DefaultAudioInstanceLimit = 8
+
+// ======================================================
+// Audio Bus
+// ======================================================
+
+// AudioBus groups sounds that share a volume. The master bus applies to every sound.
+type AudioBus int
+
+const (
+	MasterBus AudioBus = iota
+	MusicBus
+	SfxBus
+)
+
+func (b AudioBus) String() string {
+	switch b {
+	case MasterBus:
+		return "master"
+	case MusicBus:
+		return "music"
+	case SfxBus:
+		return "sfx"
+	default:
+		return "unknown"
+	}
+}
+
+func (b AudioBus) IsValid() bool {
+	return b >= MasterBus && b <= SfxBus
+}
+
+// ======================================================
+// Audio
+// ======================================================
+
+// Audio plays loaded audio clips on volume controlled buses.
+//
+// The service is created on first use through Context.Audio and is updated by the app every frame,
+// which advances fades and releases finished sounds.
+//
+// A playing sound retains its clip, so the clip is not evicted from the asset cache while it plays.
+type Audio struct {
+	context *audio.Context
+	volumes map[AudioBus]float64
+	limits  map[AssetFile]int
+	sounds  []*Sound
+	mu      sync.Mutex
+
+	// released holds the files of closed sounds whose retain has not been released yet. Releasing
+	// takes the assets lock, so it happens once the audio lock is no longer held.
+	released []AssetFile
+}
+
+var (
+	audioService   *Audio
+	audioServiceMu = sync.Mutex{}
+)
+
+// getAudio returns the audio service, creating it if it does not exist yet.
+func getAudio() *Audio {
+	audioServiceMu.Lock()
+	defer audioServiceMu.Unlock()
+
+	if audioService == nil {
+		ctx := audio.CurrentContext()
+		if ctx == nil {
+			ctx = audio.NewContext(AudioSampleRate)
+		}
+		audioService = &Audio{
+			context: ctx,
+			volumes: map[AudioBus]float64{
+				MasterBus: 1,
+				MusicBus:  1,
+				SfxBus:    1,
+			},
+			limits: make(map[AssetFile]int),
+		}
+	}
+
+	return audioService
+}
+
+// updateAudio advances the audio service, if it has been created.
+func updateAudio(delta time.Duration) {
+	audioServiceMu.Lock()
+	a := audioService
+	audioServiceMu.Unlock()
+
+	if a != nil {
+		a.update(delta)
+	}
+}
+
+// stopAudioFile stops every sound playing an audio file, if the audio service has been created.
+//
+// It is called by the cleanup of the audio importers, which runs while the assets lock is held, so the
+// sounds' retains are released by the next update instead.
+func stopAudioFile(file AssetFile) {
+	audioServiceMu.Lock()
+	a := audioService
+	audioServiceMu.Unlock()
+
+	if a != nil {
+		a.stopWhere(func(s *Sound) bool {
+			return s.file == file
+		})
+	}
+}
+
+// PlayOptions configures how a sound starts playing.
+type PlayOptions struct {
+	// Loop repeats the sound until it is stopped.
+	Loop bool
+	// FadeIn fades the sound in from silence over the duration. The sound starts silent, so fading
+	// in does not pop the way FadeIn called on a playing sound can.
+	FadeIn time.Duration
+}
+
+// Play plays a loaded audio clip once on a bus.
+//
+// If the clip is already playing as many times as its instance limit allows, its oldest instance is stopped.
+func (a *Audio) Play(file AssetFile, bus AudioBus) (*Sound, error) {
+	return a.play(file, bus, PlayOptions{})
+}
+
+// PlayLoop plays a loaded audio clip on a bus, repeating until it is stopped.
+func (a *Audio) PlayLoop(file AssetFile, bus AudioBus) (*Sound, error) {
+	return a.play(file, bus, PlayOptions{Loop: true})
+}
+
+// PlayWithOptions plays a loaded audio clip on a bus as configured by the options.
+func (a *Audio) PlayWithOptions(file AssetFile, bus AudioBus, options PlayOptions) (*Sound, error) {
+	return a.play(file, bus, options)
+}
+
+func (a *Audio) MustPlay(file AssetFile, bus AudioBus) *Sound {
+	sound, err := a.Play(file, bus)
+	if err != nil {
+		panic(err)
+	}
+	return sound
+}
+
+func (a *Audio) MustPlayLoop(file AssetFile, bus AudioBus) *Sound {
+	sound, err := a.PlayLoop(file, bus)
+	if err != nil {
+		panic(err)
+	}
+	return sound
+}
+
+func (a *Audio) MustPlayWithOptions(file AssetFile, bus AudioBus, options PlayOptions) *Sound {
+	sound, err := a.PlayWithOptions(file, bus, options)
+	if err != nil {
+		panic(err)
+	}
+	return sound
+}
+
+// SetVolume sets the volume of a bus, clamped to [0, 1].
+func (a *Audio) SetVolume(bus AudioBus, volume float64) error {
+	if !bus.IsValid() {
+		return fmt.Errorf("%w: %d", ErrAudioBusInvalid, bus)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.volumes[bus] = clampVolume(volume)
+	for _, sound := range a.sounds {
+		sound.apply(a.volumes)
+	}
+
+	return nil
+}
+
+func (a *Audio) Volume(bus AudioBus) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.volumes[bus]
+}
+
+// SetInstanceLimit sets the number of instances of an audio clip that may play at once.
+func (a *Audio) SetInstanceLimit(file AssetFile, limit int) error {
+	if limit < 1 {
+		return fmt.Errorf("%w: %s: %d", ErrAudioInstanceLimitInvalid, file, limit)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.limits[file] = limit
+
+	return nil
+}
+
+func (a *Audio) InstanceLimit(file AssetFile) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.instanceLimit(file)
+}
+
+// Sounds returns the number of sounds that are currently playing or paused.
+func (a *Audio) Sounds() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return len(a.sounds)
+}
+
+// StopFile stops every sound playing an audio clip.
+func (a *Audio) StopFile(file AssetFile) {
+	a.stopWhere(func(s *Sound) bool {
+		return s.file == file
+	})
+	a.releaseClips()
+}
+
+// StopBus stops every sound playing on a bus. Stopping the master bus stops every sound.
+func (a *Audio) StopBus(bus AudioBus) {
+	a.stopWhere(func(s *Sound) bool {
+		return bus == MasterBus || s.bus == bus
+	})
+	a.releaseClips()
+}
+
+func (a *Audio) StopAll() {
+	a.StopBus(MasterBus)
+}
+
+func (a *Audio) play(file AssetFile, bus AudioBus, options PlayOptions) (*Sound, error) {
+	if !bus.IsValid() {
+		return nil, fmt.Errorf("%w: %d", ErrAudioBusInvalid, bus)
+	}
+
+	player, err := a.newPlayer(file, options.Loop)
+	if err != nil {
+		return nil, err
+	}
+
+	// The clip is retained until the sound is closed, so it is not evicted while it plays.
+	if err := RetainAsset(file); err != nil {
+		player.Close()
+		return nil, err
+	}
+
+	sound := &Sound{
+		audio:  a,
+		file:   file,
+		bus:    bus,
+		loop:   options.Loop,
+		player: player,
+		volume: 1,
+		fade:   1,
+	}
+	if options.FadeIn > 0 {
+		sound.fadeBetween(0, 1, options.FadeIn, false)
+	}
+
+	// The starting volume is applied before the player starts, so the first samples are not played
+	// at full volume.
+	a.mu.Lock()
+	a.limitInstances(file)
+	a.sounds = append(a.sounds, sound)
+	sound.apply(a.volumes)
+	player.Play()
+	a.mu.Unlock()
+
+	a.releaseClips()
+
+	return sound, nil
+}
+
+// newPlayer creates a player of a loaded audio clip, or of a loaded audio stream.
+func (a *Audio) newPlayer(file AssetFile, loop bool) (*audio.Player, error) {
+	if isAudioStreamType(file.Type()) {
+		stream, err := GetAudioStream(file)
+		if err != nil {
+			return nil, err
+		}
+
+		// Instances of a stream share its file, so the playing instance is stopped before it is rewound.
+		a.StopFile(file)
+
+		decoder, err := stream.decode()
+		if err != nil {
+			return nil, err
+		}
+
+		if loop {
+			return a.context.NewPlayer(audio.NewInfiniteLoop(decoder, decoder.Length()))
+		}
+		return a.context.NewPlayer(decoder)
+	}
+
+	clip, err := GetAudioClip(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if loop {
+		return a.context.NewPlayer(audio.NewInfiniteLoop(bytes.NewReader(clip.data), clip.Len()))
+	}
+	return a.context.NewPlayerFromBytes(clip.data), nil
+}
+
+// limitInstances stops the oldest instances of an audio clip until another instance fits its limit.
+//
+// The caller must hold the audio lock.
+func (a *Audio) limitInstances(file AssetFile) {
+	limit := a.instanceLimit(file)
+
+	count := 0
+	for _, sound := range a.sounds {
+		if sound.file == file {
+			count++
+		}
+	}
+
+	for i := 0; i < len(a.sounds) && count >= limit; {
+		if sound := a.sounds[i]; sound.file == file {
+			a.closeSound(sound)
+			a.sounds = slices.Delete(a.sounds, i, i+1)
+			count--
+			continue
+		}
+		i++
+	}
+}
+
+func (a *Audio) instanceLimit(file AssetFile) int {
+	if limit, exists := a.limits[file]; exists {
+		return limit
+	}
+	return DefaultAudioInstanceLimit
+}
+
+func (a *Audio) stopWhere(match func(s *Sound) bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.sounds = slices.DeleteFunc(a.sounds, func(s *Sound) bool {
+		if match(s) {
+			a.closeSound(s)
+			return true
+		}
+		return false
+	})
+}
+
+func (a *Audio) update(delta time.Duration) {
+	a.mu.Lock()
+	a.sounds = slices.DeleteFunc(a.sounds, func(s *Sound) bool {
+		s.advance(delta)
+		if s.finished() {
+			a.closeSound(s)
+			return true
+		}
+		s.apply(a.volumes)
+		return false
+	})
+	a.mu.Unlock()
+
+	a.releaseClips()
+}
+
+// closeSound closes a sound and queues the release of its clip.
+//
+// The caller must hold the audio lock.
+func (a *Audio) closeSound(s *Sound) {
+	s.close()
+	a.released = append(a.released, s.file)
+}
+
+// releaseClips releases the clips of closed sounds. The audio lock must not be held.
+//
+// Clips that were unloaded while their sounds played are no longer retained, so their errors are ignored.
+func (a *Audio) releaseClips() {
+	a.mu.Lock()
+	released := a.released
+	a.released = nil
+	a.mu.Unlock()
+
+	for _, file := range released {
+		ReleaseAsset(file)
+	}
+}
+
+// ======================================================
+// Sound
+// ======================================================
+
+// Sound is a playing instance of an audio clip.
+//
+// Sounds are owned by the audio service, which releases them once they finish or are stopped.
+// Their methods may be called from any goroutine.
+type Sound struct {
+	audio  *Audio
+	file   AssetFile
+	bus    AudioBus
+	loop   bool
+	player *audio.Player
+
+	// The fields below are guarded by the audio lock.
+	volume float64
+	paused bool
+	done   bool
+
+	fade         float64
+	fadeFrom     float64
+	fadeTo       float64
+	fadeElapsed  time.Duration
+	fadeDuration time.Duration
+	fadeStop     bool
+}
+
+func (s *Sound) File() AssetFile {
+	return s.file
+}
+
+func (s *Sound) Bus() AudioBus {
+	return s.bus
+}
+
+func (s *Sound) IsLooping() bool {
+	return s.loop
+}
+
+func (s *Sound) IsPlaying() bool {
+	s.audio.mu.Lock()
+	defer s.audio.mu.Unlock()
+
+	return !s.done && s.player.IsPlaying()
+}
+
+func (s *Sound) IsPaused() bool {
+	s.audio.mu.Lock()
+	defer s.audio.mu.Unlock()
+
+	return s.paused
+}
+
+func (s *Sound) Volume() float64 {
+	s.audio.mu.Lock()
+	defer s.audio.mu.Unlock()
+
+	return s.volume
+}
+
+// SetVolume sets the volume of the sound, clamped to [0, 1]. It is scaled by the volume of its bus
+// and the master bus.
+func (s *Sound) SetVolume(volume float64) {
+	s.audio.mu.Lock()
+	defer s.audio.mu.Unlock()
+
+	s.volume = clampVolume(volume)
+	if !s.done {
+		s.apply(s.audio.volumes)
+	}
+}
+
+func (s *Sound) Pause() {
+	s.audio.mu.Lock()
+	defer s.audio.mu.Unlock()
+
+	if s.done {
+		return
+	}
+	s.paused = true
+	s.player.Pause()
+}
+
+func (s *Sound) Resume() {
+	s.audio.mu.Lock()
+	defer s.audio.mu.Unlock()
+
+	if s.done {
+		return
+	}
+	s.paused = false
+	s.player.Play()
+}
+
+// Stop stops the sound. The audio service releases it on its next update.
+func (s *Sound) Stop() {
+	s.audio.mu.Lock()
+	defer s.audio.mu.Unlock()
+
+	if s.done {
+		return
+	}
+	s.done = true
+	s.player.Pause()
+}
+
+// FadeIn fades the sound from silence to its volume.
+//
+// The sound is silenced at once, but a sound that has already started may be heard briefly before
+// the call. Use PlayOptions.FadeIn to start a sound silent.
+func (s *Sound) FadeIn(duration time.Duration) {
+	s.fadeWith(func() { s.fadeBetween(0, 1, duration, false) })
+}
+
+// FadeOut fades the sound to silence and then stops it.
+func (s *Sound) FadeOut(duration time.Duration) {
+	s.fadeWith(func() { s.fadeBetween(s.fade, 0, duration, true) })
+}
+
+// FadeTo fades the sound to a fraction of its volume, clamped to [0, 1].
+func (s *Sound) FadeTo(volume float64, duration time.Duration) {
+	s.fadeWith(func() { s.fadeBetween(s.fade, clampVolume(volume), duration, false) })
+}
+
+// fadeWith starts a fade under the audio lock and applies its starting volume.
+func (s *Sound) fadeWith(start func()) {
+	s.audio.mu.Lock()
+	defer s.audio.mu.Unlock()
+
+	start()
+	if !s.done {
+		s.apply(s.audio.volumes)
+	} else {
+		s.player.Pause()
+	}
+}
+
+// fadeBetween sets the fade of the sound. The caller must hold the audio lock.
+func (s *Sound) fadeBetween(from, to float64, duration time.Duration, stop bool) {
+	s.fade = from
+	s.fadeFrom = from
+	s.fadeTo = to
+	s.fadeElapsed = 0
+	s.fadeDuration = max(duration, 0)
+	s.fadeStop = stop
+
+	if s.fadeDuration == 0 {
+		s.fade = to
+		s.done = s.done || stop
+	}
+}
+
+// advance steps the fade of the sound. The caller must hold the audio lock.
+func (s *Sound) advance(delta time.Duration) {
+	if s.paused || s.fadeDuration == 0 {
+		return
+	}
+
+	s.fadeElapsed = min(s.fadeElapsed+delta, s.fadeDuration)
+	t := float64(s.fadeElapsed) / float64(s.fadeDuration)
+	s.fade = s.fadeFrom + (s.fadeTo-s.fadeFrom)*t
+
+	if s.fadeElapsed == s.fadeDuration {
+		s.fadeDuration = 0
+		s.done = s.done || s.fadeStop
+	}
+}
+
+func (s *Sound) finished() bool {
+	return s.done || (!s.paused && !s.player.IsPlaying())
+}
+
+// apply sets the player volume from the sound, its fade and the bus volumes. The caller must hold the audio lock.
+func (s *Sound) apply(volumes map[AudioBus]float64) {
+	volume := s.volume * s.fade * volumes[MasterBus]
+	if s.bus != MasterBus {
+		volume *= volumes[s.bus]
+	}
+	s.player.SetVolume(volume)
+}
+
+func (s *Sound) close() {
+	s.done = true
+	s.player.Close()
+}
+
+func clampVolume(volume float64) float64 {
+	return min(max(volume, 0), 1)
+}
@@ -11,6 +11,8 @@ type Context interface {
 	Context() context.Context
 	Screen() *Screen
 	Time() *Time
+	Audio() *Audio
+	Localizer() *Localizer
 	Logger() *slog.Logger
 	SetLogger(logger *slog.Logger) Context
 	Get(key ContextKey) any
@@ -45,6 +47,16 @@ func (c *finchCtx) Time() *Time {
 	return c.time
 }
 
+// Audio returns the audio service, creating it on first use.
+func (c *finchCtx) Audio() *Audio {
+	return getAudio()
+}
+
+// Localizer returns the localizer, creating it on first use.
+func (c *finchCtx) Localizer() *Localizer {
+	return getLocalizer()
+}
+
 func (c *finchCtx) Logger() *slog.Logger {
 	return c.logger
 }
@@ -0,0 +1,226 @@
+package finch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adm87/finch-core/internal/assets"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+const (
+	TtfAssetType = "ttf"
+	OtfAssetType = "otf"
+
+	TtfSidecarAssetType = "ttf.yaml"
+	OtfSidecarAssetType = "otf.yaml"
+)
+
+// FontSidecarExtension is appended to the path of a font file to locate its sidecar file.
+//
+//	assets/fonts/body.ttf
+//	assets/fonts/body.ttf.yaml  ->  sizes: [12, 16, 24]
+const FontSidecarExtension = ".yaml"
+
+// DefaultFontSize is the size of the default face of a font that declares no sizes, in pixels.
+const DefaultFontSize = 16
+
+var (
+	ErrFontSizeInvalid = errors.New("font size is invalid")
+)
+
+// ======================================================
+// Font
+// ======================================================
+
+// Font is a loaded TrueType or OpenType font that produces text/v2 faces at any size.
+//
+// The sizes a font is meant to be used at are declared in its path, as in "body@12,16.ttf",
+// or in a sidecar file next to it. Faces are created on first use and cached per size.
+type Font struct {
+	source *text.GoTextFaceSource
+	sizes  []float64
+	faces  map[float64]*text.GoTextFace
+	mu     sync.Mutex
+}
+
+// fontSidecar is the contents of a font's sidecar file.
+type fontSidecar struct {
+	Sizes []float64 `json:"sizes" yaml:"sizes"`
+}
+
+// Source returns the face source of the font.
+func (f *Font) Source() *text.GoTextFaceSource {
+	return f.source
+}
+
+// Sizes returns the size variants declared for the font, smallest first.
+func (f *Font) Sizes() []float64 {
+	return slices.Clone(f.sizes)
+}
+
+// Face returns the face of the font at a size, in pixels.
+func (f *Font) Face(size float64) *text.GoTextFace {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if face, exists := f.faces[size]; exists {
+		return face
+	}
+
+	face := &text.GoTextFace{
+		Source: f.source,
+		Size:   size,
+	}
+	f.faces[size] = face
+
+	return face
+}
+
+// DefaultFace returns the face of the font at its smallest declared size, or at DefaultFontSize if it declares none.
+func (f *Font) DefaultFace() *text.GoTextFace {
+	if len(f.sizes) == 0 {
+		return f.Face(DefaultFontSize)
+	}
+	return f.Face(f.sizes[0])
+}
+
+// RegisterFontAssetImport registers the importers of fonts and of their sidecar files.
+//
+// A font loads its sidecar file as a dependency, so editing the sidecar hot reloads the font.
+func RegisterFontAssetImport() {
+	RegisterAssetImporter(&AssetImporter{
+		AssetTypes: []AssetType{
+			TtfSidecarAssetType,
+			OtfSidecarAssetType,
+		},
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			sidecar := &fontSidecar{}
+			if err := assets.DecodeAssetData(file.Type(), data, sidecar); err != nil {
+				return nil, err
+			}
+			for _, size := range sidecar.Sizes {
+				if size <= 0 {
+					return nil, fmt.Errorf("%w: %g", ErrFontSizeInvalid, size)
+				}
+			}
+			return sidecar, nil
+		},
+		OutputType: reflect.TypeFor[*fontSidecar](),
+	})
+
+	RegisterAssetImporter(&AssetImporter{
+		AssetTypes: []AssetType{
+			TtfAssetType,
+			OtfAssetType,
+		},
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			source, err := text.NewGoTextFaceSource(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+
+			sizes, err := fontSizes(file)
+			if err != nil {
+				return nil, err
+			}
+
+			return &Font{
+				source: source,
+				sizes:  sizes,
+				faces:  make(map[float64]*text.GoTextFace),
+			}, nil
+		},
+		OutputType: reflect.TypeFor[*Font](),
+	})
+}
+
+func GetFont(file AssetFile) (*Font, error) {
+	font, err := GetAsset[*Font](file)
+	if err != nil {
+		return nil, err
+	}
+	return font, nil
+}
+
+func MustGetFont(file AssetFile) *Font {
+	return MustGetAsset[*Font](file)
+}
+
+// GetFontFace returns the face of a loaded font at a size, in pixels.
+func GetFontFace(file AssetFile, size float64) (*text.GoTextFace, error) {
+	font, err := GetFont(file)
+	if err != nil {
+		return nil, err
+	}
+	return font.Face(size), nil
+}
+
+func MustGetFontFace(file AssetFile, size float64) *text.GoTextFace {
+	face, err := GetFontFace(file, size)
+	if err != nil {
+		panic(err)
+	}
+	return face
+}
+
+// fontSizes returns the size variants of a font file, read from its path and its sidecar file.
+func fontSizes(file AssetFile) ([]float64, error) {
+	sizes, err := pathFontSizes(file)
+	if err != nil {
+		return nil, err
+	}
+
+	sidecarFile := AssetFile(file.Path() + FontSidecarExtension)
+	exists, err := assets.AssetFileExists(sidecarFile)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if err := LoadAssetDependencies(file, sidecarFile); err != nil {
+			return nil, err
+		}
+		sidecar, err := GetAsset[*fontSidecar](sidecarFile)
+		if err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, sidecar.Sizes...)
+	}
+
+	slices.Sort(sizes)
+
+	return slices.Compact(sizes), nil
+}
+
+// pathFontSizes returns the sizes listed after the last @ of a font's name, as in title@32,48.ttf. A
+// suffix that is not a list of numbers, as in ui@2x.ttf, is part of the name and lists no sizes.
+func pathFontSizes(file AssetFile) ([]float64, error) {
+	name := strings.TrimSuffix(path.Base(file.Path()), path.Ext(file.Path()))
+	i := strings.LastIndex(name, "@")
+	if i < 0 {
+		return make([]float64, 0), nil
+	}
+
+	fields := strings.Split(name[i+1:], ",")
+	sizes := make([]float64, 0, len(fields))
+	for _, field := range fields {
+		size, err := strconv.ParseFloat(field, 64)
+		if err != nil || math.IsNaN(size) || math.IsInf(size, 0) {
+			return make([]float64, 0), nil
+		}
+		if size <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrFontSizeInvalid, field)
+		}
+		sizes = append(sizes, size)
+	}
+
+	return sizes, nil
+}
@@ -0,0 +1,266 @@
+package finch
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	FntAssetType = "fnt"
+)
+
+var (
+	ErrBitmapFontInvalid = errors.New("bitmap font is invalid")
+)
+
+// ======================================================
+// Bitmap Font
+// ======================================================
+
+// BitmapGlyph is a character of a bitmap font.
+type BitmapGlyph struct {
+	Image    *ebiten.Image // Sub image of the glyph within its page
+	XOffset  int           // Horizontal offset from the pen position to the glyph image
+	YOffset  int           // Vertical offset from the top of the line to the glyph image
+	XAdvance int           // Horizontal distance the pen moves after the glyph
+}
+
+// BitmapFont is a font loaded from a BMFont text descriptor and its page images.
+//
+// Page images are loaded relative to the descriptor as dependencies of the font, so editing a page
+// hot reloads the font, and the pages are unloaded with it.
+type BitmapFont struct {
+	lineHeight int
+	base       int
+	pages      []*ebiten.Image
+	glyphs     map[rune]*BitmapGlyph
+	kernings   map[[2]rune]int
+}
+
+func (f *BitmapFont) LineHeight() int {
+	return f.lineHeight
+}
+
+// Base returns the distance from the top of a line to the baseline of its glyphs.
+func (f *BitmapFont) Base() int {
+	return f.base
+}
+
+func (f *BitmapFont) Glyph(r rune) (*BitmapGlyph, bool) {
+	glyph, exists := f.glyphs[r]
+	return glyph, exists
+}
+
+// Kerning returns the horizontal adjustment between two consecutive characters.
+func (f *BitmapFont) Kerning(first, second rune) int {
+	return f.kernings[[2]rune{first, second}]
+}
+
+// Measure returns the size of a string drawn with the font, in pixels.
+func (f *BitmapFont) Measure(str string) (width, height int) {
+	lines := strings.Split(str, "\n")
+
+	for _, line := range lines {
+		x := 0
+		prev := rune(-1)
+		for _, r := range line {
+			glyph, exists := f.glyphs[r]
+			if !exists {
+				continue
+			}
+			x += f.Kerning(prev, r) + glyph.XAdvance
+			prev = r
+		}
+		width = max(width, x)
+	}
+
+	return width, len(lines) * f.lineHeight
+}
+
+// Draw draws a string with the font. The options' transform positions the top left corner of the first line.
+//
+// Characters that are not in the font are skipped.
+func (f *BitmapFont) Draw(dst *ebiten.Image, str string, options *ebiten.DrawImageOptions) {
+	if options == nil {
+		options = &ebiten.DrawImageOptions{}
+	}
+
+	glyphOptions := *options
+
+	for i, line := range strings.Split(str, "\n") {
+		x := 0
+		y := i * f.lineHeight
+		prev := rune(-1)
+
+		for _, r := range line {
+			glyph, exists := f.glyphs[r]
+			if !exists {
+				continue
+			}
+
+			x += f.Kerning(prev, r)
+			prev = r
+
+			glyphOptions.GeoM.Reset()
+			glyphOptions.GeoM.Translate(float64(x+glyph.XOffset), float64(y+glyph.YOffset))
+			glyphOptions.GeoM.Concat(options.GeoM)
+			dst.DrawImage(glyph.Image, &glyphOptions)
+
+			x += glyph.XAdvance
+		}
+	}
+}
+
+// RegisterBitmapFontAssetImport registers the importer of BMFont text descriptors.
+//
+// The image importer must be registered too, since the page images are loaded through it.
+func RegisterBitmapFontAssetImport() {
+	RegisterAssetImporter(&AssetImporter{
+		AssetTypes: []AssetType{
+			FntAssetType,
+		},
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			return parseBitmapFont(file, data)
+		},
+		OutputType: reflect.TypeFor[*BitmapFont](),
+	})
+}
+
+func GetBitmapFont(file AssetFile) (*BitmapFont, error) {
+	font, err := GetAsset[*BitmapFont](file)
+	if err != nil {
+		return nil, err
+	}
+	return font, nil
+}
+
+func MustGetBitmapFont(file AssetFile) *BitmapFont {
+	return MustGetAsset[*BitmapFont](file)
+}
+
+// parseBitmapFont parses a BMFont text descriptor and loads its page images.
+func parseBitmapFont(file AssetFile, data []byte) (*BitmapFont, error) {
+	font := &BitmapFont{
+		glyphs:   make(map[rune]*BitmapGlyph),
+		kernings: make(map[[2]rune]int),
+	}
+
+	pages := make(map[int]string)
+	chars := make([]map[string]string, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		tag, attrs, err := parseBitmapFontLine(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrBitmapFontInvalid, line, err)
+		}
+
+		switch tag {
+		case "common":
+			font.lineHeight, _ = strconv.Atoi(attrs["lineHeight"])
+			font.base, _ = strconv.Atoi(attrs["base"])
+		case "page":
+			id, err := strconv.Atoi(attrs["id"])
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: page id: %w", ErrBitmapFontInvalid, line, err)
+			}
+			pages[id] = attrs["file"]
+		case "char":
+			chars = append(chars, attrs)
+		case "kerning":
+			first, _ := strconv.Atoi(attrs["first"])
+			second, _ := strconv.Atoi(attrs["second"])
+			amount, _ := strconv.Atoi(attrs["amount"])
+			font.kernings[[2]rune{rune(first), rune(second)}] = amount
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// The pages are loaded as dependencies, which are released again if the font fails to load.
+	pageFiles := make([]AssetFile, len(pages))
+	for id, name := range pages {
+		if id < 0 || id >= len(pages) || name == "" {
+			return nil, fmt.Errorf("%w: page %d", ErrBitmapFontInvalid, id)
+		}
+		pageFiles[id] = AssetFile(path.Join(path.Dir(file.Path()), name))
+	}
+
+	if err := LoadAssetDependencies(file, pageFiles...); err != nil {
+		return nil, err
+	}
+
+	font.pages = make([]*ebiten.Image, len(pageFiles))
+	for id, pageFile := range pageFiles {
+		page, err := GetImage(pageFile)
+		if err != nil {
+			return nil, err
+		}
+		font.pages[id] = page
+	}
+
+	for _, attrs := range chars {
+		values := make(map[string]int, len(attrs))
+		for _, key := range []string{"id", "x", "y", "width", "height", "xoffset", "yoffset", "xadvance", "page"} {
+			value, err := strconv.Atoi(attrs[key])
+			if err != nil && key != "page" {
+				return nil, fmt.Errorf("%w: char %s: %s: %w", ErrBitmapFontInvalid, attrs["id"], key, err)
+			}
+			values[key] = value
+		}
+
+		page := values["page"]
+		if page < 0 || page >= len(font.pages) {
+			return nil, fmt.Errorf("%w: char %d: page %d", ErrBitmapFontInvalid, values["id"], page)
+		}
+
+		x, y := values["x"], values["y"]
+		font.glyphs[rune(values["id"])] = &BitmapGlyph{
+			Image:    font.pages[page].SubImage(image.Rect(x, y, x+values["width"], y+values["height"])).(*ebiten.Image),
+			XOffset:  values["xoffset"],
+			YOffset:  values["yoffset"],
+			XAdvance: values["xadvance"],
+		}
+	}
+
+	return font, nil
+}
+
+// parseBitmapFontLine splits a BMFont descriptor line into its tag and key=value attributes.
+func parseBitmapFontLine(line string) (string, map[string]string, error) {
+	line = strings.TrimSpace(line)
+	tag, rest, _ := strings.Cut(line, " ")
+	attrs := make(map[string]string)
+
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		key, value, found := strings.Cut(rest, "=")
+		if !found {
+			return "", nil, fmt.Errorf("attribute without value: %s", rest)
+		}
+
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				return "", nil, fmt.Errorf("unterminated value: %s", key)
+			}
+			attrs[key] = value[1 : end+1]
+			rest = value[end+2:]
+			continue
+		}
+
+		value, rest, _ = strings.Cut(value, " ")
+		attrs[key] = value
+	}
+
+	return tag, attrs, nil
+}
@@ -0,0 +1,38 @@
+package finch
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPathFontSizes(t *testing.T) {
+	tests := []struct {
+		file AssetFile
+		want []float64
+		err  error
+	}{
+		{file: "assets/fonts/body.ttf", want: []float64{}},
+		{file: "assets/fonts/title@32.ttf", want: []float64{32}},
+		{file: "assets/fonts/title@32,12.5.otf", want: []float64{32, 12.5}},
+		{file: "assets/fonts/ui@2x.ttf", want: []float64{}},
+		{file: "assets/fonts/ui@2x@16.ttf", want: []float64{16}},
+		{file: "assets/fonts/me@home.ttf", want: []float64{}},
+		{file: "assets/fonts/title@32,big.ttf", want: []float64{}},
+		{file: "assets/fonts/title@inf.ttf", want: []float64{}},
+		{file: "assets/fonts/title@0.ttf", err: ErrFontSizeInvalid},
+		{file: "assets/fonts/title@12,-4.ttf", err: ErrFontSizeInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file.Path(), func(t *testing.T) {
+			got, err := pathFontSizes(tt.file)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("pathFontSizes() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && !slices.Equal(got, tt.want) {
+				t.Fatalf("pathFontSizes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package finch
 
 import (
 	"bytes"
-	"errors"
+	"fmt"
+	"image/color"
+	"reflect"
+	"sync"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
@@ -15,6 +18,31 @@ const (
 	BmpAssetType  = "bmp"
 )
 
+// MissingImageSize is the width and height, in pixels, of the placeholder returned for images that failed to load.
+const MissingImageSize = 16
+
+// missingImage is the magenta and black checkerboard returned for images that failed to load.
+var missingImage = sync.OnceValue(func() *ebiten.Image {
+	const cell = MissingImageSize / 2
+
+	img := ebiten.NewImage(MissingImageSize, MissingImageSize)
+	for y := range MissingImageSize {
+		for x := range MissingImageSize {
+			if (x/cell+y/cell)%2 == 0 {
+				img.Set(x, y, color.RGBA{R: 255, B: 255, A: 255})
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	return img
+})
+
+// MissingImage returns the placeholder image used as the fallback of images that failed to load.
+func MissingImage() *ebiten.Image {
+	return missingImage()
+}
+
 func RegisterImageAssetImport() {
 	RegisterAssetImporter(&AssetImporter{
 		AssetTypes: []AssetType{
@@ -33,11 +61,23 @@ func RegisterImageAssetImport() {
 		CleanupAssetFile: func(file AssetFile, data any) error {
 			img, ok := data.(*ebiten.Image)
 			if !ok {
-				return errors.New("asset is not an *ebiten.Image")
+				return fmt.Errorf("%w: expected *ebiten.Image, got %T", ErrAssetTypeMismatch, data)
 			}
 			img.Deallocate()
 			return nil
 		},
+		FallbackAsset: func(file AssetFile) any {
+			return missingImage()
+		},
+		OutputType: reflect.TypeFor[*ebiten.Image](),
+		EstimateAssetSize: func(file AssetFile, data any) int64 {
+			img, ok := data.(*ebiten.Image)
+			if !ok {
+				return 0
+			}
+			bounds := img.Bounds()
+			return int64(bounds.Dx()) * int64(bounds.Dy()) * 4
+		},
 	})
 }
 
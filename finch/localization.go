@@ -0,0 +1,337 @@
+package finch
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/adm87/finch-core/internal/assets"
+)
+
+const (
+	StringsJsonAssetType = "strings.json"
+	StringsYamlAssetType = "strings.yaml"
+	StringsYmlAssetType  = "strings.yml"
+)
+
+// ======================================================
+// Plural Category
+// ======================================================
+
+// PluralCategory is a plural form of a localized string, as named by the Unicode CLDR plural rules.
+type PluralCategory int
+
+const (
+	PluralZero PluralCategory = iota
+	PluralOne
+	PluralTwo
+	PluralFew
+	PluralMany
+	PluralOther
+)
+
+func (c PluralCategory) String() string {
+	switch c {
+	case PluralZero:
+		return "zero"
+	case PluralOne:
+		return "one"
+	case PluralTwo:
+		return "two"
+	case PluralFew:
+		return "few"
+	case PluralMany:
+		return "many"
+	case PluralOther:
+		return "other"
+	default:
+		return "unknown"
+	}
+}
+
+func (c PluralCategory) IsValid() bool {
+	return c >= PluralZero && c <= PluralOther
+}
+
+func parsePluralCategory(s string) (PluralCategory, bool) {
+	for c := PluralZero; c <= PluralOther; c++ {
+		if c.String() == s {
+			return c, true
+		}
+	}
+	return PluralOther, false
+}
+
+// PluralRule selects the plural form of a localized string for a count.
+type PluralRule func(count int) PluralCategory
+
+var (
+	// PluralRuleOneOther is the rule of English, German, Spanish, Italian and most other western languages.
+	PluralRuleOneOther PluralRule = func(count int) PluralCategory {
+		if count == 1 {
+			return PluralOne
+		}
+		return PluralOther
+	}
+
+	// PluralRuleZeroOneOther is the rule of French and Brazilian Portuguese, where zero is singular.
+	PluralRuleZeroOneOther PluralRule = func(count int) PluralCategory {
+		if count == 0 || count == 1 {
+			return PluralOne
+		}
+		return PluralOther
+	}
+
+	// PluralRuleOther is the rule of languages without plural forms, such as Japanese, Korean and Chinese.
+	PluralRuleOther PluralRule = func(count int) PluralCategory {
+		return PluralOther
+	}
+
+	// PluralRuleSlavic is the rule of Russian and Ukrainian.
+	PluralRuleSlavic PluralRule = func(count int) PluralCategory {
+		mod10, mod100 := abs(count)%10, abs(count)%100
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return PluralOne
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return PluralFew
+		default:
+			return PluralMany
+		}
+	}
+
+	// PluralRulePolish is the rule of Polish.
+	PluralRulePolish PluralRule = func(count int) PluralCategory {
+		mod10, mod100 := abs(count)%10, abs(count)%100
+		switch {
+		case count == 1:
+			return PluralOne
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return PluralFew
+		default:
+			return PluralMany
+		}
+	}
+)
+
+// defaultPluralRules maps locales and base languages to their plural rule. Other languages use PluralRuleOneOther.
+var defaultPluralRules = map[string]PluralRule{
+	"fr":    PluralRuleZeroOneOther,
+	"pt-BR": PluralRuleZeroOneOther,
+	"ja":    PluralRuleOther,
+	"ko":    PluralRuleOther,
+	"zh":    PluralRuleOther,
+	"th":    PluralRuleOther,
+	"vi":    PluralRuleOther,
+	"id":    PluralRuleOther,
+	"ru":    PluralRuleSlavic,
+	"uk":    PluralRuleSlavic,
+	"be":    PluralRuleSlavic,
+	"pl":    PluralRulePolish,
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// ======================================================
+// String Table
+// ======================================================
+
+// StringTable is a loaded table of localized strings.
+//
+// Nested keys are joined with dots, and maps whose keys are all plural categories are plural strings:
+//
+//	menu:
+//	  play: Play
+//	  quit: Quit
+//	greeting: "Hello, {{.name}}!"
+//	apples:
+//	  one: "{{.count}} apple"
+//	  other: "{{.count}} apples"
+type StringTable struct {
+	strings map[string]string
+	plurals map[string]map[PluralCategory]string
+}
+
+// Lookup returns a string of the table. Plural strings return their "other" form.
+func (t *StringTable) Lookup(key string) (string, bool) {
+	if s, exists := t.strings[key]; exists {
+		return s, true
+	}
+	if forms, exists := t.plurals[key]; exists {
+		s, exists := forms[PluralOther]
+		return s, exists
+	}
+	return "", false
+}
+
+// LookupPlural returns the form of a plural string for a plural category, or its "other" form if the
+// category is missing. Strings without plural forms are returned as is.
+func (t *StringTable) LookupPlural(key string, category PluralCategory) (string, bool) {
+	forms, exists := t.plurals[key]
+	if !exists {
+		s, exists := t.strings[key]
+		return s, exists
+	}
+	if s, exists := forms[category]; exists {
+		return s, true
+	}
+	s, exists := forms[PluralOther]
+	return s, exists
+}
+
+// Keys returns the keys of the table, sorted.
+func (t *StringTable) Keys() []string {
+	keys := make([]string, 0, len(t.strings)+len(t.plurals))
+	for key := range t.strings {
+		keys = append(keys, key)
+	}
+	for key := range t.plurals {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (t *StringTable) Len() int {
+	return len(t.strings) + len(t.plurals)
+}
+
+// RegisterStringTableAssetImport registers the importer of string tables for the given asset types.
+//
+// The format of each type is selected by its last part, which must be json, yaml or yml. If no types are
+// given, strings.json, strings.yaml and strings.yml are registered, leaving the plain data types free
+// for RegisterDataImporter.
+func RegisterStringTableAssetImport(types ...AssetType) error {
+	if len(types) == 0 {
+		types = []AssetType{StringsJsonAssetType, StringsYamlAssetType, StringsYmlAssetType}
+	}
+
+	for _, t := range types {
+		if _, err := assets.AssetDataFormat(t); err != nil {
+			return &AssetError{Op: "register", Type: t, Err: err}
+		}
+	}
+
+	return RegisterAssetImporter(&AssetImporter{
+		AssetTypes: types,
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			return parseStringTable(file, data)
+		},
+		EstimateAssetSize: func(file AssetFile, data any) int64 {
+			table, ok := data.(*StringTable)
+			if !ok {
+				return 0
+			}
+			size := int64(0)
+			for key, s := range table.strings {
+				size += int64(len(key) + len(s))
+			}
+			for key, forms := range table.plurals {
+				size += int64(len(key))
+				for _, s := range forms {
+					size += int64(len(s))
+				}
+			}
+			return size
+		},
+		OutputType: reflect.TypeFor[*StringTable](),
+	})
+}
+
+func MustRegisterStringTableAssetImport(types ...AssetType) {
+	if err := RegisterStringTableAssetImport(types...); err != nil {
+		panic(err)
+	}
+}
+
+func GetStringTable(file AssetFile) (*StringTable, error) {
+	table, err := GetAsset[*StringTable](file)
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+func MustGetStringTable(file AssetFile) *StringTable {
+	return MustGetAsset[*StringTable](file)
+}
+
+func parseStringTable(file AssetFile, data []byte) (*StringTable, error) {
+	contents := make(map[string]any)
+	if err := assets.DecodeAssetData(file.Type(), data, &contents); err != nil {
+		return nil, err
+	}
+
+	table := &StringTable{
+		strings: make(map[string]string),
+		plurals: make(map[string]map[PluralCategory]string),
+	}
+	table.add("", contents)
+
+	return table, nil
+}
+
+func (t *StringTable) add(prefix string, values map[string]any) {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		children, isMap := stringTableMap(value)
+		if !isMap {
+			t.strings[key] = fmt.Sprint(value)
+			continue
+		}
+
+		if forms, isPlural := stringTablePlural(children); isPlural {
+			t.plurals[key] = forms
+			continue
+		}
+
+		t.add(key, children)
+	}
+}
+
+// stringTableMap returns a nested map of a decoded table, whose keys may have been decoded as any type.
+func stringTableMap(value any) (map[string]any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		return v, true
+	case map[any]any:
+		children := make(map[string]any, len(v))
+		for key, child := range v {
+			children[fmt.Sprint(key)] = child
+		}
+		return children, true
+	default:
+		return nil, false
+	}
+}
+
+// stringTablePlural returns the forms of a plural string, if every key of a nested map is a plural
+// category and one of them is "other".
+func stringTablePlural(values map[string]any) (map[PluralCategory]string, bool) {
+	if _, exists := values[PluralOther.String()]; !exists {
+		return nil, false
+	}
+
+	forms := make(map[PluralCategory]string, len(values))
+	for key, value := range values {
+		category, ok := parsePluralCategory(strings.ToLower(key))
+		if !ok {
+			return nil, false
+		}
+		if _, isMap := stringTableMap(value); isMap {
+			return nil, false
+		}
+		forms[category] = fmt.Sprint(value)
+	}
+
+	return forms, true
+}
@@ -0,0 +1,406 @@
+package finch
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/adm87/finch-core/tmpl"
+)
+
+var (
+	ErrLocaleInvalid = errors.New("locale is invalid")
+)
+
+// ======================================================
+// Localizer
+// ======================================================
+
+// LanguageChangedEvent is sent to subscribers when the language of the localizer changes.
+type LanguageChangedEvent struct {
+	Previous string
+	Language string
+}
+
+// LanguageChangedFunc is called when the language of the localizer changes.
+type LanguageChangedFunc func(event LanguageChangedEvent)
+
+// Localizer looks up localized strings in the string tables of the current language.
+//
+// The service is created on first use through Context.Localizer. Locales are written as language tags,
+// such as "en" or "pt-BR". Strings missing in a locale are looked up in its base language, then in
+// the fallback locales, in order. Tables are fetched on every lookup, so hot reloaded tables apply at once.
+//
+//	loc := ctx.Localizer()
+//	loc.AddTables("en", "locale/en.strings.yaml")
+//	loc.AddTables("de", "locale/de.strings.yaml")
+//	loc.SetFallbacks("en")
+//	loc.SetLanguage("de")
+//
+//	loc.Translate("greeting", finch.Args{"name": player})
+//	loc.Plural("apples", count, nil)
+type Localizer struct {
+	language    string
+	fallbacks   []string
+	tables      map[string][]AssetFile
+	rules       map[string]PluralRule
+	subscribers map[int]LanguageChangedFunc
+	nextID      int
+	mu          sync.RWMutex
+}
+
+// Args are the named arguments of a localized string, where they appear as {{.name}}.
+type Args map[string]any
+
+var (
+	localizerService   *Localizer
+	localizerServiceMu = sync.Mutex{}
+)
+
+// getLocalizer returns the localizer, creating it if it does not exist yet.
+func getLocalizer() *Localizer {
+	localizerServiceMu.Lock()
+	defer localizerServiceMu.Unlock()
+
+	if localizerService == nil {
+		localizerService = newLocalizer()
+	}
+
+	return localizerService
+}
+
+func newLocalizer() *Localizer {
+	return &Localizer{
+		tables:      make(map[string][]AssetFile),
+		rules:       make(map[string]PluralRule),
+		subscribers: make(map[int]LanguageChangedFunc),
+	}
+}
+
+// AddTables adds string tables to a locale. Tables added later take precedence over earlier ones.
+//
+// The tables are not loaded by the localizer. Tables that are not loaded are skipped by lookups.
+func (l *Localizer) AddTables(locale string, files ...AssetFile) error {
+	locale, err := normalizeLocale(locale)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, file := range files {
+		if !slices.Contains(l.tables[locale], file) {
+			l.tables[locale] = append(l.tables[locale], file)
+		}
+	}
+
+	return nil
+}
+
+// RemoveTables removes string tables from a locale.
+func (l *Localizer) RemoveTables(locale string, files ...AssetFile) {
+	locale, err := normalizeLocale(locale)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tables[locale] = slices.DeleteFunc(l.tables[locale], func(file AssetFile) bool {
+		return slices.Contains(files, file)
+	})
+	if len(l.tables[locale]) == 0 {
+		delete(l.tables, locale)
+	}
+}
+
+// Tables returns the string tables of a locale, in the order they were added.
+func (l *Localizer) Tables(locale string) []AssetFile {
+	locale, err := normalizeLocale(locale)
+	if err != nil {
+		return nil
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return slices.Clone(l.tables[locale])
+}
+
+// Locales returns the locales that have string tables, sorted.
+func (l *Localizer) Locales() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	locales := make([]string, 0, len(l.tables))
+	for locale := range l.tables {
+		locales = append(locales, locale)
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+func (l *Localizer) Language() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.language
+}
+
+// SetLanguage switches the current language and notifies the language changed subscribers.
+func (l *Localizer) SetLanguage(locale string) error {
+	locale, err := normalizeLocale(locale)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	previous := l.language
+	l.language = locale
+	subscribers := make([]LanguageChangedFunc, 0, len(l.subscribers))
+	for _, fn := range l.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	l.mu.Unlock()
+
+	if previous == locale {
+		return nil
+	}
+
+	event := LanguageChangedEvent{Previous: previous, Language: locale}
+	for _, fn := range subscribers {
+		fn(event)
+	}
+
+	return nil
+}
+
+func (l *Localizer) MustSetLanguage(locale string) {
+	if err := l.SetLanguage(locale); err != nil {
+		panic(err)
+	}
+}
+
+// SetFallbacks sets the locales strings are looked up in when the current language does not have them.
+func (l *Localizer) SetFallbacks(locales ...string) error {
+	fallbacks := make([]string, 0, len(locales))
+	for _, locale := range locales {
+		locale, err := normalizeLocale(locale)
+		if err != nil {
+			return err
+		}
+		fallbacks = append(fallbacks, locale)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fallbacks = fallbacks
+	return nil
+}
+
+func (l *Localizer) Fallbacks() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return slices.Clone(l.fallbacks)
+}
+
+// SetPluralRule sets the plural rule of a locale, replacing the default rule of its language.
+func (l *Localizer) SetPluralRule(locale string, rule PluralRule) error {
+	locale, err := normalizeLocale(locale)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if rule == nil {
+		delete(l.rules, locale)
+	} else {
+		l.rules[locale] = rule
+	}
+	return nil
+}
+
+// SubscribeLanguageChanged registers a callback that is invoked when the language changes.
+// The returned function removes the subscription.
+func (l *Localizer) SubscribeLanguageChanged(fn LanguageChangedFunc) (unsubscribe func()) {
+	if fn == nil {
+		return func() {}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id := l.nextID
+	l.nextID++
+	l.subscribers[id] = fn
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		delete(l.subscribers, id)
+	}
+}
+
+// Lookup returns a localized string without substituting arguments.
+func (l *Localizer) Lookup(key string) (string, bool) {
+	return l.lookup(func(locale string, table *StringTable) (string, bool) {
+		return table.Lookup(key)
+	})
+}
+
+// Has reports whether a string exists in the current language or its fallbacks.
+func (l *Localizer) Has(key string) bool {
+	_, found := l.Lookup(key)
+	return found
+}
+
+// Translate returns a localized string with its arguments substituted.
+// Missing strings are returned as their key, so they stand out without breaking the game.
+func (l *Localizer) Translate(key string, args Args) string {
+	s, found := l.Lookup(key)
+	if !found {
+		return key
+	}
+	return formatString(key, s, args)
+}
+
+// Plural returns the plural form of a localized string for a count, with its arguments substituted.
+// The count is available to the string as {{.count}}, unless the arguments set it.
+func (l *Localizer) Plural(key string, count int, args Args) string {
+	language := l.Language()
+	s, found := l.lookup(func(locale string, table *StringTable) (string, bool) {
+		// Tables of the base language follow the rule of the current language, as pt does for pt-BR.
+		if locale == baseLocale(language) {
+			locale = language
+		}
+		return table.LookupPlural(key, l.pluralRule(locale)(count))
+	})
+	if !found {
+		return key
+	}
+
+	if _, exists := args["count"]; !exists {
+		withCount := make(Args, len(args)+1)
+		for name, value := range args {
+			withCount[name] = value
+		}
+		withCount["count"] = count
+		args = withCount
+	}
+
+	return formatString(key, s, args)
+}
+
+// lookup finds the first table of the current language or its fallbacks that has a string.
+func (l *Localizer) lookup(find func(locale string, table *StringTable) (string, bool)) (string, bool) {
+	l.mu.RLock()
+	locales := localeChain(l.language, l.fallbacks)
+	tables := make([][]AssetFile, len(locales))
+	for i, locale := range locales {
+		tables[i] = l.tables[locale]
+	}
+	l.mu.RUnlock()
+
+	for i, locale := range locales {
+		for j := len(tables[i]) - 1; j >= 0; j-- {
+			table, err := GetStringTable(tables[i][j])
+			if err != nil {
+				continue
+			}
+			if s, found := find(locale, table); found {
+				return s, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func (l *Localizer) pluralRule(locale string) PluralRule {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if rule, exists := l.rules[locale]; exists {
+		return rule
+	}
+	if rule, exists := l.rules[baseLocale(locale)]; exists {
+		return rule
+	}
+	if rule, exists := defaultPluralRules[locale]; exists {
+		return rule
+	}
+	if rule, exists := defaultPluralRules[baseLocale(locale)]; exists {
+		return rule
+	}
+	return PluralRuleOneOther
+}
+
+// normalizeLocale writes a locale as a language tag, with a lower case language and an upper case
+// region: "pt_br" becomes "pt-BR".
+func normalizeLocale(locale string) (string, error) {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if locale == "" {
+		return "", fmt.Errorf("%w: empty", ErrLocaleInvalid)
+	}
+
+	parts := strings.Split(locale, "-")
+	for i, part := range parts {
+		if part == "" {
+			return "", fmt.Errorf("%w: %s", ErrLocaleInvalid, locale)
+		}
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 2:
+			parts[i] = strings.ToUpper(part)
+		}
+	}
+
+	return strings.Join(parts, "-"), nil
+}
+
+// baseLocale returns the language of a locale, without its region: "pt-BR" becomes "pt".
+func baseLocale(locale string) string {
+	base, _, _ := strings.Cut(locale, "-")
+	return base
+}
+
+// localeChain returns the locales to look strings up in, in order.
+func localeChain(language string, fallbacks []string) []string {
+	chain := make([]string, 0, 2+len(fallbacks)*2)
+	for _, locale := range append([]string{language}, fallbacks...) {
+		if locale == "" {
+			continue
+		}
+		for _, candidate := range []string{locale, baseLocale(locale)} {
+			if !slices.Contains(chain, candidate) {
+				chain = append(chain, candidate)
+			}
+		}
+	}
+	return chain
+}
+
+// formatString executes a localized string as a template of the tmpl package, with its arguments as
+// data. Strings that fail to parse or execute, such as those naming a missing argument, are returned
+// as they are, so the mistake stands out without breaking the game.
+func formatString(key string, s string, args Args) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+
+	out, err := tmpl.Execute(key, s, args, "missingkey=error")
+	if err != nil {
+		return s
+	}
+	return string(out)
+}
@@ -0,0 +1,200 @@
+package finch
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPluralRules(t *testing.T) {
+	tests := []struct {
+		locale string
+		count  int
+		want   PluralCategory
+	}{
+		{locale: "en", count: 0, want: PluralOther},
+		{locale: "en", count: 1, want: PluralOne},
+		{locale: "en-GB", count: 2, want: PluralOther},
+		{locale: "fr", count: 0, want: PluralOne},
+		{locale: "fr", count: 2, want: PluralOther},
+		{locale: "pt-BR", count: 0, want: PluralOne},
+		{locale: "pt", count: 0, want: PluralOther},
+		{locale: "ja", count: 1, want: PluralOther},
+		{locale: "ru", count: 1, want: PluralOne},
+		{locale: "ru", count: 11, want: PluralMany},
+		{locale: "ru", count: 21, want: PluralOne},
+		{locale: "ru", count: 3, want: PluralFew},
+		{locale: "ru", count: 13, want: PluralMany},
+		{locale: "ru", count: -2, want: PluralFew},
+		{locale: "uk", count: 25, want: PluralMany},
+		{locale: "pl", count: 1, want: PluralOne},
+		{locale: "pl", count: 21, want: PluralMany},
+		{locale: "pl", count: 22, want: PluralFew},
+		{locale: "pl", count: 12, want: PluralMany},
+	}
+
+	l := newLocalizer()
+	for _, tt := range tests {
+		if got := l.pluralRule(tt.locale)(tt.count); got != tt.want {
+			t.Errorf("plural rule of %s for %d = %v, want %v", tt.locale, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestSetPluralRule(t *testing.T) {
+	l := newLocalizer()
+	if err := l.SetPluralRule("EN", PluralRuleOther); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := l.pluralRule("en-US")(1); got != PluralOther {
+		t.Fatalf("plural rule of en-US for 1 = %v, want the rule set for en", got)
+	}
+
+	if err := l.SetPluralRule("en", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.pluralRule("en-US")(1); got != PluralOne {
+		t.Fatalf("plural rule of en-US for 1 = %v, want the default rule after removing it", got)
+	}
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   string
+		err    error
+	}{
+		{locale: "en", want: "en"},
+		{locale: "EN", want: "en"},
+		{locale: "pt_br", want: "pt-BR"},
+		{locale: " pt-br ", want: "pt-BR"},
+		{locale: "zh-Hant-TW", want: "zh-Hant-TW"},
+		{locale: "", err: ErrLocaleInvalid},
+		{locale: "en-", err: ErrLocaleInvalid},
+		{locale: "-US", err: ErrLocaleInvalid},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeLocale(tt.locale)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("normalizeLocale(%q) error = %v, want %v", tt.locale, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeLocale(%q) = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleChain(t *testing.T) {
+	got := localeChain("pt-BR", []string{"pt", "en-US", "en"})
+	want := []string{"pt-BR", "pt", "en-US", "en"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("localeChain() = %v, want %v", got, want)
+	}
+
+	if got := localeChain("", []string{"en"}); !slices.Equal(got, []string{"en"}) {
+		t.Fatalf("localeChain() without a language = %v, want the fallbacks", got)
+	}
+}
+
+func TestFormatString(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		args Args
+		want string
+	}{
+		{name: "plain", s: "Play", want: "Play"},
+		{name: "argument", s: "Hello, {{.name}}!", args: Args{"name": "Ada"}, want: "Hello, Ada!"},
+		{name: "number", s: "{{.count}} apples", args: Args{"count": 3}, want: "3 apples"},
+		{name: "function", s: "{{.name | upper}}", args: Args{"name": "Ada"}, want: "ADA"},
+		{name: "missing argument", s: "Hello, {{.name}}!", want: "Hello, {{.name}}!"},
+		{name: "invalid template", s: "Hello, {{.name", args: Args{"name": "Ada"}, want: "Hello, {{.name"},
+		{name: "single braces", s: "{name}", args: Args{"name": "Ada"}, want: "{name}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatString("key", tt.s, tt.args); got != tt.want {
+				t.Fatalf("formatString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizer(t *testing.T) {
+	MustRegisterStringTableAssetImport()
+
+	files := fstest.MapFS{
+		"en.strings.yaml": {Data: []byte("menu:\n  play: Play\n  quit: Quit\ngreeting: \"Hello, {{.name}}!\"\napples:\n  one: \"{{.count}} apple\"\n  other: \"{{.count}} apples\"\n")},
+		"pt.strings.yaml": {Data: []byte("menu:\n  play: Jogar\napples:\n  one: \"{{.count}} maçã\"\n  other: \"{{.count}} maçãs\"\n")},
+		"ru.strings.json": {Data: []byte(`{"apples": {"one": "{{.count}} яблоко", "few": "{{.count}} яблока", "many": "{{.count}} яблок", "other": "{{.count}} яблока"}}`)},
+	}
+	if err := RegisterAssetFilesystem("locale", files); err != nil {
+		t.Fatal(err)
+	}
+	MustLoadAssets("locale/en.strings.yaml", "locale/pt.strings.yaml", "locale/ru.strings.json")
+
+	l := newLocalizer()
+	if err := errors.Join(
+		l.AddTables("en", "locale/en.strings.yaml"),
+		l.AddTables("pt", "locale/pt.strings.yaml"),
+		l.AddTables("ru", "locale/ru.strings.json"),
+		l.SetFallbacks("en"),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	events := make([]LanguageChangedEvent, 0)
+	unsubscribe := l.SubscribeLanguageChanged(func(event LanguageChangedEvent) {
+		events = append(events, event)
+	})
+
+	l.MustSetLanguage("pt_br")
+
+	tests := []struct {
+		key    string
+		plural bool
+		count  int
+		args   Args
+		want   string
+	}{
+		{key: "menu.play", want: "Jogar"},
+		{key: "menu.quit", want: "Quit"},
+		{key: "greeting", args: Args{"name": "Ada"}, want: "Hello, Ada!"},
+		{key: "missing", want: "missing"},
+		{key: "apples", plural: true, count: 0, want: "0 maçã"},
+		{key: "apples", plural: true, count: 2, want: "2 maçãs"},
+	}
+
+	for _, tt := range tests {
+		got := l.Translate(tt.key, tt.args)
+		if tt.plural {
+			got = l.Plural(tt.key, tt.count, tt.args)
+		}
+		if got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	l.MustSetLanguage("ru")
+	for count, want := range map[int]string{1: "1 яблоко", 3: "3 яблока", 5: "5 яблок"} {
+		if got := l.Plural("apples", count, nil); got != want {
+			t.Errorf("Plural(apples, %d) in ru = %q, want %q", count, got, want)
+		}
+	}
+	if got := l.Plural("apples", 5, Args{"count": "five"}); got != "five яблок" {
+		t.Errorf("Plural() with a count argument = %q, want the argument", got)
+	}
+
+	unsubscribe()
+	l.MustSetLanguage("en")
+
+	want := []LanguageChangedEvent{{Previous: "", Language: "pt-BR"}, {Previous: "pt-BR", Language: "ru"}}
+	if !slices.Equal(events, want) {
+		t.Fatalf("language changed events = %v, want %v", events, want)
+	}
+}
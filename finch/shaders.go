@@ -0,0 +1,506 @@
+package finch
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/adm87/finch-core/hashset"
+	"github.com/adm87/finch-core/internal/assets"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	KageAssetType = "kage"
+
+	// ShaderIncludeDirective includes another shader asset, by path relative to the including file:
+	//
+	//	#include "common/noise.kage"
+	ShaderIncludeDirective = "#include"
+)
+
+var (
+	ErrShaderCompileFailed   = errors.New("shader compile failed")
+	ErrShaderIncludeInvalid  = errors.New("shader include is invalid")
+	ErrShaderProgramMissing  = errors.New("shader has no Fragment function")
+	ErrShaderUniformNotFound = errors.New("shader uniform not found")
+	ErrShaderUniformMismatch = errors.New("shader uniform value does not match its type")
+)
+
+var (
+	shaderFragmentPattern = regexp.MustCompile(`(?m)^\s*func\s+Fragment\s*\(`)
+	shaderErrorPattern    = regexp.MustCompile(`^(\d+):(\d+): (.*)$`)
+)
+
+// ======================================================
+// Shader
+// ======================================================
+
+// Shader is a loaded Kage shader, with its includes expanded.
+//
+// Shaders without a Fragment function are not compiled. They are only meant to be included by other shaders.
+type Shader struct {
+	program  *ebiten.Shader
+	raw      []byte
+	source   []byte
+	uniforms []ShaderUniform
+}
+
+// Program returns the compiled shader, or nil if the shader has no Fragment function.
+func (s *Shader) Program() *ebiten.Shader {
+	return s.program
+}
+
+// Source returns the source the shader was compiled from, with its includes expanded.
+func (s *Shader) Source() []byte {
+	return s.source
+}
+
+// Uniforms returns the uniform variables the shader declares, in declaration order.
+func (s *Shader) Uniforms() []ShaderUniform {
+	return s.uniforms
+}
+
+func (s *Shader) Uniform(name string) (ShaderUniform, bool) {
+	for _, uniform := range s.uniforms {
+		if uniform.Name == name {
+			return uniform, true
+		}
+	}
+	return ShaderUniform{}, false
+}
+
+// NewUniforms returns an empty set of uniform values, checked against the uniforms of the shader.
+func (s *Shader) NewUniforms() *ShaderUniforms {
+	return &ShaderUniforms{
+		shader: s,
+		values: make(map[string]any, len(s.uniforms)),
+	}
+}
+
+// RegisterShaderAssetImport registers the importer of Kage shaders.
+//
+// Shaders can include other shader assets with the #include directive, which loads them as dependencies.
+// The package clause and //kage: directives of included files are ignored, so they can be written as
+// complete Kage files. Compile errors are reported against the file and line they occur in.
+func RegisterShaderAssetImport() {
+	RegisterAssetImporter(&AssetImporter{
+		AssetTypes: []AssetType{
+			KageAssetType,
+		},
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			return compileShader(file, data)
+		},
+		CleanupAssetFile: func(file AssetFile, data any) error {
+			shader, ok := data.(*Shader)
+			if !ok {
+				return fmt.Errorf("%w: expected *Shader, got %T", ErrAssetTypeMismatch, data)
+			}
+			if shader.program != nil {
+				shader.program.Deallocate()
+			}
+			return nil
+		},
+		OutputType: reflect.TypeFor[*Shader](),
+	})
+}
+
+func GetShader(file AssetFile) (*Shader, error) {
+	shader, err := GetAsset[*Shader](file)
+	if err != nil {
+		return nil, err
+	}
+	return shader, nil
+}
+
+func MustGetShader(file AssetFile) *Shader {
+	return MustGetAsset[*Shader](file)
+}
+
+// GetShaderProgram returns the compiled program of a loaded shader.
+func GetShaderProgram(file AssetFile) (*ebiten.Shader, error) {
+	shader, err := GetShader(file)
+	if err != nil {
+		return nil, err
+	}
+	if shader.program == nil {
+		return nil, assets.NewAssetError("get", file, ErrShaderProgramMissing)
+	}
+	return shader.program, nil
+}
+
+func MustGetShaderProgram(file AssetFile) *ebiten.Shader {
+	program, err := GetShaderProgram(file)
+	if err != nil {
+		panic(err)
+	}
+	return program
+}
+
+// ======================================================
+// Shader Errors
+// ======================================================
+
+// ShaderError is a compile error at a position of a shader source file, or one of its includes.
+//
+//	var shaderErr *finch.ShaderError
+//	if errors.As(err, &shaderErr) {
+//		log.Println(shaderErr.File, shaderErr.Line)
+//	}
+type ShaderError struct {
+	File    AssetFile
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *ShaderError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File.Path(), e.Line, e.Column, e.Message)
+}
+
+// shaderLine is the file and line a line of an expanded shader source comes from.
+type shaderLine struct {
+	file AssetFile
+	line int
+}
+
+// mapShaderErrors rewrites the errors of a compiled source to the files and lines they come from.
+func mapShaderErrors(err error, lines []shaderLine) error {
+	messages := make([]string, 0)
+
+	var list scanner.ErrorList
+	if errors.As(err, &list) {
+		for _, e := range list {
+			messages = append(messages, e.Error())
+		}
+	} else {
+		messages = strings.Split(err.Error(), "\n")
+	}
+
+	errs := []error{ErrShaderCompileFailed}
+	for _, message := range messages {
+		match := shaderErrorPattern.FindStringSubmatch(message)
+		if match == nil {
+			errs = append(errs, errors.New(message))
+			continue
+		}
+
+		line, _ := strconv.Atoi(match[1])
+		column, _ := strconv.Atoi(match[2])
+
+		// Errors past the end of the source are in code ebiten appends to it.
+		if line < 1 || line > len(lines) {
+			errs = append(errs, errors.New(message))
+			continue
+		}
+
+		errs = append(errs, &ShaderError{
+			File:    lines[line-1].file,
+			Line:    lines[line-1].line,
+			Column:  column,
+			Message: match[3],
+		})
+	}
+
+	return errors.Join(errs...)
+}
+
+// ======================================================
+// Shader Compilation
+// ======================================================
+
+func compileShader(file AssetFile, data []byte) (*Shader, error) {
+	includes, err := parseShaderIncludes(file, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := LoadAssetDependencies(file, includes...); err != nil {
+		return nil, err
+	}
+
+	source, lines, err := expandShaderSource(file, data, hashset.New[AssetFile](), false)
+	if err != nil {
+		return nil, err
+	}
+
+	shader := &Shader{
+		raw:    data,
+		source: source,
+	}
+
+	if !shaderFragmentPattern.Match(source) {
+		return shader, nil
+	}
+
+	program, err := ebiten.NewShader(source)
+	if err != nil {
+		return nil, mapShaderErrors(err, lines)
+	}
+	shader.program = program
+
+	uniforms, err := reflectShaderUniforms(source)
+	if err != nil {
+		program.Deallocate()
+		return nil, mapShaderErrors(err, lines)
+	}
+	shader.uniforms = uniforms
+
+	return shader, nil
+}
+
+// parseShaderIncludes returns the asset files a shader source includes directly.
+func parseShaderIncludes(file AssetFile, data []byte) ([]AssetFile, error) {
+	includes := make([]AssetFile, 0)
+
+	for i, line := range strings.Split(string(data), "\n") {
+		include, ok, err := parseShaderInclude(file, line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file.Path(), i+1, err)
+		}
+		if ok {
+			includes = append(includes, include)
+		}
+	}
+
+	return includes, nil
+}
+
+func parseShaderInclude(file AssetFile, line string) (AssetFile, bool, error) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, ShaderIncludeDirective) {
+		return "", false, nil
+	}
+
+	name, err := strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(line, ShaderIncludeDirective)))
+	if err != nil || name == "" {
+		return "", false, fmt.Errorf("%w: %s", ErrShaderIncludeInvalid, line)
+	}
+
+	return AssetFile(path.Join(path.Dir(file.Path()), name)), true, nil
+}
+
+// expandShaderSource replaces the include directives of a shader source with the sources of the
+// included shaders, which must be loaded. Each file is included once.
+func expandShaderSource(file AssetFile, data []byte, included hashset.Set[AssetFile], isInclude bool) ([]byte, []shaderLine, error) {
+	included.Add(file)
+
+	var (
+		b     strings.Builder
+		lines = make([]shaderLine, 0)
+	)
+
+	for i, line := range strings.Split(string(data), "\n") {
+		include, ok, err := parseShaderInclude(file, line)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s:%d: %w", file.Path(), i+1, err)
+		}
+
+		if ok {
+			if !included.Contains(include) {
+				dependency, err := GetShader(include)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				source, includeLines, err := expandShaderSource(include, dependency.raw, included, true)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				b.Write(source)
+				lines = append(lines, includeLines...)
+				continue
+			}
+			line = ""
+		} else if isInclude && isShaderHeader(line) {
+			line = ""
+		}
+
+		b.WriteString(line)
+		b.WriteString("\n")
+		lines = append(lines, shaderLine{file: file, line: i + 1})
+	}
+
+	return []byte(b.String()), lines, nil
+}
+
+// isShaderHeader reports whether a line is a package clause or a //kage: directive.
+func isShaderHeader(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "package ") || strings.HasPrefix(line, "//kage:")
+}
+
+// ======================================================
+// Shader Uniforms
+// ======================================================
+
+// ShaderUniform is a uniform variable declared by a shader.
+type ShaderUniform struct {
+	Name string
+	Type string // Kage type of the variable, or of its elements for arrays, such as "float" or "vec4"
+	Len  int    // Length of an array uniform, or 0
+}
+
+// Components returns the number of values the uniform is set with.
+func (u ShaderUniform) Components() int {
+	components := 1
+	switch u.Type {
+	case "vec2", "ivec2":
+		components = 2
+	case "vec3", "ivec3":
+		components = 3
+	case "vec4", "ivec4", "mat2":
+		components = 4
+	case "mat3":
+		components = 9
+	case "mat4":
+		components = 16
+	}
+	return components * max(u.Len, 1)
+}
+
+// IsInt reports whether the uniform is set with int values rather than float values.
+func (u ShaderUniform) IsInt() bool {
+	return u.Type == "int" || strings.HasPrefix(u.Type, "ivec")
+}
+
+func reflectShaderUniforms(source []byte) ([]ShaderUniform, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", source, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	uniforms := make([]ShaderUniform, 0)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+
+			uniform := ShaderUniform{}
+			switch t := value.Type.(type) {
+			case *ast.Ident:
+				uniform.Type = t.Name
+			case *ast.ArrayType:
+				elem, ok := t.Elt.(*ast.Ident)
+				length, isLit := t.Len.(*ast.BasicLit)
+				if !ok || !isLit {
+					continue
+				}
+				uniform.Type = elem.Name
+				uniform.Len, _ = strconv.Atoi(length.Value)
+			default:
+				continue
+			}
+
+			for _, name := range value.Names {
+				uniform.Name = name.Name
+				uniforms = append(uniforms, uniform)
+			}
+		}
+	}
+
+	return uniforms, nil
+}
+
+// ShaderUniforms is a set of uniform values for a shader, checked against the uniforms it declares.
+//
+//	uniforms := shader.NewUniforms()
+//	uniforms.SetFloat("Time", t)
+//	uniforms.SetVec2("Resolution", w, h)
+//
+//	screen.DrawRectShader(w, h, shader.Program(), &ebiten.DrawRectShaderOptions{
+//		Uniforms: uniforms.Map(),
+//	})
+type ShaderUniforms struct {
+	shader *Shader
+	values map[string]any
+}
+
+// Map returns the uniform values, as expected by ebiten's shader draw options.
+func (u *ShaderUniforms) Map() map[string]any {
+	return u.values
+}
+
+func (u *ShaderUniforms) SetFloat(name string, value float32) error {
+	return u.SetFloats(name, value)
+}
+
+func (u *ShaderUniforms) SetVec2(name string, x, y float32) error {
+	return u.SetFloats(name, x, y)
+}
+
+func (u *ShaderUniforms) SetVec3(name string, x, y, z float32) error {
+	return u.SetFloats(name, x, y, z)
+}
+
+func (u *ShaderUniforms) SetVec4(name string, x, y, z, w float32) error {
+	return u.SetFloats(name, x, y, z, w)
+}
+
+// SetFloats sets a float based uniform, such as a vector, matrix or array, from all of its components.
+func (u *ShaderUniforms) SetFloats(name string, values ...float32) error {
+	uniform, err := u.check(name, false, len(values))
+	if err != nil {
+		return err
+	}
+	if uniform.Components() == 1 {
+		u.values[name] = values[0]
+	} else {
+		u.values[name] = values
+	}
+	return nil
+}
+
+func (u *ShaderUniforms) SetInt(name string, value int32) error {
+	return u.SetInts(name, value)
+}
+
+// SetInts sets an int based uniform, such as an ivec or array, from all of its components.
+func (u *ShaderUniforms) SetInts(name string, values ...int32) error {
+	uniform, err := u.check(name, true, len(values))
+	if err != nil {
+		return err
+	}
+	if uniform.Components() == 1 {
+		u.values[name] = values[0]
+	} else {
+		u.values[name] = values
+	}
+	return nil
+}
+
+// Delete removes a uniform value, so the shader uses its zero value.
+func (u *ShaderUniforms) Delete(name string) {
+	delete(u.values, name)
+}
+
+func (u *ShaderUniforms) check(name string, isInt bool, count int) (ShaderUniform, error) {
+	uniform, exists := u.shader.Uniform(name)
+	if !exists {
+		return uniform, fmt.Errorf("%w: %s", ErrShaderUniformNotFound, name)
+	}
+	if uniform.IsInt() != isInt || uniform.Components() != count {
+		return uniform, fmt.Errorf("%w: %s is %s, got %d values", ErrShaderUniformMismatch, name, uniform.typeName(), count)
+	}
+	return uniform, nil
+}
+
+func (u ShaderUniform) typeName() string {
+	if u.Len > 0 {
+		return fmt.Sprintf("[%d]%s", u.Len, u.Type)
+	}
+	return u.Type
+}
@@ -0,0 +1,205 @@
+package finch
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"path"
+	"reflect"
+
+	"github.com/adm87/finch-core/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	TmxAssetType = "tmx"
+	TmjAssetType = "tmj"
+	TsxAssetType = "tsx"
+	TsjAssetType = "tsj"
+)
+
+// ======================================================
+// Tiled Maps
+// ======================================================
+
+// RegisterTiledAssetImport registers the importers of Tiled maps and tilesets, in both the XML and
+// JSON formats.
+//
+// External tilesets and every image used by a map are loaded as its dependencies, and image sources
+// are rewritten to asset paths, so the image importer must be registered too.
+func RegisterTiledAssetImport() {
+	RegisterAssetImporter(&AssetImporter{
+		AssetTypes: []AssetType{
+			TmxAssetType,
+			TmjAssetType,
+		},
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			return parseTiledMap(file, data)
+		},
+		OutputType: reflect.TypeFor[*tiled.Map](),
+	})
+
+	RegisterAssetImporter(&AssetImporter{
+		AssetTypes: []AssetType{
+			TsxAssetType,
+			TsjAssetType,
+		},
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			return parseTiledTileset(file, data)
+		},
+		OutputType: reflect.TypeFor[*tiled.Tileset](),
+	})
+}
+
+func GetTiledMap(file AssetFile) (*tiled.Map, error) {
+	m, err := GetAsset[*tiled.Map](file)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func MustGetTiledMap(file AssetFile) *tiled.Map {
+	return MustGetAsset[*tiled.Map](file)
+}
+
+func GetTiledTileset(file AssetFile) (*tiled.Tileset, error) {
+	tileset, err := GetAsset[*tiled.Tileset](file)
+	if err != nil {
+		return nil, err
+	}
+	return tileset, nil
+}
+
+func MustGetTiledTileset(file AssetFile) *tiled.Tileset {
+	return MustGetAsset[*tiled.Tileset](file)
+}
+
+// GetTiledTileImage returns the image of a tile of a loaded map, cut from its tileset image.
+// Flip flags of the gid are not applied.
+func GetTiledTileImage(m *tiled.Map, gid tiled.GID) (*ebiten.Image, error) {
+	ref, id, ok := m.TilesetFor(gid)
+	if !ok {
+		return nil, fmt.Errorf("%w: gid %d", ErrAssetFragmentNotFound, gid.ID())
+	}
+	if ref.Tileset == nil {
+		return nil, fmt.Errorf("%w: %s", tiled.ErrTilesetNotResolved, ref.Source)
+	}
+
+	if tile, ok := ref.Tileset.Tile(id); ok && tile.Image != nil {
+		return GetImage(AssetFile(tile.Image.Source))
+	}
+
+	if ref.Tileset.Image == nil {
+		return nil, fmt.Errorf("%w: tile %d has no image", ErrAssetFragmentNotFound, id)
+	}
+
+	img, err := GetImage(AssetFile(ref.Tileset.Image.Source))
+	if err != nil {
+		return nil, err
+	}
+
+	x, y, w, h := ref.Tileset.TileRect(id)
+	return img.SubImage(image.Rect(x, y, x+w, y+h)).(*ebiten.Image), nil
+}
+
+func MustGetTiledTileImage(m *tiled.Map, gid tiled.GID) *ebiten.Image {
+	img, err := GetTiledTileImage(m, gid)
+	if err != nil {
+		panic(err)
+	}
+	return img
+}
+
+func parseTiledMap(file AssetFile, data []byte) (*tiled.Map, error) {
+	var (
+		m   *tiled.Map
+		err error
+	)
+
+	switch file.Type() {
+	case TmjAssetType:
+		m, err = tiled.DecodeTMJ(bytes.NewReader(data))
+	default:
+		m, err = tiled.DecodeTMX(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dir := path.Dir(file.Path())
+
+	// Embedded tilesets and image layers reference images relative to the map.
+	images := make([]AssetFile, 0)
+	for _, ref := range m.Tilesets {
+		if ref.Tileset != nil {
+			images = append(images, resolveTiledImages(dir, ref.Tileset)...)
+		}
+	}
+	for _, layer := range m.AllLayers() {
+		if layer.Image != nil {
+			layer.Image.Source = path.Join(dir, layer.Image.Source)
+			images = append(images, AssetFile(layer.Image.Source))
+		}
+	}
+
+	err = m.ResolveTilesets(func(source string) (*tiled.Tileset, error) {
+		tilesetFile := AssetFile(path.Join(dir, source))
+		if err := LoadAssetDependencies(file, tilesetFile); err != nil {
+			return nil, err
+		}
+		return GetTiledTileset(tilesetFile)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := LoadAssetDependencies(file, images...); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func parseTiledTileset(file AssetFile, data []byte) (*tiled.Tileset, error) {
+	var (
+		tileset *tiled.Tileset
+		err     error
+	)
+
+	switch file.Type() {
+	case TsjAssetType:
+		tileset, err = tiled.DecodeTSJ(bytes.NewReader(data))
+	default:
+		tileset, err = tiled.DecodeTSX(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	images := resolveTiledImages(path.Dir(file.Path()), tileset)
+	if err := LoadAssetDependencies(file, images...); err != nil {
+		return nil, err
+	}
+
+	return tileset, nil
+}
+
+// resolveTiledImages rewrites the image sources of a tileset to asset paths and returns them.
+func resolveTiledImages(dir string, tileset *tiled.Tileset) []AssetFile {
+	images := make([]AssetFile, 0, 1)
+
+	if tileset.Image != nil {
+		tileset.Image.Source = path.Join(dir, tileset.Image.Source)
+		images = append(images, AssetFile(tileset.Image.Source))
+	}
+
+	for _, tile := range tileset.Tiles {
+		if tile.Image != nil {
+			tile.Image.Source = path.Join(dir, tile.Image.Source)
+			images = append(images, AssetFile(tile.Image.Source))
+		}
+	}
+
+	return images
+}
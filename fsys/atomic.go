@@ -0,0 +1,88 @@
+package fsys
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// WriteFileAtomic writes data to a temporary file in the directory of the named file, syncs it and
+// renames it over the named file, so the file holds either its old or its new contents, never a
+// partial write. The file is created with the given permissions if it does not exist, and keeps its
+// mode if it does. A symlink is followed and its target is replaced, so the link itself is kept.
+func WriteFileAtomic(name string, data []byte, perm os.FileMode) error {
+	return WriteAtomic(name, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+func MustWriteFileAtomic(name string, data []byte, perm os.FileMode) {
+	Must(WriteFileAtomic(name, data, perm))
+}
+
+// WriteAtomic is WriteFileAtomic for contents too large to hold in memory, which write streams into
+// the temporary file. The named file is left untouched if write returns an error.
+func WriteAtomic(name string, perm os.FileMode, write func(w io.Writer) error) error {
+	if target, err := filepath.EvalSymlinks(name); err == nil {
+		name = target
+	}
+	if info, err := os.Stat(name); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return syncDir(filepath.Dir(name))
+}
+
+func MustWriteAtomic(name string, perm os.FileMode, write func(w io.Writer) error) {
+	Must(WriteAtomic(name, perm, write))
+}
+
+// syncDir syncs a directory, so a rename into it survives a crash. Windows cannot open directories
+// for syncing, and commits renames to disk on its own.
+func syncDir(name string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	dir, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	return dir.Sync()
+}
@@ -0,0 +1,110 @@
+package fsys
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "a.txt")
+
+	if err := WriteFileAtomic(name, []byte("one"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(name, []byte("two"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "two" {
+		t.Fatalf("contents = %q, want %q", data, "two")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("directory has %d entries, want no temporary files left", len(entries))
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Fatalf("mode = %v, want the existing file's mode %v", info.Mode().Perm(), os.FileMode(0o600))
+		}
+	}
+}
+
+func TestWriteFileAtomicSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.txt")
+	link := filepath.Join(dir, "link.txt")
+
+	if err := os.WriteFile(target, []byte("one"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks are not supported:", err)
+	}
+
+	if err := WriteFileAtomic(link, []byte("two"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("link was replaced, Lstat() = %v, %v", info, err)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "two" {
+		t.Fatalf("target contents = %q, want %q", data, "two")
+	}
+}
+
+func TestWriteAtomicError(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "a.txt")
+
+	if err := os.WriteFile(name, []byte("one"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	errWrite := errors.New("write failed")
+	err := WriteAtomic(name, 0o644, func(w io.Writer) error {
+		w.Write([]byte("partial"))
+		return errWrite
+	})
+	if !errors.Is(err, errWrite) {
+		t.Fatalf("WriteAtomic() error = %v, want %v", err, errWrite)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "one" {
+		t.Fatalf("contents = %q, want the file untouched", data)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("directory has %d entries, want no temporary files left", len(entries))
+	}
+}
@@ -1,6 +1,7 @@
 package fsys
 
 import (
+	"bytes"
 	"encoding/json"
 	"os"
 )
@@ -23,41 +24,29 @@ func ReadJson[T any](path string, data T) error {
 	return nil
 }
 
+// WriteJson encodes data as json and writes it atomically, see WriteFileAtomic.
 func WriteJson[T any](path string, data T) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+	var buf bytes.Buffer
 
-	encoder := json.NewEncoder(file)
+	encoder := json.NewEncoder(&buf)
 
 	if err := encoder.Encode(data); err != nil {
 		return err
 	}
 
-	if err := file.Sync(); err != nil {
-		return err
-	}
-	return nil
+	return WriteFileAtomic(path, buf.Bytes(), 0o644)
 }
 
+// WriteJsonIndent encodes data as indented json and writes it atomically, see WriteFileAtomic.
 func WriteJsonIndent[T any](path string, data T) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+	var buf bytes.Buffer
 
-	encoder := json.NewEncoder(file)
+	encoder := json.NewEncoder(&buf)
 	encoder.SetIndent("", "  ")
 
 	if err := encoder.Encode(data); err != nil {
 		return err
 	}
 
-	if err := file.Sync(); err != nil {
-		return err
-	}
-	return nil
+	return WriteFileAtomic(path, buf.Bytes(), 0o644)
 }
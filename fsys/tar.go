@@ -0,0 +1,342 @@
+package fsys
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MaxTarSize is the total size of the files read from a tar archive. Larger archives are rejected
+// before their contents are read, since every file is held in memory.
+const MaxTarSize = 1 << 30
+
+// maxTarLinks is the number of links followed to resolve a link entry.
+const maxTarLinks = 40
+
+var (
+	ErrTarInvalidPath = errors.New("tar entry path is invalid")
+	ErrTarInvalidLink = errors.New("tar link target is invalid")
+	ErrTarTooLarge    = errors.New("tar archive is too large")
+)
+
+// ======================================================
+// Tar Filesystem
+// ======================================================
+
+// TarFS is a read-only, in-memory filesystem of the regular files of a tar archive. It implements fs.FS,
+// fs.ReadFileFS, fs.ReadDirFS and fs.StatFS.
+//
+// Directories are implied by file paths. Hard links and symbolic links are resolved to the files they
+// link to within the archive, and links to directories or outside the archive are rejected. Directory
+// entries and other special entries are skipped.
+type TarFS struct {
+	files map[string]*tarFile
+	dirs  map[string][]fs.DirEntry
+}
+
+// OpenTar reads the tar archive at the given path, gzip compressed or not.
+func OpenTar(name string) (*TarFS, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	t, err := NewTarFS(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return t, nil
+}
+
+// NewTarFS reads a tar archive, gzip compressed or not, such as an embedded byte slice.
+func NewTarFS(r io.Reader) (*TarFS, error) {
+	buffered := bufio.NewReader(r)
+
+	// Gzip streams start with the magic bytes 0x1f 0x8b.
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	t := &TarFS{files: make(map[string]*tarFile)}
+	links := make(map[string]*tar.Header)
+
+	var size int64
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeLink && header.Typeflag != tar.TypeSymlink {
+			continue
+		}
+
+		name, ok := tarPath(header.Name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrTarInvalidPath, header.Name)
+		}
+
+		// Later entries replace earlier ones, as when the archive is extracted.
+		if header.Typeflag != tar.TypeReg {
+			delete(t.files, name)
+			links[name] = header
+			continue
+		}
+
+		if header.Size > MaxTarSize-size {
+			return nil, fmt.Errorf("%w: %s: more than %d bytes", ErrTarTooLarge, header.Name, MaxTarSize)
+		}
+		size += header.Size
+
+		data := make([]byte, header.Size)
+		if _, err := io.ReadFull(tr, data); err != nil {
+			return nil, fmt.Errorf("%s: %w", header.Name, err)
+		}
+
+		delete(links, name)
+		t.files[name] = &tarFile{name: name, data: data, modTime: header.ModTime, mode: fs.FileMode(header.Mode).Perm()}
+	}
+
+	for name := range links {
+		target, err := t.resolveLink(name, links)
+		if err != nil {
+			return nil, err
+		}
+
+		t.files[name] = &tarFile{name: name, data: target.data, modTime: target.modTime, mode: target.mode}
+	}
+
+	t.buildDirs()
+
+	return t, nil
+}
+
+func MustOpenTar(name string) *TarFS {
+	return MustGet(OpenTar(name))
+}
+
+func (t *TarFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if file, exists := t.files[name]; exists {
+		return &tarReader{Reader: bytes.NewReader(file.data), file: file}, nil
+	}
+
+	if entries, exists := t.dirs[name]; exists {
+		return &tarDirReader{dir: tarDir{name: name}, entries: entries}, nil
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (t *TarFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+
+	file, exists := t.files[name]
+	if !exists {
+		if _, isDir := t.dirs[name]; isDir {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+		}
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return bytes.Clone(file.data), nil
+}
+
+func (t *TarFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	entries, exists := t.dirs[name]
+	if !exists {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return slices.Clone(entries), nil
+}
+
+func (t *TarFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if file, exists := t.files[name]; exists {
+		return file, nil
+	}
+
+	if _, exists := t.dirs[name]; exists {
+		return tarDir{name: name}, nil
+	}
+
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// resolveLink follows a link entry, and the links it leads to, to the regular file it links to.
+//
+// Hard link targets are archive paths, and symbolic link targets are relative to the link's directory.
+func (t *TarFS) resolveLink(name string, links map[string]*tar.Header) (*tarFile, error) {
+	for range maxTarLinks {
+		header := links[name]
+
+		target := header.Linkname
+		if header.Typeflag == tar.TypeSymlink && !path.IsAbs(target) {
+			target = path.Join(path.Dir(name), target)
+		}
+
+		target, ok := tarPath(target)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: %q", ErrTarInvalidLink, header.Name, header.Linkname)
+		}
+
+		if file, exists := t.files[target]; exists {
+			return file, nil
+		}
+		if _, exists := links[target]; !exists {
+			return nil, fmt.Errorf("%w: %s: %q is not a file", ErrTarInvalidLink, header.Name, header.Linkname)
+		}
+
+		name = target
+	}
+
+	return nil, fmt.Errorf("%w: %s: too many links", ErrTarInvalidLink, name)
+}
+
+// tarPath cleans the path of a tar entry, reporting whether it is a valid path within the archive.
+func tarPath(name string) (string, bool) {
+	name = strings.TrimPrefix(path.Clean(strings.TrimPrefix(name, "./")), "./")
+	return name, fs.ValidPath(name) && name != "."
+}
+
+// buildDirs derives the directory tree from the file paths.
+func (t *TarFS) buildDirs() {
+	t.dirs = map[string][]fs.DirEntry{".": nil}
+
+	for _, file := range t.files {
+		var child fs.DirEntry = file
+
+		for name := file.name; name != "."; {
+			parent := path.Dir(name)
+			_, known := t.dirs[parent]
+
+			t.dirs[parent] = append(t.dirs[parent], child)
+
+			if known {
+				break
+			}
+
+			child = tarDir{name: parent}
+			name = parent
+		}
+	}
+
+	for _, entries := range t.dirs {
+		slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+			return strings.Compare(a.Name(), b.Name())
+		})
+	}
+}
+
+// ======================================================
+// Tar Entries
+// ======================================================
+
+// tarFile implements fs.FileInfo and fs.DirEntry for a regular file of a tar archive.
+type tarFile struct {
+	name    string
+	data    []byte
+	modTime time.Time
+	mode    fs.FileMode
+}
+
+func (f *tarFile) Name() string       { return path.Base(f.name) }
+func (f *tarFile) Size() int64        { return int64(len(f.data)) }
+func (f *tarFile) Mode() fs.FileMode  { return f.mode }
+func (f *tarFile) ModTime() time.Time { return f.modTime }
+func (f *tarFile) IsDir() bool        { return false }
+func (f *tarFile) Sys() any           { return nil }
+
+func (f *tarFile) Info() (fs.FileInfo, error) { return f, nil }
+func (f *tarFile) Type() fs.FileMode          { return 0 }
+
+// tarDir implements fs.FileInfo and fs.DirEntry for a directory implied by file paths.
+type tarDir struct {
+	name string
+}
+
+func (d tarDir) Name() string       { return path.Base(d.name) }
+func (d tarDir) Size() int64        { return 0 }
+func (d tarDir) Mode() fs.FileMode  { return fs.ModeDir | 0o555 }
+func (d tarDir) ModTime() time.Time { return time.Time{} }
+func (d tarDir) IsDir() bool        { return true }
+func (d tarDir) Sys() any           { return nil }
+
+func (d tarDir) Info() (fs.FileInfo, error) { return d, nil }
+func (d tarDir) Type() fs.FileMode          { return fs.ModeDir }
+
+type tarReader struct {
+	*bytes.Reader
+	file *tarFile
+}
+
+func (r *tarReader) Stat() (fs.FileInfo, error) { return r.file, nil }
+func (r *tarReader) Close() error               { return nil }
+
+type tarDirReader struct {
+	dir     tarDir
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *tarDirReader) Stat() (fs.FileInfo, error) { return d.dir, nil }
+func (d *tarDirReader) Close() error               { return nil }
+
+func (d *tarDirReader) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.dir.name, Err: errors.New("is a directory")}
+}
+
+func (d *tarDirReader) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return slices.Clone(remaining), nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	n = min(n, len(remaining))
+	d.offset += n
+
+	return slices.Clone(remaining[:n]), nil
+}
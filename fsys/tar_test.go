@@ -0,0 +1,233 @@
+package fsys
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// tarEntry is an entry of a tar archive built by a test.
+type tarEntry struct {
+	name string
+	data string
+	link string
+	flag byte
+}
+
+func buildTar(t *testing.T, compressed bool, entries ...tarEntry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(&buf)
+	if compressed {
+		tw = tar.NewWriter(gz)
+	}
+
+	for _, e := range entries {
+		flag := e.flag
+		if flag == 0 {
+			flag = tar.TypeReg
+		}
+
+		header := &tar.Header{Name: e.name, Linkname: e.link, Typeflag: flag, Mode: 0o644, ModTime: time.Unix(1700000000, 0)}
+		if flag == tar.TypeReg {
+			header.Size = int64(len(e.data))
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if compressed {
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+var archiveFiles = []tarEntry{
+	{name: "./assets/ui/button.png", data: "button"},
+	{name: "assets/ui/panel.png", data: "panel"},
+	{name: "assets/data/", flag: tar.TypeDir},
+	{name: "assets/data/items.yaml", data: "items: []"},
+	{name: "readme.txt", data: "hello"},
+}
+
+func TestTarFS(t *testing.T) {
+	for _, compressed := range []bool{false, true} {
+		name := "tar"
+		if compressed {
+			name = "tar.gz"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			tfs, err := NewTarFS(bytes.NewReader(buildTar(t, compressed, archiveFiles...)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := fstest.TestFS(tfs, "assets/ui/button.png", "assets/ui/panel.png", "assets/data/items.yaml", "readme.txt"); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestZipFS(t *testing.T) {
+	zfs, err := NewZipFS(buildZip(t, map[string]string{
+		"assets/ui/button.png":   "button",
+		"assets/ui/panel.png":    "panel",
+		"assets/data/items.yaml": "items: []",
+		"readme.txt":             "hello",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fstest.TestFS(zfs, "assets/ui/button.png", "assets/ui/panel.png", "assets/data/items.yaml", "readme.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTarFSLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		want    map[string]string
+		err     error
+	}{
+		{
+			name: "hard link",
+			entries: []tarEntry{
+				{name: "a/one.txt", data: "one"},
+				{name: "b/two.txt", link: "a/one.txt", flag: tar.TypeLink},
+			},
+			want: map[string]string{"a/one.txt": "one", "b/two.txt": "one"},
+		},
+		{
+			name: "symbolic link",
+			entries: []tarEntry{
+				{name: "a/one.txt", data: "one"},
+				{name: "b/two.txt", link: "../a/one.txt", flag: tar.TypeSymlink},
+				{name: "b/three.txt", link: "two.txt", flag: tar.TypeSymlink},
+			},
+			want: map[string]string{"a/one.txt": "one", "b/two.txt": "one", "b/three.txt": "one"},
+		},
+		{
+			name: "link replaced by a file",
+			entries: []tarEntry{
+				{name: "a.txt", data: "a"},
+				{name: "b.txt", link: "a.txt", flag: tar.TypeSymlink},
+				{name: "b.txt", data: "b"},
+			},
+			want: map[string]string{"a.txt": "a", "b.txt": "b"},
+		},
+		{
+			name:    "link outside the archive",
+			entries: []tarEntry{{name: "a/b.txt", link: "../../etc/passwd", flag: tar.TypeSymlink}},
+			err:     ErrTarInvalidLink,
+		},
+		{
+			name:    "absolute link",
+			entries: []tarEntry{{name: "b.txt", link: "/etc/passwd", flag: tar.TypeSymlink}},
+			err:     ErrTarInvalidLink,
+		},
+		{
+			name: "link to a directory",
+			entries: []tarEntry{
+				{name: "a/one.txt", data: "one"},
+				{name: "b", link: "a", flag: tar.TypeSymlink},
+			},
+			err: ErrTarInvalidLink,
+		},
+		{
+			name:    "missing target",
+			entries: []tarEntry{{name: "b.txt", link: "a.txt", flag: tar.TypeLink}},
+			err:     ErrTarInvalidLink,
+		},
+		{
+			name: "link loop",
+			entries: []tarEntry{
+				{name: "a.txt", link: "b.txt", flag: tar.TypeSymlink},
+				{name: "b.txt", link: "a.txt", flag: tar.TypeSymlink},
+			},
+			err: ErrTarInvalidLink,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tfs, err := NewTarFS(bytes.NewReader(buildTar(t, false, tt.entries...)))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("NewTarFS() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+
+			for name, want := range tt.want {
+				data, err := fs.ReadFile(tfs, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != want {
+					t.Errorf("%s = %q, want %q", name, data, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTarFSTooLarge(t *testing.T) {
+	// The header alone declares the size, so the archive is rejected before its contents are read.
+	var buf bytes.Buffer
+	if err := tar.NewWriter(&buf).WriteHeader(&tar.Header{Name: "big.bin", Typeflag: tar.TypeReg, Size: MaxTarSize + 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewTarFS(&buf); !errors.Is(err, ErrTarTooLarge) {
+		t.Fatalf("NewTarFS() error = %v, want %v", err, ErrTarTooLarge)
+	}
+
+	if _, err := NewTarFS(bytes.NewReader([]byte("not a tar archive"))); err == nil {
+		t.Fatal("NewTarFS() of invalid data succeeded")
+	}
+}
@@ -0,0 +1,20 @@
+package fsys
+
+import (
+	"archive/zip"
+	"bytes"
+)
+
+// OpenZip opens the zip archive at the given path as an fs.FS. Close it once it is no longer read.
+func OpenZip(name string) (*zip.ReadCloser, error) {
+	return zip.OpenReader(name)
+}
+
+// NewZipFS reads a zip archive held in memory, such as an embedded byte slice, as an fs.FS.
+func NewZipFS(data []byte) (*zip.Reader, error) {
+	return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+}
+
+func MustOpenZip(name string) *zip.ReadCloser {
+	return MustGet(OpenZip(name))
+}
@@ -0,0 +1,212 @@
+package httpfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adm87/finch-core/fsys"
+)
+
+const (
+	cacheBodyExtension = ".body"
+	cacheMetaExtension = ".json"
+)
+
+// ======================================================
+// Cache
+// ======================================================
+
+// cacheEntry records a cached response. On disk, each entry is stored as a body file and a metadata
+// file named after the hash of the file name, so remote paths never map outside the cache directory.
+type cacheEntry struct {
+	Name         string    `json:"name"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ModTime      time.Time `json:"mod_time"`
+	Size         int64     `json:"size"`
+	Hash         string    `json:"hash"`
+
+	validated time.Time
+	verified  bool
+	data      []byte
+}
+
+func (e *cacheEntry) info() fileInfo {
+	return fileInfo{name: e.Name, size: e.Size, modTime: e.ModTime}
+}
+
+// ClearCache removes every cached file, from memory and from the cache directory.
+func (f *FS) ClearCache() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.cache = make(map[string]*cacheEntry)
+
+	if f.options.CacheDir == "" {
+		return nil
+	}
+
+	errs := make([]error, 0)
+	for _, pattern := range []string{"*" + cacheBodyExtension, "*" + cacheMetaExtension} {
+		matches, err := filepath.Glob(filepath.Join(f.options.CacheDir, pattern))
+		if err != nil {
+			return err
+		}
+		for _, match := range matches {
+			if err := os.Remove(match); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// cachedEntry returns the cache entry of a file without reading its contents, or nil if it is not cached.
+func (f *FS) cachedEntry(name string) *cacheEntry {
+	f.mu.Lock()
+	entry, exists := f.cache[name]
+	f.mu.Unlock()
+
+	if exists {
+		return entry
+	}
+	return f.readCacheMeta(name)
+}
+
+// cached returns the cache entry and contents of a file, or nil if it is not cached or its cached
+// contents are missing or corrupted.
+//
+// Contents are hashed once, when they are stored or first read from the cache directory. After that
+// only their size is checked against the entry.
+func (f *FS) cached(name string) (*cacheEntry, []byte) {
+	entry := f.cachedEntry(name)
+	if entry == nil {
+		return nil, nil
+	}
+
+	data := entry.data
+	if f.options.CacheDir != "" {
+		var err error
+		if data, err = os.ReadFile(f.cachePath(name, cacheBodyExtension)); err != nil {
+			return nil, nil
+		}
+	}
+
+	if int64(len(data)) != entry.Size {
+		return nil, nil
+	}
+
+	if !entry.verified {
+		if hashData(data) != entry.Hash {
+			return nil, nil
+		}
+
+		verified := *entry
+		verified.verified = true
+		entry = &verified
+
+		f.mu.Lock()
+		f.cache[name] = entry
+		f.mu.Unlock()
+	}
+
+	return entry, data
+}
+
+// revalidate records that the cached copy of a file is still current, and returns its updated entry.
+func (f *FS) revalidate(cached *cacheEntry, header http.Header) *cacheEntry {
+	// Cache entries are shared with concurrent fetches, so they are replaced instead of updated.
+	revalidated := *cached
+	revalidated.validated = time.Now()
+	if etag := header.Get("ETag"); etag != "" {
+		revalidated.ETag = etag
+	}
+
+	f.mu.Lock()
+	f.cache[cached.Name] = &revalidated
+	f.mu.Unlock()
+
+	return &revalidated
+}
+
+// store caches the contents of a file, whose hash the entry holds. The body is written before the
+// metadata, and both are written to a temporary file first, so an interrupted write never leaves an
+// entry with the wrong contents.
+func (f *FS) store(entry *cacheEntry, data []byte) error {
+	entry.verified = true
+
+	if f.options.CacheDir == "" {
+		entry.data = data
+	} else {
+		meta, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if err := fsys.WriteFileAtomic(f.cachePath(entry.Name, cacheBodyExtension), data, 0o644); err != nil {
+			return err
+		}
+		if err := fsys.WriteFileAtomic(f.cachePath(entry.Name, cacheMetaExtension), meta, 0o644); err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	f.cache[entry.Name] = entry
+	f.mu.Unlock()
+
+	return nil
+}
+
+// remove drops the cached copy of a file that no longer exists on the server.
+func (f *FS) remove(name string) {
+	f.mu.Lock()
+	delete(f.cache, name)
+	f.mu.Unlock()
+
+	if f.options.CacheDir != "" {
+		os.Remove(f.cachePath(name, cacheMetaExtension))
+		os.Remove(f.cachePath(name, cacheBodyExtension))
+	}
+}
+
+func (f *FS) readCacheMeta(name string) *cacheEntry {
+	if f.options.CacheDir == "" {
+		return nil
+	}
+
+	meta, err := os.ReadFile(f.cachePath(name, cacheMetaExtension))
+	if err != nil {
+		return nil
+	}
+
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(meta, entry); err != nil || entry.Name != name {
+		return nil
+	}
+
+	return entry
+}
+
+func (f *FS) cachePath(name, extension string) string {
+	return filepath.Join(f.options.CacheDir, hashData([]byte(name))+extension)
+}
+
+func ensureCacheDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	return os.MkdirAll(dir, 0o755)
+}
+
+func hashData(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,449 @@
+// Package httpfs implements an fs.FS that reads files from an HTTP server, so a remote asset root can
+// be registered like any other filesystem.
+//
+//	remote, err := httpfs.New("https://cdn.example.com/game/assets", httpfs.Options{
+//		CacheDir: filepath.Join(userCacheDir, "assets"),
+//		Timeout:  10 * time.Second,
+//		Retries:  3,
+//	})
+//	finch.RegisterAssetFilesystem("assets", remote)
+//
+// Files are requested with GET relative to the base URL. Responses are cached, on disk when a cache
+// directory is set and in memory otherwise, and revalidated with If-None-Match and If-Modified-Since,
+// so unchanged files cost a 304 response. Stat sends a HEAD request instead, or answers from the cache
+// while it is fresh, so polling files for changes does not download them. An open file serves its
+// stat and contents from the response that opened it. HTTP servers cannot list directories, so
+// ReadDir, and glob patterns that rely on it, are not supported.
+package httpfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidURL = errors.New("httpfs base URL is invalid")
+)
+
+// ======================================================
+// Options
+// ======================================================
+
+// Options configures the requests and cache of a filesystem.
+type Options struct {
+	// Client sends the requests. Nil uses http.DefaultClient.
+	Client *http.Client
+
+	// Context bounds every request. Cancelling it aborts requests in flight and fails later ones.
+	// Nil uses context.Background.
+	Context context.Context
+
+	// Header is added to every request, for example to authenticate.
+	Header http.Header
+
+	// Timeout limits each attempt of a request. Zero leaves attempts bounded by Context and Client only.
+	Timeout time.Duration
+
+	// Retries is the number of times a request is retried after a network error or a 408, 429 or 5xx response.
+	Retries int
+
+	// RetryDelay is the delay before the first retry, doubled for every retry after it. Zero uses 250ms.
+	RetryDelay time.Duration
+
+	// CacheDir is the directory responses are cached in. Empty caches responses in memory.
+	CacheDir string
+
+	// MaxAge is how long a cached file is served without revalidating it. Zero revalidates every
+	// Open, Stat and ReadFile.
+	MaxAge time.Duration
+
+	// StaleIfError serves the cached copy of a file when it cannot be revalidated, so a game keeps
+	// working offline with the files it has already downloaded.
+	StaleIfError bool
+}
+
+// ======================================================
+// Status Error
+// ======================================================
+
+// StatusError reports a response with an unexpected status code.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// ======================================================
+// FS
+// ======================================================
+
+// FS is a read-only filesystem of the files under a base URL. It implements fs.FS, fs.ReadFileFS and
+// fs.StatFS, and is safe for concurrent use.
+type FS struct {
+	base    *url.URL
+	options Options
+
+	mu    sync.Mutex
+	cache map[string]*cacheEntry
+}
+
+// New returns a filesystem of the files under a base URL, which must be http or https.
+func New(baseURL string, options Options) (*FS, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+	if (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURL, baseURL)
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/")
+	base.RawPath = ""
+
+	if options.Client == nil {
+		options.Client = http.DefaultClient
+	}
+	if options.Context == nil {
+		options.Context = context.Background()
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = 250 * time.Millisecond
+	}
+	options.Retries = max(options.Retries, 0)
+
+	if err := ensureCacheDir(options.CacheDir); err != nil {
+		return nil, err
+	}
+
+	return &FS{
+		base:    base,
+		options: options,
+		cache:   make(map[string]*cacheEntry),
+	}, nil
+}
+
+func MustNew(baseURL string, options Options) *FS {
+	f, err := New(baseURL, options)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// URL returns the URL a file is requested from.
+func (f *FS) URL(name string) string {
+	u := *f.base
+	u.Path = f.base.Path + "/" + name
+	return u.String()
+}
+
+func (f *FS) Open(name string) (fs.File, error) {
+	entry, data, err := f.fetch("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return &file{Reader: bytes.NewReader(data), info: entry.info()}, nil
+}
+
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	_, data, err := f.fetch("read", name)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Clone(data), nil
+}
+
+// Stat returns the info of a file from its cached copy while it is fresh, and otherwise from the
+// headers of a HEAD request. The file is downloaded only when the server answers HEAD without a
+// Last-Modified header or a known ETag, since only its contents then tell whether it changed.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+
+	cached := f.cachedEntry(name)
+	if cached != nil && f.isFresh(cached) {
+		return cached.info(), nil
+	}
+
+	res, err := f.request(http.MethodHead, name, cached)
+	if code := statusCode(err); code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented {
+		return f.statBody(name)
+	}
+	if err != nil {
+		if cached != nil && f.options.StaleIfError && !isAnswered(err) {
+			return cached.info(), nil
+		}
+		return nil, f.requestError("stat", name, err)
+	}
+
+	if res.status == http.StatusNotModified || (cached != nil && cached.matches(res.header)) {
+		return f.revalidate(cached, res.header).info(), nil
+	}
+
+	modTime, err := http.ParseTime(res.header.Get("Last-Modified"))
+	if err != nil || res.size < 0 {
+		return f.statBody(name)
+	}
+
+	return fileInfo{name: name, size: res.size, modTime: modTime}, nil
+}
+
+// statBody returns the info of a file by fetching it.
+func (f *FS) statBody(name string) (fs.FileInfo, error) {
+	entry, _, err := f.fetch("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return entry.info(), nil
+}
+
+// ReadDir is not supported, since HTTP servers cannot list directories.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.ErrUnsupported}
+}
+
+// fetch returns the cache entry and contents of a file, revalidating or downloading it as needed.
+func (f *FS) fetch(op, name string) (*cacheEntry, []byte, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	cached, data := f.cached(name)
+	if cached != nil && f.isFresh(cached) {
+		return cached, data, nil
+	}
+
+	res, err := f.request(http.MethodGet, name, cached)
+	if err != nil {
+		if cached != nil && f.options.StaleIfError && !isAnswered(err) {
+			return cached, data, nil
+		}
+		return nil, nil, f.requestError(op, name, err)
+	}
+
+	if res.status == http.StatusNotModified {
+		return f.revalidate(cached, res.header), data, nil
+	}
+
+	now := time.Now()
+
+	entry := &cacheEntry{
+		Name:         name,
+		ETag:         res.header.Get("ETag"),
+		LastModified: res.header.Get("Last-Modified"),
+		Size:         int64(len(res.body)),
+		Hash:         hashData(res.body),
+		validated:    now,
+	}
+
+	// Without a Last-Modified header, the file is stamped with the time its contents were seen to
+	// change, so modification times still tell when a file was updated.
+	switch modTime, err := http.ParseTime(entry.LastModified); {
+	case err == nil:
+		entry.ModTime = modTime
+	case cached != nil && cached.Hash == entry.Hash:
+		entry.ModTime = cached.ModTime
+	default:
+		entry.ModTime = now
+	}
+
+	if err := f.store(entry, res.body); err != nil {
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	return entry, res.body, nil
+}
+
+// isFresh reports whether a cached file may be served without revalidating it.
+func (f *FS) isFresh(entry *cacheEntry) bool {
+	return f.options.MaxAge > 0 && time.Since(entry.validated) < f.options.MaxAge
+}
+
+// requestError maps a failed request for a file to the error of a filesystem operation. A file that
+// no longer exists on the server is dropped from the cache.
+func (f *FS) requestError(op, name string, err error) error {
+	switch statusCode(err) {
+	case http.StatusNotFound, http.StatusGone:
+		f.remove(name)
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &fs.PathError{Op: op, Path: name, Err: fmt.Errorf("%w: %w", fs.ErrPermission, err)}
+	default:
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+}
+
+// matches reports whether the validators of a response identify the cached copy of a file, for
+// servers that answer conditional HEAD requests with 200.
+func (e *cacheEntry) matches(header http.Header) bool {
+	if etag := header.Get("ETag"); etag != "" {
+		return etag == e.ETag
+	}
+	lastModified := header.Get("Last-Modified")
+	return lastModified != "" && lastModified == e.LastModified
+}
+
+// ======================================================
+// Requests
+// ======================================================
+
+// response is a successful response. The body is read for GET requests only, and size is the
+// Content-Length of HEAD requests, or -1 when the server did not send one.
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+	size   int64
+}
+
+// request sends a GET or HEAD request for a file, conditionally when a cached copy exists, retrying
+// failed attempts.
+func (f *FS) request(method, name string, cached *cacheEntry) (*response, error) {
+	delay := f.options.RetryDelay
+
+	for attempt := 0; ; attempt++ {
+		res, err := f.attempt(method, name, cached)
+		if err == nil {
+			return res, nil
+		}
+
+		if attempt >= f.options.Retries || !isRetryable(err) || f.options.Context.Err() != nil {
+			return nil, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-f.options.Context.Done():
+			timer.Stop()
+			return nil, f.options.Context.Err()
+		case <-timer.C:
+		}
+
+		delay *= 2
+	}
+}
+
+func (f *FS) attempt(method, name string, cached *cacheEntry) (*response, error) {
+	ctx := f.options.Context
+	if f.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.options.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, f.URL(name), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range f.options.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := f.options.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK && method == http.MethodHead:
+		return &response{status: resp.StatusCode, header: resp.Header, size: resp.ContentLength}, nil
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &response{status: resp.StatusCode, header: resp.Header, body: body}, nil
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return &response{status: resp.StatusCode, header: resp.Header}, nil
+	default:
+		io.Copy(io.Discard, resp.Body)
+		return nil, &StatusError{URL: req.URL.String(), StatusCode: resp.StatusCode}
+	}
+}
+
+// statusCode returns the status code of a response error, or 0 if the request failed without one.
+func statusCode(err error) int {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.StatusCode
+	}
+	return 0
+}
+
+// isAnswered reports whether a failed request was answered by the server with a status that a
+// cached copy must not hide, because the file is gone or may not be read.
+func isAnswered(err error) bool {
+	switch statusCode(err) {
+	case http.StatusNotFound, http.StatusGone, http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryable reports whether a failed attempt may succeed if it is made again.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var status *StatusError
+	if errors.As(err, &status) {
+		code := status.StatusCode
+		return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+	}
+
+	return true
+}
+
+// ======================================================
+// Files
+// ======================================================
+
+// fileInfo implements fs.FileInfo for a remote file.
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (i fileInfo) Name() string       { return path.Base(i.name) }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) Mode() fs.FileMode  { return 0o444 }
+func (i fileInfo) ModTime() time.Time { return i.modTime }
+func (i fileInfo) IsDir() bool        { return false }
+func (i fileInfo) Sys() any           { return nil }
+
+type file struct {
+	*bytes.Reader
+	info fileInfo
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Close() error               { return nil }
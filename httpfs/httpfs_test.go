@@ -0,0 +1,348 @@
+package httpfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// server serves files from a map and records the requests it receives.
+type server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	files    map[string]string
+	failures int // number of requests to answer with 503 before serving
+	noHead   bool
+	requests []string
+}
+
+var lastModified = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newServer(t *testing.T, files map[string]string) *server {
+	t.Helper()
+
+	s := &server{files: files}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conditional := ""
+	if r.Header.Get("If-None-Match") != "" {
+		conditional = " conditional"
+	}
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path+conditional)
+
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodHead && s.noHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, exists := s.files[r.URL.Path]
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	etag := `"` + hashData([]byte(body))[:16] + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	io.WriteString(w, body)
+}
+
+// take returns the requests received since the last call.
+func (s *server) take() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := s.requests
+	s.requests = nil
+	return requests
+}
+
+func (s *server) set(path, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.files[path] = body
+}
+
+func TestFSRequests(t *testing.T) {
+	type step struct {
+		op       string // "open", "read", "stat"
+		name     string
+		body     string
+		err      error
+		requests []string
+	}
+
+	tests := []struct {
+		name    string
+		options Options
+		noHead  bool
+		steps   []step
+	}{
+		{
+			name: "read then revalidate",
+			steps: []step{
+				{op: "read", name: "a.txt", body: "alpha", requests: []string{"GET /assets/a.txt"}},
+				{op: "read", name: "a.txt", body: "alpha", requests: []string{"GET /assets/a.txt conditional"}},
+			},
+		},
+		{
+			name:    "fresh cache",
+			options: Options{MaxAge: time.Hour},
+			steps: []step{
+				{op: "read", name: "a.txt", body: "alpha", requests: []string{"GET /assets/a.txt"}},
+				{op: "read", name: "a.txt", body: "alpha"},
+				{op: "stat", name: "a.txt"},
+			},
+		},
+		{
+			name: "open serves stat and contents from one response",
+			steps: []step{
+				{op: "open", name: "a.txt", body: "alpha", requests: []string{"GET /assets/a.txt"}},
+			},
+		},
+		{
+			name: "stat uses head",
+			steps: []step{
+				{op: "stat", name: "a.txt", requests: []string{"HEAD /assets/a.txt"}},
+				{op: "read", name: "a.txt", body: "alpha", requests: []string{"GET /assets/a.txt"}},
+				{op: "stat", name: "a.txt", requests: []string{"HEAD /assets/a.txt conditional"}},
+			},
+		},
+		{
+			name:   "stat without head support",
+			noHead: true,
+			steps: []step{
+				{op: "stat", name: "a.txt", requests: []string{"HEAD /assets/a.txt", "GET /assets/a.txt"}},
+			},
+		},
+		{
+			name: "not found",
+			steps: []step{
+				{op: "open", name: "missing.txt", err: fs.ErrNotExist, requests: []string{"GET /assets/missing.txt"}},
+				{op: "stat", name: "missing.txt", err: fs.ErrNotExist, requests: []string{"HEAD /assets/missing.txt"}},
+			},
+		},
+		{
+			name: "invalid path",
+			steps: []step{
+				{op: "open", name: "../a.txt", err: fs.ErrInvalid},
+				{op: "stat", name: ".", err: fs.ErrInvalid},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t, map[string]string{"/assets/a.txt": "alpha"})
+			srv.noHead = tt.noHead
+
+			f, err := New(srv.URL+"/assets", tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, step := range tt.steps {
+				var body []byte
+				var info fs.FileInfo
+				switch step.op {
+				case "open":
+					var file fs.File
+					if file, err = f.Open(step.name); err == nil {
+						if info, err = file.Stat(); err == nil {
+							body, err = io.ReadAll(file)
+						}
+						file.Close()
+					}
+				case "read":
+					body, err = f.ReadFile(step.name)
+				case "stat":
+					info, err = f.Stat(step.name)
+				}
+
+				if !errors.Is(err, step.err) {
+					t.Fatalf("step %d: %s %s error = %v, want %v", i, step.op, step.name, err, step.err)
+				}
+				if string(body) != step.body {
+					t.Fatalf("step %d: body = %q, want %q", i, body, step.body)
+				}
+				if info != nil && (info.Size() != 5 || !info.ModTime().Equal(lastModified)) {
+					t.Fatalf("step %d: info = %d bytes at %v", i, info.Size(), info.ModTime())
+				}
+				if requests := srv.take(); !slices.Equal(requests, step.requests) {
+					t.Fatalf("step %d: requests = %q, want %q", i, requests, step.requests)
+				}
+			}
+		})
+	}
+}
+
+func TestFSRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		retries  int
+		err      bool
+		requests int
+	}{
+		{name: "no failures", failures: 0, retries: 2, requests: 1},
+		{name: "recovers", failures: 2, retries: 2, requests: 3},
+		{name: "gives up", failures: 3, retries: 2, err: true, requests: 3},
+		{name: "no retries", failures: 1, retries: 0, err: true, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t, map[string]string{"/a.txt": "alpha"})
+			srv.failures = tt.failures
+
+			f := MustNew(srv.URL, Options{Retries: tt.retries, RetryDelay: time.Millisecond})
+
+			body, err := f.ReadFile("a.txt")
+			if (err != nil) != tt.err {
+				t.Fatalf("ReadFile() error = %v, want error %v", err, tt.err)
+			}
+			if !tt.err && string(body) != "alpha" {
+				t.Fatalf("ReadFile() = %q", body)
+			}
+			if requests := srv.take(); len(requests) != tt.requests {
+				t.Fatalf("requests = %q, want %d", requests, tt.requests)
+			}
+		})
+	}
+}
+
+func TestFSOffline(t *testing.T) {
+	tests := []struct {
+		name         string
+		staleIfError bool
+		cacheDir     bool
+		restart      bool // read through a new filesystem sharing the cache directory
+		err          bool
+	}{
+		{name: "memory cache", staleIfError: true},
+		{name: "disk cache", staleIfError: true, cacheDir: true},
+		{name: "disk cache after restart", staleIfError: true, cacheDir: true, restart: true},
+		{name: "stale not allowed", staleIfError: false, err: true},
+		{name: "nothing cached", staleIfError: true, restart: true, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t, map[string]string{"/a.txt": "alpha"})
+
+			options := Options{StaleIfError: tt.staleIfError}
+			if tt.cacheDir {
+				options.CacheDir = t.TempDir()
+			}
+
+			f := MustNew(srv.URL, options)
+			if _, err := f.ReadFile("a.txt"); err != nil {
+				t.Fatal(err)
+			}
+
+			srv.Close()
+			if tt.restart {
+				f = MustNew(srv.URL, options)
+			}
+
+			body, err := f.ReadFile("a.txt")
+			if (err != nil) != tt.err {
+				t.Fatalf("ReadFile() error = %v, want error %v", err, tt.err)
+			}
+			if !tt.err && string(body) != "alpha" {
+				t.Fatalf("ReadFile() = %q", body)
+			}
+
+			info, err := f.Stat("a.txt")
+			if (err != nil) != tt.err {
+				t.Fatalf("Stat() error = %v, want error %v", err, tt.err)
+			}
+			if !tt.err && info.Size() != 5 {
+				t.Fatalf("Stat() size = %d", info.Size())
+			}
+		})
+	}
+}
+
+func TestFSDiskCacheCorruption(t *testing.T) {
+	srv := newServer(t, map[string]string{"/a.txt": "alpha"})
+	options := Options{CacheDir: t.TempDir(), StaleIfError: true}
+
+	if _, err := MustNew(srv.URL, options).ReadFile("a.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	f := MustNew(srv.URL, options)
+	if err := os.WriteFile(filepath.Join(options.CacheDir, hashData([]byte("a.txt"))+cacheBodyExtension), []byte("omega"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	srv.take()
+
+	body, err := f.ReadFile("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "alpha" {
+		t.Fatalf("ReadFile() = %q", body)
+	}
+	if requests := srv.take(); !slices.Equal(requests, []string{"GET /a.txt"}) {
+		t.Fatalf("requests = %q, want an unconditional download", requests)
+	}
+}
+
+func TestFSChangedFile(t *testing.T) {
+	srv := newServer(t, map[string]string{"/a.txt": "alpha"})
+	f := MustNew(srv.URL, Options{})
+
+	if _, err := f.ReadFile("a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	srv.set("/a.txt", "omega!")
+
+	info, err := f.Stat("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 6 {
+		t.Fatalf("Stat() size = %d after change, want 6", info.Size())
+	}
+
+	body, err := f.ReadFile("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "omega!" {
+		t.Fatalf("ReadFile() = %q after change", body)
+	}
+}
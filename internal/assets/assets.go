@@ -0,0 +1,661 @@
+// Package assets is the asset runtime of finch: asset files and types, importers, filesystems and
+// layers, the cache, manifests, hashes and hot reload.
+//
+// It does not depend on ebiten, so the offline tools can load, hash and validate assets on machines
+// without cgo or a display. Games use it through the finch package, which re-exports its API.
+package assets
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/adm87/finch-core/hashset"
+	"github.com/adm87/finch-core/linq"
+)
+
+var (
+	ErrAssetManagerNotFound    = errors.New("asset manager not found")
+	ErrAssetManagerConflict    = errors.New("asset manager conflict")
+	ErrAssetManagerNil         = errors.New("asset manager is nil")
+	ErrAssetFilesystemConflict = errors.New("asset filesystem conflict")
+	ErrAssetFilesystemNil      = errors.New("asset filesystem is nil")
+	ErrAssetInvalidType        = errors.New("asset has invalid type")
+	ErrAssetTypeMismatch       = errors.New("asset type mismatch")
+	ErrAssetNotLoaded          = errors.New("asset not loaded")
+	ErrAssetIsLoaded           = errors.New("asset is already loaded")
+	ErrAssetIsLoading          = errors.New("asset is currently loading")
+	ErrAssetTypeEmpty          = errors.New("asset type is empty")
+	ErrAssetRootEmpty          = errors.New("asset root is empty")
+	ErrAssetLayerNotFound      = errors.New("asset filesystem layer not found")
+	ErrAssetPathEmpty          = errors.New("asset path is empty")
+	ErrAssetPathInvalid        = errors.New("asset path is invalid")
+	ErrAssetPathEscapesRoot    = errors.New("asset path escapes its root")
+)
+
+// ======================================================
+// Asset Importer
+// ======================================================
+
+// AssetImporter manages allocation and deallocation of a specific asset types.
+//
+// OutputType optionally declares the type of the data produced by ProcessAssetFile. When set,
+// AssetRef handles are checked against it. FallbackAsset optionally provides a placeholder that
+// GetAsset returns for files that failed to load.
+//
+// ProcessAssetStream optionally replaces ProcessAssetFile for importers of large files, such as long
+// music tracks, that read their file as they need it instead of all at once. See AssetStream.
+type AssetImporter struct {
+	ProcessAssetFile   AssetAllocator
+	ProcessAssetStream AssetStreamAllocator
+	CleanupAssetFile   AssetDeallocator
+	EstimateAssetSize  AssetSizeEstimator
+	FallbackAsset      AssetFallbackFunc
+	OutputType         reflect.Type
+	AssetTypes         []AssetType
+}
+
+// ======================================================
+// Asset Allocation/Deallocation
+// ======================================================
+
+// AssetAllocator is a function that takes raw asset data and converts it into a usable form.
+type AssetAllocator func(file AssetFile, filedata []byte) (any, error)
+
+// AssetDeallocator is a function that takes a loaded asset and frees its resources.
+type AssetDeallocator func(file AssetFile, data any) error
+
+// ======================================================
+// Asset Type
+// ======================================================
+
+// AssetType represents the type of an asset, typically derived from its file extension.
+//
+// Types are lower case and may span several extensions, as in "atlas.json".
+type AssetType string
+
+// NewAssetType returns an asset type written as a lower case extension, without its leading dot.
+func NewAssetType(t string) AssetType {
+	return AssetType(strings.ToLower(strings.TrimPrefix(strings.TrimSpace(t), ".")))
+}
+
+func (t AssetType) String() string {
+	return string(t)
+}
+
+func (t AssetType) IsValid() error {
+	if t == "" {
+		return ErrAssetTypeEmpty
+	}
+	return nil
+}
+
+// ======================================================
+// Asset Root
+// ======================================================
+
+// AssetRoot represents the root directory of an asset file.
+type AssetRoot string
+
+func (r AssetRoot) String() string {
+	return string(r)
+}
+
+func (r AssetRoot) IsValid() error {
+	if r == "" {
+		return ErrAssetRootEmpty
+	}
+	return nil
+}
+
+// ======================================================
+// Asset File
+// ======================================================
+
+// AssetFile is a handle to an asset file and its associated type and data.
+//
+// Asset files are paths relative to the working directory or an asset filesystem, whose first element
+// is their root, as in "assets/sprites/hero.png". Use NewAssetFile to build them from paths that may be
+// written in another form.
+type AssetFile string
+
+// NewAssetFile returns the canonical asset file of a path.
+//
+// Backslashes are replaced with slashes, and the path is cleaned of redundant separators, "." elements
+// and leading slashes. Paths that are empty, name a volume such as "C:", or use ".." to escape their root
+// are rejected. A fragment, as in "sprites.atlas#hero", is kept as is.
+func NewAssetFile(p string) (AssetFile, error) {
+	p, fragment, hasFragment := strings.Cut(p, "#")
+
+	p = strings.TrimSpace(strings.ReplaceAll(p, "\\", "/"))
+	if p == "" {
+		return "", &AssetError{Op: "resolve", Err: ErrAssetPathEmpty}
+	}
+
+	elements := strings.Split(p, "/")
+	depth := 0
+	for _, element := range elements {
+		switch element {
+		case "", ".":
+		case "..":
+			depth--
+			// Popping the first element would leave the root.
+			if depth <= 0 {
+				return "", &AssetError{Op: "resolve", File: AssetFile(p), Err: ErrAssetPathEscapesRoot}
+			}
+		default:
+			depth++
+		}
+	}
+
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" {
+		return "", &AssetError{Op: "resolve", Err: ErrAssetPathEmpty}
+	}
+	if root, _, _ := strings.Cut(p, "/"); strings.HasSuffix(root, ":") {
+		return "", &AssetError{Op: "resolve", File: AssetFile(p), Err: fmt.Errorf("%w: %s is a volume", ErrAssetPathInvalid, root)}
+	}
+
+	if hasFragment {
+		p += "#" + fragment
+	}
+
+	return AssetFile(p), nil
+}
+
+func MustNewAssetFile(p string) AssetFile {
+	file, err := NewAssetFile(p)
+	if err != nil {
+		panic(err)
+	}
+	return file
+}
+
+// Path returns the file path of the asset.
+func (f AssetFile) Path() string {
+	return string(f)
+}
+
+// Root returns the root directory of the asset file.
+func (f AssetFile) Root() AssetRoot {
+	root, _, _ := strings.Cut(f.cleanPath(), "/")
+	return AssetRoot(root)
+}
+
+// Base returns the asset file without its fragment.
+func (f AssetFile) Base() AssetFile {
+	base, _, _ := strings.Cut(f.Path(), "#")
+	return AssetFile(base)
+}
+
+// Fragment returns the name of the sub-asset the file refers to, as in "sprites.atlas#hero", or an
+// empty string if it refers to a whole asset.
+func (f AssetFile) Fragment() string {
+	_, fragment, _ := strings.Cut(f.Path(), "#")
+	return fragment
+}
+
+// Type returns the asset type based on the file extension, in lower case.
+//
+// Files with several extensions, as in "sprites.atlas.json", have the longest type an importer is
+// registered for, or their last extension otherwise. Files without an extension have an empty type.
+func (f AssetFile) Type() AssetType {
+	name := path.Base(f.cleanPath())
+	extensions := strings.Split(strings.TrimLeft(name, "."), ".")[1:]
+
+	assetImportersMu.RLock()
+	defer assetImportersMu.RUnlock()
+
+	for i := range extensions {
+		t := NewAssetType(strings.Join(extensions[i:], "."))
+		if _, exists := assetManagers[t]; exists || i == len(extensions)-1 {
+			return t
+		}
+	}
+
+	return ""
+}
+
+// cleanPath returns the path of the asset file without its fragment, in the form NewAssetFile returns,
+// so that files built by conversion, as in AssetFile("assets\\hero.png"), still resolve.
+func (f AssetFile) cleanPath() string {
+	fpath := strings.ReplaceAll(f.Base().Path(), "\\", "/")
+	return strings.TrimPrefix(path.Clean("/"+fpath), "/")
+}
+
+func (f AssetFile) Load() error {
+	return LoadAssets(f)
+}
+
+func (f AssetFile) MustLoad() {
+	if err := f.Load(); err != nil {
+		panic(err)
+	}
+}
+
+func (f AssetFile) Unload() error {
+	return UnloadAssets(f)
+}
+
+func (f AssetFile) MustUnload() {
+	if err := f.Unload(); err != nil {
+		panic(err)
+	}
+}
+
+func (f AssetFile) IsLoaded() bool {
+	return IsAssetLoaded(f)
+}
+
+func (f AssetFile) Get() (any, error) {
+	return GetAsset[any](f)
+}
+
+func (f AssetFile) MustGet() any {
+	data, err := f.Get()
+
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
+// ======================================================
+// Asset Management
+// ======================================================
+
+// assetEntry is the cached state of a loaded asset file.
+type assetEntry struct {
+	data     any
+	stream   AssetStream
+	modTime  time.Time
+	layer    string
+	size     int64
+	refs     int
+	lastUsed atomic.Uint64
+}
+
+var (
+	assetCache    = make(map[AssetFile]*assetEntry)
+	assetsLoading = hashset.New[AssetFile]()
+	assetsMu      = sync.RWMutex{}
+
+	// assetManagers is guarded by its own lock, since asset types are looked up while assetsMu is held.
+	assetManagers    = make(map[AssetType]*AssetImporter)
+	assetImportersMu = sync.RWMutex{}
+)
+
+func HasAssetTypeSupport(t AssetType) bool {
+	_, exists := getAssetImporter(NewAssetType(t.String()))
+	return exists
+}
+
+// getAssetImporter returns the importer registered for an asset type.
+func getAssetImporter(t AssetType) (*AssetImporter, bool) {
+	assetImportersMu.RLock()
+	defer assetImportersMu.RUnlock()
+
+	manager, exists := assetManagers[t]
+	return manager, exists
+}
+
+func RegisterAssetImporter(manager *AssetImporter) error {
+	if manager == nil {
+		return &AssetError{Op: "register", Err: ErrAssetManagerNil}
+	}
+
+	if err := checkAssetRefs(manager); err != nil {
+		return err
+	}
+
+	assetImportersMu.Lock()
+	defer assetImportersMu.Unlock()
+
+	for i, t := range manager.AssetTypes {
+		t = NewAssetType(t.String())
+		manager.AssetTypes[i] = t
+
+		if err := t.IsValid(); err != nil {
+			return &AssetError{Op: "register", Type: t, Err: fmt.Errorf("%w: %w", ErrAssetInvalidType, err)}
+		}
+
+		if _, exists := assetManagers[t]; exists {
+			return &AssetError{Op: "register", Type: t, Err: ErrAssetManagerConflict}
+		}
+
+		assetManagers[t] = manager
+	}
+
+	return nil
+}
+
+// RegisterAssetFilesystem registers the base filesystem of an asset root.
+//
+// The base layer is named after the root and mounted at priority 0. Use MountAssetFilesystem
+// to stack additional filesystems, such as DLC archives or mod folders, on top of it.
+func RegisterAssetFilesystem(root AssetRoot, filesystem fs.FS) error {
+	return MountAssetFilesystem(root, root.String(), 0, filesystem)
+}
+
+// RegisterAssetFilesystemMapped registers the base filesystem of an asset root, mapping asset paths into
+// it as the given mapping selects.
+func RegisterAssetFilesystemMapped(root AssetRoot, filesystem fs.FS, mapping AssetPathMapping) error {
+	return MountAssetFilesystemMapped(root, root.String(), 0, filesystem, mapping)
+}
+
+// IsAssetLoaded reports whether an asset file is loaded and has not been evicted.
+func IsAssetLoaded(file AssetFile) bool {
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+
+	_, loaded := assetCache[file]
+	return loaded
+}
+
+// GetAsset returns the typed data of a loaded asset.
+//
+// If the asset was evicted from the cache to stay within its memory budget, it is reloaded first.
+// If the asset failed to load and its type has a fallback, the fallback is returned instead.
+//
+// Files with a fragment return a sub-asset of their loaded base asset, provided its data implements AssetFragmenter.
+func GetAsset[T any](file AssetFile) (T, error) {
+	data, err := getAssetData(file)
+
+	if err != nil {
+		return *new(T), err
+	}
+
+	if typed, ok := data.(T); ok {
+		return typed, nil
+	}
+
+	return *new(T), NewAssetError("get", file, fmt.Errorf("%w: expected %s, got %T", ErrAssetTypeMismatch, reflect.TypeFor[T](), data))
+}
+
+func MustGetAsset[T any](file AssetFile) T {
+	data, err := GetAsset[T](file)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
+func LoadAssets(files ...AssetFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	requests := hashset.New[AssetFile]()
+	errs := make([]error, 0)
+
+	if err := buildAssetRequests(requests, files); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(requests) == 0 {
+		return errors.Join(errs...)
+	}
+
+	if err := loadAssetBatches(linq.Batch(requests.ToSlice(), 100)); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+func MustLoadAssets(files ...AssetFile) {
+	if err := LoadAssets(files...); err != nil {
+		panic(err)
+	}
+}
+
+func UnloadAssets(files ...AssetFile) error {
+	assetsMu.Lock()
+	unloaded, err := unloadAssetFiles(files)
+	assetsMu.Unlock()
+
+	for _, file := range unloaded {
+		emitAssetEvent(AssetEvent{Kind: AssetUnloaded, File: file})
+	}
+
+	return err
+}
+
+func MustUnloadAssets(files ...AssetFile) {
+	if err := UnloadAssets(files...); err != nil {
+		panic(err)
+	}
+}
+
+// unloadAssetFiles deallocates and removes assets from the cache, returning the files that were unloaded.
+//
+// The caller must hold the assets write lock.
+func unloadAssetFiles(files []AssetFile) ([]AssetFile, error) {
+	unloaded := make([]AssetFile, 0, len(files))
+
+	for _, file := range files {
+		if assetsEvicted.Contains(file) {
+			assetsEvicted.Remove(file)
+			delete(assetStamps, file)
+			continue
+		}
+
+		if _, failed := assetsFailed[file]; failed {
+			delete(assetsFailed, file)
+			continue
+		}
+
+		entry, exists := assetCache[file]
+		if !exists {
+			return unloaded, NewAssetError("unload", file, ErrAssetNotLoaded)
+		}
+
+		manager, exists := getAssetImporter(file.Type())
+		if !exists {
+			return unloaded, NewAssetError("unload", file, ErrAssetManagerNotFound)
+		}
+
+		if manager.CleanupAssetFile != nil {
+			if err := manager.CleanupAssetFile(file, entry.data); err != nil {
+				return unloaded, NewAssetError("unload", file, fmt.Errorf("%w: %w", ErrAssetCleanupFailed, err))
+			}
+		}
+
+		delete(assetCache, file)
+		delete(assetStamps, file)
+		releaseAssetDependencies(file)
+		unloaded = append(unloaded, file)
+
+		if err := closeAssetStream(entry.stream); err != nil {
+			return unloaded, NewAssetError("unload", file, err)
+		}
+	}
+
+	return unloaded, nil
+}
+
+func buildAssetRequests(requests hashset.Set[AssetFile], files []AssetFile) error {
+	errs := make([]error, 0)
+
+	for _, file := range files {
+		if _, exists := requests[file]; exists {
+			continue
+		}
+
+		fileType := file.Type()
+
+		if err := fileType.IsValid(); err != nil {
+			errs = append(errs, NewAssetError("load", file, fmt.Errorf("%w: %w", ErrAssetInvalidType, err)))
+			continue
+		}
+
+		if _, exists := getAssetImporter(fileType); !exists {
+			errs = append(errs, NewAssetError("load", file, ErrAssetManagerNotFound))
+			continue
+		}
+
+		requests.Add(file)
+	}
+
+	return errors.Join(errs...)
+}
+
+func loadAssetBatches(batches [][]AssetFile) error {
+	if len(batches) == 1 {
+		return loadAssetBatch(batches[0])
+	}
+
+	panicCh := make(chan error, len(batches))
+	wg := sync.WaitGroup{}
+
+	wg.Add(len(batches))
+	for _, batch := range batches {
+		go func(files []AssetFile) {
+			defer wg.Done()
+
+			defer func() {
+				if r := recover(); r != nil {
+					panicCh <- &AssetError{Op: "load", Err: fmt.Errorf("%w: %v", ErrAssetLoadPanicked, r)}
+				}
+			}()
+
+			if err := loadAssetBatch(files); err != nil {
+				panicCh <- err
+			}
+		}(batch)
+	}
+	wg.Wait()
+
+	close(panicCh)
+
+	errs := make([]error, 0, len(panicCh))
+	for err := range panicCh {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func loadAssetBatch(files []AssetFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0)
+
+	// Note: Errors don't interrupt loading subsequent assets.
+	// Instead all errors are returned to be handled upstream.
+	for _, file := range files {
+		if err := loadAssetFile(file); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func loadAssetFile(file AssetFile) error {
+	if err := tryLoad(file); err != nil {
+		return err
+	}
+
+	defer func() {
+		assetsMu.Lock()
+		assetsLoading.Remove(file)
+		assetsLoadingDone.Broadcast()
+		assetsMu.Unlock()
+	}()
+
+	emitAssetEvent(AssetEvent{Kind: AssetLoadStarted, File: file})
+
+	start := time.Now()
+
+	size, err := importAssetFile(file)
+	if err != nil {
+		err = NewAssetError("load", file, err)
+		recordAssetFailure(file, err)
+		emitAssetEvent(AssetEvent{Kind: AssetLoadFailed, File: file, Duration: time.Since(start), Err: err})
+		return err
+	}
+
+	recordAssetFailure(file, nil)
+	emitAssetEvent(AssetEvent{Kind: AssetLoaded, File: file, Duration: time.Since(start), Bytes: size})
+
+	return evictOverBudget()
+}
+
+// importAssetFile reads and processes an asset file and adds it to the cache, returning the number of bytes read.
+//
+// Errors are returned unwrapped, for the caller to wrap in an AssetError.
+func importAssetFile(file AssetFile) (int64, error) {
+	src, err := openAssetFile(file)
+	if err != nil {
+		return 0, err
+	}
+	defer src.close()
+
+	info, err := src.stat()
+	if err != nil {
+		return 0, err
+	}
+
+	manager, exists := getAssetImporter(file.Type())
+	if !exists {
+		return 0, ErrAssetManagerNotFound
+	}
+
+	imported, err := processAssetSource(manager, file, src)
+	if err != nil {
+		// The importer may have retained dependencies before failing.
+		assetsMu.Lock()
+		releaseAssetDependencies(file)
+		assetsMu.Unlock()
+		return 0, err
+	}
+
+	entry := &assetEntry{
+		data:    imported.data,
+		stream:  imported.stream,
+		modTime: info.ModTime(),
+		layer:   src.layer,
+		size:    imported.size,
+	}
+	entry.touch()
+
+	assetsMu.Lock()
+	assetCache[file] = entry
+	assetStamps[file] = imported.stamp
+	assetsEvicted.Remove(file)
+	assetsMu.Unlock()
+
+	return imported.stamp.Size, nil
+}
+
+func tryLoad(file AssetFile) error {
+	assetsMu.Lock()
+	defer assetsMu.Unlock()
+
+	if _, exists := assetCache[file]; exists {
+		return NewAssetError("load", file, ErrAssetIsLoaded)
+	}
+	if assetsLoading.Contains(file) {
+		return NewAssetError("load", file, ErrAssetIsLoading)
+	}
+
+	assetsLoading.Add(file)
+
+	return nil
+}
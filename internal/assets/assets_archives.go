@@ -0,0 +1,122 @@
+package assets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/adm87/finch-core/archive"
+	"github.com/adm87/finch-core/fsys"
+)
+
+var (
+	ErrAssetArchiveUnsupported = errors.New("asset archive format is unsupported")
+)
+
+// ======================================================
+// Asset Archives
+// ======================================================
+
+// MountAssetArchive opens an archive file and mounts it as a named filesystem layer of an asset root,
+// as MountAssetFilesystem does. The format is selected by the file extension, which must be .zip, .tar,
+// .tar.gz, .tgz or .farc. The archive is closed when the layer is unmounted.
+//
+//	finch.MustMountAssetArchive("assets", "mod:better-ui", 20, "mods/better-ui.zip")
+func MountAssetArchive(root AssetRoot, layer string, priority int, name string) error {
+	filesystem, closer, err := openAssetArchive(name, nil)
+	if err != nil {
+		return &AssetError{Op: "mount", Err: fmt.Errorf("%s: %s: %w", root, layer, err)}
+	}
+
+	err = mountAssetLayer(root, &assetLayer{name: layer, priority: priority, fs: filesystem, closer: closer})
+	if err != nil && closer != nil {
+		closer.Close()
+	}
+	return err
+}
+
+func MustMountAssetArchive(root AssetRoot, layer string, priority int, name string) {
+	if err := MountAssetArchive(root, layer, priority, name); err != nil {
+		panic(err)
+	}
+}
+
+// MountAssetArchiveData mounts an archive held in memory, such as an embedded byte slice, as a named
+// filesystem layer of an asset root. The format is selected by the extension of name, as in MountAssetArchive.
+func MountAssetArchiveData(root AssetRoot, layer string, priority int, name string, data []byte) error {
+	filesystem, _, err := openAssetArchive(name, data)
+	if err != nil {
+		return &AssetError{Op: "mount", Err: fmt.Errorf("%s: %s: %w", root, layer, err)}
+	}
+
+	return mountAssetLayer(root, &assetLayer{name: layer, priority: priority, fs: filesystem})
+}
+
+func MustMountAssetArchiveData(root AssetRoot, layer string, priority int, name string, data []byte) {
+	if err := MountAssetArchiveData(root, layer, priority, name, data); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterAssetArchive opens an archive file and registers it as the base filesystem of an asset root.
+func RegisterAssetArchive(root AssetRoot, name string) error {
+	return MountAssetArchive(root, root.String(), 0, name)
+}
+
+func MustRegisterAssetArchive(root AssetRoot, name string) {
+	if err := RegisterAssetArchive(root, name); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterAssetArchiveData registers an archive held in memory as the base filesystem of an asset root.
+func RegisterAssetArchiveData(root AssetRoot, name string, data []byte) error {
+	return MountAssetArchiveData(root, root.String(), 0, name, data)
+}
+
+func MustRegisterAssetArchiveData(root AssetRoot, name string, data []byte) {
+	if err := RegisterAssetArchiveData(root, name, data); err != nil {
+		panic(err)
+	}
+}
+
+// openAssetArchive opens the archive file at name, or reads it from data if data is not nil, returning
+// the closer of the opened file if any.
+func openAssetArchive(name string, data []byte) (fs.FS, io.Closer, error) {
+	lower := strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		if data != nil {
+			r, err := fsys.NewZipFS(data)
+			return r, nil, err
+		}
+		r, err := fsys.OpenZip(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		return r, r, nil
+	case strings.HasSuffix(lower, ".tar"), strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		if data != nil {
+			t, err := fsys.NewTarFS(bytes.NewReader(data))
+			return t, nil, err
+		}
+		t, err := fsys.OpenTar(name)
+		return t, nil, err
+	case strings.HasSuffix(lower, archive.Extension):
+		if data != nil {
+			a, err := archive.NewReader(bytes.NewReader(data), int64(len(data)))
+			return a, nil, err
+		}
+		a, err := archive.Open(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		return a, a, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrAssetArchiveUnsupported, name)
+	}
+}
@@ -0,0 +1,199 @@
+package assets
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func tarGzArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestMountAssetArchiveData(t *testing.T) {
+	tests := []struct {
+		name string
+		data func(t *testing.T) []byte
+	}{
+		{name: "mod.zip", data: func(t *testing.T) []byte { return zipArchive(t, map[string]string{"ui/a.txt": "zip"}) }},
+		{name: "mod.ZIP", data: func(t *testing.T) []byte { return zipArchive(t, map[string]string{"assets/ui/a.txt": "zip"}) }},
+		{name: "mod.tar.gz", data: func(t *testing.T) []byte { return tarGzArchive(t, map[string]string{"ui/a.txt": "tar"}) }},
+		{name: "mod.tgz", data: func(t *testing.T) []byte { return tarGzArchive(t, map[string]string{"assets/ui/a.txt": "tar"}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAssets(t)
+			textImporter(t, "txt")
+			mapFS(t, fstest.MapFS{"ui/a.txt": {Data: []byte("base")}, "ui/b.txt": {Data: []byte("base")}})
+
+			data := tt.data(t)
+			if err := MountAssetArchiveData("assets", "mod", 1, tt.name, data); err != nil {
+				t.Fatal(err)
+			}
+			if err := LoadAssets("assets/ui/a.txt", "assets/ui/b.txt"); err != nil {
+				t.Fatal(err)
+			}
+
+			for file, layer := range map[AssetFile]string{"assets/ui/a.txt": "mod", "assets/ui/b.txt": "assets"} {
+				if got, _ := AssetFileLayer(file); got != layer {
+					t.Errorf("%s served by layer %q, want %q", file, got, layer)
+				}
+			}
+			if got := MustGetAsset[string]("assets/ui/a.txt"); got == "base" {
+				t.Errorf("assets/ui/a.txt = %q, want the archive's file", got)
+			}
+		})
+	}
+}
+
+func TestMountAssetArchive(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, "txt")
+
+	name := filepath.Join(t.TempDir(), "mod.zip")
+	if err := os.WriteFile(name, zipArchive(t, map[string]string{"assets/a.txt": "zip"}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MountAssetArchive("assets", "mod", 0, name); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ReadAssetFile("assets/a.txt"); err != nil || string(data) != "zip" {
+		t.Fatalf("ReadAssetFile() = %q, %v, want the archive's file", data, err)
+	}
+
+	if err := UnmountAssetFilesystem("assets", "mod"); err != nil {
+		t.Fatal(err)
+	}
+	if layers := AssetFilesystemLayers("assets"); len(layers) != 0 {
+		t.Fatalf("layers after unmount = %v, want none", layers)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "mod.rar", err: ErrAssetArchiveUnsupported},
+		{name: filepath.Join(t.TempDir(), "missing.zip"), err: fs.ErrNotExist},
+	}
+
+	for _, tt := range tests {
+		err := MountAssetArchive("assets", "mod", 0, tt.name)
+
+		var assetErr *AssetError
+		if !errors.Is(err, tt.err) || !errors.As(err, &assetErr) {
+			t.Errorf("MountAssetArchive(%s) error = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+	if layers := AssetFilesystemLayers("assets"); len(layers) != 0 {
+		t.Fatalf("layers after failed mounts = %v, want none", layers)
+	}
+}
+
+func TestAssetPathMapping(t *testing.T) {
+	relative := fstest.MapFS{"ui/a.txt": {Data: []byte("relative")}}
+	rooted := fstest.MapFS{"assets/ui/a.txt": {Data: []byte("rooted")}}
+
+	tests := []struct {
+		name    string
+		fs      fstest.MapFS
+		mapping AssetPathMapping
+		want    string
+		err     error
+	}{
+		{name: "detect relative", fs: relative, mapping: AssetPathDetect, want: "relative"},
+		{name: "detect rooted", fs: rooted, mapping: AssetPathDetect, want: "rooted"},
+		{name: "relative", fs: relative, mapping: AssetPathRelative, want: "relative"},
+		{name: "rooted", fs: rooted, mapping: AssetPathRooted, want: "rooted"},
+		{name: "relative of rooted", fs: rooted, mapping: AssetPathRelative, err: fs.ErrNotExist},
+		{name: "rooted of relative", fs: relative, mapping: AssetPathRooted, err: fs.ErrNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAssets(t)
+
+			if err := MountAssetFilesystemMapped("assets", "base", 0, tt.fs, tt.mapping); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := ReadAssetFile("assets/ui/a.txt")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ReadAssetFile() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && string(data) != tt.want {
+				t.Fatalf("ReadAssetFile() = %q, want %q", data, tt.want)
+			}
+			if err != nil {
+				return
+			}
+
+			// Patterns match within the filesystem and map back to asset files.
+			files, err := (&AssetManifest{Groups: map[string][]string{"ui": {"assets/ui/*.txt"}}}).Resolve("ui")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []AssetFile{"assets/ui/a.txt"}; !slices.Equal(files, want) {
+				t.Fatalf("Resolve() = %v, want %v", files, want)
+			}
+		})
+	}
+
+	resetAssets(t)
+	err := MountAssetFilesystemMapped("assets", "base", 0, relative, AssetPathRooted+1)
+	if !errors.Is(err, ErrAssetPathMappingInvalid) {
+		t.Fatalf("MountAssetFilesystemMapped() error = %v, want %v", err, ErrAssetPathMappingInvalid)
+	}
+}
@@ -0,0 +1,331 @@
+package assets
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+
+	"github.com/adm87/finch-core/hashset"
+)
+
+var (
+	ErrAssetEvicted       = errors.New("asset was evicted")
+	ErrAssetNotRetained   = errors.New("asset is not retained")
+	ErrAssetBudgetInvalid = errors.New("asset memory budget is negative")
+)
+
+// ======================================================
+// Asset Size Estimation
+// ======================================================
+
+// AssetSizeEstimator returns the approximate number of bytes of memory used by a loaded asset.
+type AssetSizeEstimator func(file AssetFile, data any) int64
+
+// ======================================================
+// Asset Cache Options
+// ======================================================
+
+// AssetEvictedReloadFunc is called when an asset evicted from the cache has been reloaded
+// in the background, or has failed to reload.
+type AssetEvictedReloadFunc func(file AssetFile, data any, err error)
+
+// AssetCacheOptions configures the memory budget of the asset cache.
+//
+// When a budget is exceeded, the least recently used assets that are not retained are
+// evicted until the cache fits again. Evicted assets reload transparently the next time
+// they are requested through GetAsset.
+type AssetCacheOptions struct {
+	// Budget is the total number of bytes loaded assets may use. Zero means unlimited.
+	Budget int64
+
+	// TypeBudgets limits the number of bytes used by assets of individual types. Zero means unlimited.
+	// Types are case-insensitive and may be written with a leading dot.
+	TypeBudgets map[AssetType]int64
+
+	// AsyncReload reloads evicted assets in the background instead of blocking GetAsset.
+	// While the reload is in progress GetAsset returns ErrAssetEvicted.
+	AsyncReload bool
+
+	// OnEvictedReload is called when a background reload of an evicted asset completes.
+	OnEvictedReload AssetEvictedReloadFunc
+}
+
+// AssetCacheStats reports the state of the asset cache.
+type AssetCacheStats struct {
+	Loaded    int
+	Evicted   int
+	Bytes     int64
+	TypeBytes map[AssetType]int64
+	Evictions uint64
+	Reloads   uint64
+}
+
+var (
+	assetCacheOptions = AssetCacheOptions{}
+	assetsEvicted     = hashset.New[AssetFile]()
+	assetClock        = atomic.Uint64{}
+	assetEvictions    = atomic.Uint64{}
+	assetReloads      = atomic.Uint64{}
+)
+
+// ConfigureAssetCache sets the memory budget of the asset cache and evicts assets that no longer fit.
+func ConfigureAssetCache(options AssetCacheOptions) error {
+	if options.Budget < 0 {
+		return &AssetError{Op: "configure", Err: fmt.Errorf("%w: %d", ErrAssetBudgetInvalid, options.Budget)}
+	}
+
+	// Types are normalized as RegisterAssetImporter does, so "PNG" and ".png" budget png files. Types
+	// listed in several forms get the smallest of their budgets.
+	typeBudgets := make(map[AssetType]int64, len(options.TypeBudgets))
+	for t, budget := range options.TypeBudgets {
+		t = NewAssetType(t.String())
+		if err := t.IsValid(); err != nil {
+			return &AssetError{Op: "configure", Err: fmt.Errorf("%w: %w", ErrAssetInvalidType, err)}
+		}
+		if budget < 0 {
+			return &AssetError{Op: "configure", Type: t, Err: fmt.Errorf("%w: %d", ErrAssetBudgetInvalid, budget)}
+		}
+		if existing, exists := typeBudgets[t]; exists && existing != 0 && (budget == 0 || existing < budget) {
+			continue
+		}
+		typeBudgets[t] = budget
+	}
+	options.TypeBudgets = typeBudgets
+
+	assetsMu.Lock()
+	assetCacheOptions = options
+	assetsMu.Unlock()
+
+	return evictOverBudget()
+}
+
+// GetAssetCacheStats returns the current size of the asset cache and its eviction counters.
+func GetAssetCacheStats() AssetCacheStats {
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+
+	stats := AssetCacheStats{
+		Loaded:    len(assetCache),
+		Evicted:   len(assetsEvicted),
+		TypeBytes: make(map[AssetType]int64),
+		Evictions: assetEvictions.Load(),
+		Reloads:   assetReloads.Load(),
+	}
+
+	for file, entry := range assetCache {
+		stats.Bytes += entry.size
+		stats.TypeBytes[file.Type()] += entry.size
+	}
+
+	return stats
+}
+
+// RetainAsset marks a loaded asset as referenced, preventing it from being evicted.
+//
+// Every call must be balanced by a call to ReleaseAsset.
+func RetainAsset(file AssetFile) error {
+	assetsMu.Lock()
+	defer assetsMu.Unlock()
+
+	entry, exists := assetCache[file]
+	if !exists {
+		return NewAssetError("retain", file, ErrAssetNotLoaded)
+	}
+
+	entry.refs++
+
+	return nil
+}
+
+// ReleaseAsset removes a reference added by RetainAsset, allowing the asset to be evicted again.
+func ReleaseAsset(file AssetFile) error {
+	assetsMu.Lock()
+	entry, exists := assetCache[file]
+	if !exists {
+		assetsMu.Unlock()
+		return NewAssetError("release", file, ErrAssetNotLoaded)
+	}
+
+	if entry.refs == 0 {
+		assetsMu.Unlock()
+		return NewAssetError("release", file, ErrAssetNotRetained)
+	}
+
+	entry.refs--
+	assetsMu.Unlock()
+
+	return evictOverBudget()
+}
+
+func IsAssetEvicted(file AssetFile) bool {
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+
+	return assetsEvicted.Contains(file)
+}
+
+// touch marks the entry as the most recently used asset.
+func (e *assetEntry) touch() {
+	e.lastUsed.Store(assetClock.Add(1))
+}
+
+// estimateAssetSize returns the importer's estimate of an asset's size, defaulting to the size of its file.
+// Streamed assets pass no file data, since it is not held in memory, and default to zero.
+func estimateAssetSize(manager *AssetImporter, file AssetFile, filedata []byte, data any) int64 {
+	if manager.EstimateAssetSize != nil {
+		return manager.EstimateAssetSize(file, data)
+	}
+	return int64(len(filedata))
+}
+
+// getAssetData returns the data of a loaded asset, reloading it first if it was evicted, or the
+// fallback of its type if it failed to load.
+func getAssetData(file AssetFile) (any, error) {
+	if file.Fragment() != "" {
+		return getAssetFragment(file)
+	}
+
+	assetsMu.RLock()
+	entry, loaded := assetCache[file]
+	if loaded {
+		entry.touch()
+		data := entry.data
+		assetsMu.RUnlock()
+		return data, nil
+	}
+	evicted := assetsEvicted.Contains(file)
+	async := assetCacheOptions.AsyncReload
+	assetsMu.RUnlock()
+
+	if !evicted {
+		if fallback, ok := getAssetFallback(file); ok {
+			return fallback, nil
+		}
+		return nil, NewAssetError("get", file, ErrAssetNotLoaded)
+	}
+
+	if async {
+		go reloadEvictedAsset(file)
+		return nil, NewAssetError("get", file, ErrAssetEvicted)
+	}
+
+	if err := loadAssetFile(file); err != nil {
+		if fallback, ok := getAssetFallback(file); ok {
+			return fallback, nil
+		}
+		return nil, err
+	}
+	assetReloads.Add(1)
+
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+
+	entry, loaded = assetCache[file]
+	if !loaded {
+		return nil, NewAssetError("get", file, ErrAssetEvicted)
+	}
+
+	return entry.data, nil
+}
+
+func reloadEvictedAsset(file AssetFile) {
+	err := loadAssetFile(file)
+	if errors.Is(err, ErrAssetIsLoading) || errors.Is(err, ErrAssetIsLoaded) {
+		return
+	}
+
+	var data any
+
+	assetsMu.RLock()
+	callback := assetCacheOptions.OnEvictedReload
+	if entry, loaded := assetCache[file]; loaded && err == nil {
+		data = entry.data
+	}
+	assetsMu.RUnlock()
+
+	if err == nil {
+		assetReloads.Add(1)
+	}
+
+	if callback != nil {
+		callback(file, data, err)
+	}
+}
+
+// evictOverBudget enforces the cache budgets and emits an event for every evicted asset.
+func evictOverBudget() error {
+	assetsMu.Lock()
+	evicted, err := enforceAssetBudget()
+	assetsMu.Unlock()
+
+	emitAssetEvicted(evicted)
+
+	return err
+}
+
+// enforceAssetBudget evicts least recently used, unretained assets until the cache fits its budgets,
+// returning the evicted files.
+//
+// The caller must hold the assets write lock.
+func enforceAssetBudget() ([]AssetFile, error) {
+	options := assetCacheOptions
+	if options.Budget == 0 && len(options.TypeBudgets) == 0 {
+		return nil, nil
+	}
+
+	var total int64
+	typeTotals := make(map[AssetType]int64)
+	candidates := make([]AssetFile, 0, len(assetCache))
+
+	for file, entry := range assetCache {
+		total += entry.size
+		typeTotals[file.Type()] += entry.size
+		if entry.refs == 0 {
+			candidates = append(candidates, file)
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b AssetFile) int {
+		return cmp.Compare(assetCache[a].lastUsed.Load(), assetCache[b].lastUsed.Load())
+	})
+
+	evicted := make([]AssetFile, 0)
+	errs := make([]error, 0)
+
+	for _, file := range candidates {
+		fileType := file.Type()
+
+		overTotal := options.Budget > 0 && total > options.Budget
+		typeBudget := options.TypeBudgets[fileType]
+		overType := typeBudget > 0 && typeTotals[fileType] > typeBudget
+
+		if !overTotal && !overType {
+			continue
+		}
+
+		entry := assetCache[file]
+
+		if manager, exists := getAssetImporter(fileType); exists && manager.CleanupAssetFile != nil {
+			if err := manager.CleanupAssetFile(file, entry.data); err != nil {
+				errs = append(errs, NewAssetError("evict", file, fmt.Errorf("%w: %w", ErrAssetCleanupFailed, err)))
+			}
+		}
+
+		if err := closeAssetStream(entry.stream); err != nil {
+			errs = append(errs, NewAssetError("evict", file, err))
+		}
+
+		delete(assetCache, file)
+		releaseAssetDependencies(file)
+		assetsEvicted.Add(file)
+		assetEvictions.Add(1)
+		evicted = append(evicted, file)
+
+		total -= entry.size
+		typeTotals[fileType] -= entry.size
+	}
+
+	return evicted, errors.Join(errs...)
+}
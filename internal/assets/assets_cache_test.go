@@ -0,0 +1,195 @@
+package assets
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// tenByteFiles returns a filesystem of files of ten bytes each.
+func tenByteFiles(names ...string) fstest.MapFS {
+	files := fstest.MapFS{}
+	for _, name := range names {
+		files[name] = &fstest.MapFile{Data: []byte(strings.Repeat("x", 10))}
+	}
+	return files
+}
+
+func TestAssetCacheEviction(t *testing.T) {
+	type step struct {
+		op   string // "load", "get", "retain", "release", "configure"
+		file AssetFile
+	}
+
+	tests := []struct {
+		name    string
+		options AssetCacheOptions
+		steps   []step
+		evicted []AssetFile
+	}{
+		{
+			name:    "unlimited",
+			options: AssetCacheOptions{},
+			steps:   []step{{"load", "assets/a.txt"}, {"load", "assets/b.txt"}, {"load", "assets/c.txt"}},
+		},
+		{
+			name:    "least recently loaded",
+			options: AssetCacheOptions{Budget: 25},
+			steps:   []step{{"load", "assets/a.txt"}, {"load", "assets/b.txt"}, {"load", "assets/c.txt"}},
+			evicted: []AssetFile{"assets/a.txt"},
+		},
+		{
+			name:    "least recently used",
+			options: AssetCacheOptions{Budget: 25},
+			steps:   []step{{"load", "assets/a.txt"}, {"load", "assets/b.txt"}, {"get", "assets/a.txt"}, {"load", "assets/c.txt"}},
+			evicted: []AssetFile{"assets/b.txt"},
+		},
+		{
+			name:    "retained assets stay",
+			options: AssetCacheOptions{Budget: 25},
+			steps:   []step{{"load", "assets/a.txt"}, {"retain", "assets/a.txt"}, {"load", "assets/b.txt"}, {"load", "assets/c.txt"}},
+			evicted: []AssetFile{"assets/b.txt"},
+		},
+		{
+			name:    "release evicts",
+			options: AssetCacheOptions{},
+			steps:   []step{{"load", "assets/a.txt"}, {"retain", "assets/a.txt"}, {"load", "assets/b.txt"}, {"retain", "assets/b.txt"}, {"configure", ""}, {"release", "assets/a.txt"}},
+			evicted: []AssetFile{"assets/a.txt"},
+		},
+		{
+			name:    "type budget",
+			options: AssetCacheOptions{TypeBudgets: map[AssetType]int64{"txt": 15}},
+			steps:   []step{{"load", "assets/a.txt"}, {"load", "assets/b.txt"}, {"load", "assets/c.bin"}, {"load", "assets/d.bin"}},
+			evicted: []AssetFile{"assets/a.txt"},
+		},
+		{
+			name:    "type budget keys are normalized",
+			options: AssetCacheOptions{TypeBudgets: map[AssetType]int64{".TXT": 15}},
+			steps:   []step{{"load", "assets/a.txt"}, {"load", "assets/b.txt"}},
+			evicted: []AssetFile{"assets/a.txt"},
+		},
+		{
+			name:    "smallest duplicate type budget wins",
+			options: AssetCacheOptions{TypeBudgets: map[AssetType]int64{"TXT": 0, "txt": 100, ".txt": 15}},
+			steps:   []step{{"load", "assets/a.txt"}, {"load", "assets/b.txt"}},
+			evicted: []AssetFile{"assets/a.txt"},
+		},
+		{
+			name:    "configuring evicts",
+			options: AssetCacheOptions{},
+			steps:   []step{{"load", "assets/a.txt"}, {"load", "assets/b.txt"}, {"configure", ""}},
+			evicted: []AssetFile{"assets/a.txt", "assets/b.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAssets(t)
+			cleanups := textImporter(t, "txt", "bin")
+			mapFS(t, tenByteFiles("a.txt", "b.txt", "c.txt", "c.bin", "d.bin"))
+
+			if err := ConfigureAssetCache(tt.options); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, step := range tt.steps {
+				var err error
+				switch step.op {
+				case "load":
+					err = LoadAssets(step.file)
+				case "get":
+					_, err = GetAsset[string](step.file)
+				case "retain":
+					err = RetainAsset(step.file)
+				case "release":
+					err = ReleaseAsset(step.file)
+				case "configure":
+					err = ConfigureAssetCache(AssetCacheOptions{Budget: 5})
+				}
+				if err != nil {
+					t.Fatalf("%s %s: %v", step.op, step.file, err)
+				}
+			}
+
+			evicted := make([]AssetFile, 0)
+			for _, file := range []AssetFile{"assets/a.txt", "assets/b.txt", "assets/c.txt", "assets/c.bin", "assets/d.bin"} {
+				if IsAssetEvicted(file) {
+					evicted = append(evicted, file)
+				}
+			}
+			if !slices.Equal(evicted, tt.evicted) {
+				t.Fatalf("evicted = %v, want %v", evicted, tt.evicted)
+			}
+			if got := int(cleanups.Load()); got != len(tt.evicted) {
+				t.Fatalf("cleanups = %d, want %d", got, len(tt.evicted))
+			}
+			if stats := GetAssetCacheStats(); stats.Evicted != len(tt.evicted) || stats.Evictions != uint64(len(tt.evicted)) {
+				t.Fatalf("stats = %+v, want %d evicted", stats, len(tt.evicted))
+			}
+		})
+	}
+}
+
+func TestAssetCacheReloadsEvicted(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, "txt")
+	mapFS(t, tenByteFiles("a.txt", "b.txt"))
+
+	if err := ConfigureAssetCache(AssetCacheOptions{Budget: 15}); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadAssets("assets/a.txt", "assets/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	evicted := AssetFile("assets/a.txt")
+	if !IsAssetEvicted(evicted) {
+		evicted = "assets/b.txt"
+	}
+
+	data, err := GetAsset[string](evicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data != strings.Repeat("x", 10) {
+		t.Fatalf("GetAsset() = %q after reload", data)
+	}
+	if IsAssetEvicted(evicted) || !IsAssetLoaded(evicted) {
+		t.Fatalf("%s is still evicted after GetAsset", evicted)
+	}
+	if stats := GetAssetCacheStats(); stats.Reloads != 1 || stats.Loaded != 1 || stats.Evicted != 1 {
+		t.Fatalf("stats = %+v, want 1 reload, 1 loaded and 1 evicted", stats)
+	}
+}
+
+func TestAssetCacheRetainRefCounts(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, "txt")
+	mapFS(t, tenByteFiles("a.txt", "b.txt"))
+
+	if err := LoadAssets("assets/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := RetainAsset("assets/a.txt"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ConfigureAssetCache(AssetCacheOptions{Budget: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReleaseAsset("assets/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if IsAssetEvicted("assets/a.txt") {
+		t.Fatal("asset evicted while still retained once")
+	}
+	if err := ReleaseAsset("assets/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if !IsAssetEvicted("assets/a.txt") {
+		t.Fatal("asset not evicted after its last release")
+	}
+}
@@ -0,0 +1,191 @@
+package assets
+
+import (
+	"errors"
+	"slices"
+	"sync"
+)
+
+var (
+	ErrAssetDependencyCycle     = errors.New("asset dependency cycle")
+	ErrAssetFragmentNotFound    = errors.New("asset fragment not found")
+	ErrAssetFragmentUnsupported = errors.New("asset does not support fragments")
+)
+
+// ======================================================
+// Asset Dependencies
+// ======================================================
+
+var (
+	assetDependencies        = make(map[AssetFile][]AssetFile)
+	assetDependenciesLoading = make(map[AssetFile]AssetFile) // Dependency each asset is loading, for cycle detection
+	assetsLoadingDone        = sync.NewCond(&assetsMu)
+)
+
+// LoadAssetDependencies loads the asset files another asset depends on, and retains them for as long as it stays loaded.
+//
+// Importers call it from ProcessAssetFile or ProcessAssetStream, before using the data of the dependencies.
+// Dependencies that are already loaded are reused, and dependencies being loaded by another goroutine are waited for.
+// When the dependent asset is unloaded or evicted its dependencies are released, but stay loaded.
+// Reloading a dependency also reloads the assets that depend on it.
+//
+//	ProcessAssetFile: func(file finch.AssetFile, data []byte) (any, error) {
+//		page := finch.AssetFile("assets/ui/page.png")
+//		if err := finch.LoadAssetDependencies(file, page); err != nil {
+//			return nil, err
+//		}
+//		img := finch.MustGetImage(page)
+//		...
+//	}
+func LoadAssetDependencies(file AssetFile, dependencies ...AssetFile) error {
+	errs := make([]error, 0)
+
+	for _, dependency := range dependencies {
+		if dependency == file {
+			errs = append(errs, NewAssetError("load dependency", file, ErrAssetDependencyCycle))
+			continue
+		}
+
+		assetsMu.Lock()
+		assetDependenciesLoading[file] = dependency
+		assetsMu.Unlock()
+
+		if err := loadAssetFile(dependency); err != nil && !errors.Is(err, ErrAssetIsLoaded) && !errors.Is(err, ErrAssetIsLoading) {
+			errs = append(errs, err)
+		} else if err := retainAssetDependency(file, dependency); err != nil {
+			errs = append(errs, err)
+		}
+
+		assetsMu.Lock()
+		delete(assetDependenciesLoading, file)
+		assetsMu.Unlock()
+	}
+
+	return errors.Join(errs...)
+}
+
+func MustLoadAssetDependencies(file AssetFile, dependencies ...AssetFile) {
+	if err := LoadAssetDependencies(file, dependencies...); err != nil {
+		panic(err)
+	}
+}
+
+// AssetDependencies returns the asset files a loaded asset depends on.
+func AssetDependencies(file AssetFile) []AssetFile {
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+
+	return slices.Clone(assetDependencies[file])
+}
+
+// AssetDependents returns the loaded asset files that depend on an asset file.
+func AssetDependents(file AssetFile) []AssetFile {
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+
+	dependents := make([]AssetFile, 0)
+	for owner, dependencies := range assetDependencies {
+		if slices.Contains(dependencies, file) {
+			dependents = append(dependents, owner)
+		}
+	}
+
+	slices.Sort(dependents)
+
+	return dependents
+}
+
+// retainAssetDependency waits for a dependency to finish loading and retains it on behalf of its dependent.
+func retainAssetDependency(file, dependency AssetFile) error {
+	assetsMu.Lock()
+	defer assetsMu.Unlock()
+
+	for assetsLoading.Contains(dependency) {
+		// Waiting on a dependency that is itself waiting on this asset would never return.
+		if isAssetDependencyLoading(dependency, file) {
+			return NewAssetError("load dependency", dependency, ErrAssetDependencyCycle)
+		}
+		assetsLoadingDone.Wait()
+	}
+
+	entry, loaded := assetCache[dependency]
+	if !loaded {
+		return NewAssetError("load dependency", dependency, ErrAssetNotLoaded)
+	}
+
+	if slices.Contains(assetDependencies[file], dependency) {
+		return nil
+	}
+
+	entry.refs++
+	assetDependencies[file] = append(assetDependencies[file], dependency)
+
+	return nil
+}
+
+// isAssetDependencyLoading reports whether an asset is loading another, directly or through the
+// dependencies it is loading in turn.
+//
+// The caller must hold the assets lock.
+func isAssetDependencyLoading(file, dependency AssetFile) bool {
+	for visited := 0; visited <= len(assetDependenciesLoading); visited++ {
+		next, loading := assetDependenciesLoading[file]
+		if !loading {
+			return false
+		}
+		if next == dependency {
+			return true
+		}
+		file = next
+	}
+	return false
+}
+
+// releaseAssetDependencies releases the dependencies retained by an asset that is no longer loaded, or failed to load.
+//
+// The caller must hold the assets write lock.
+func releaseAssetDependencies(file AssetFile) {
+	releaseAssetDependencyRefs(assetDependencies[file])
+	delete(assetDependencies, file)
+}
+
+// releaseAssetDependencyRefs drops one reference from each of the dependencies.
+//
+// The caller must hold the assets write lock.
+func releaseAssetDependencyRefs(dependencies []AssetFile) {
+	for _, dependency := range dependencies {
+		if entry, loaded := assetCache[dependency]; loaded && entry.refs > 0 {
+			entry.refs--
+		}
+	}
+}
+
+// ======================================================
+// Asset Fragments
+// ======================================================
+
+// AssetFragmenter is implemented by asset data that contains named sub-assets, such as the frames of an atlas.
+//
+// Sub-assets are requested with a fragment in the asset file, as in "assets/sprites.atlas#hero_idle".
+type AssetFragmenter interface {
+	AssetFragment(name string) (any, bool)
+}
+
+func getAssetFragment(file AssetFile) (any, error) {
+	data, err := getAssetData(file.Base())
+	if err != nil {
+		return nil, err
+	}
+
+	fragmenter, ok := data.(AssetFragmenter)
+	if !ok {
+		return nil, NewAssetError("get", file, ErrAssetFragmentUnsupported)
+	}
+
+	fragment, exists := fragmenter.AssetFragment(file.Fragment())
+	if !exists {
+		return nil, NewAssetError("get", file, ErrAssetFragmentNotFound)
+	}
+
+	return fragment, nil
+}
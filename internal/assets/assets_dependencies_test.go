@@ -0,0 +1,197 @@
+package assets
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var errImportFailed = errors.New("import failed")
+
+// dependentImporter registers an importer of "dep" files, which name the asset they depend on.
+// Files whose name ends with "!" load their dependency and then fail.
+func dependentImporter(t *testing.T) {
+	t.Helper()
+
+	err := RegisterAssetImporter(&AssetImporter{
+		AssetTypes: []AssetType{"dep"},
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			dependency, fail := strings.CutSuffix(string(data), "!")
+			if err := LoadAssetDependencies(file, AssetFile(dependency)); err != nil {
+				return nil, err
+			}
+			if fail {
+				return nil, errImportFailed
+			}
+			return string(data), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assetRefs(file AssetFile) int {
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+
+	if entry, loaded := assetCache[file]; loaded {
+		return entry.refs
+	}
+	return -1
+}
+
+func TestReloadAssetReloadsDependents(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, "txt")
+	dependentImporter(t)
+	mapFS(t, fstest.MapFS{
+		"a.txt": {Data: []byte("a")},
+		"b.dep": {Data: []byte("assets/a.txt")},
+	})
+
+	if err := LoadAssets("assets/b.dep"); err != nil {
+		t.Fatal(err)
+	}
+	if got := AssetDependents("assets/a.txt"); !slices.Equal(got, []AssetFile{"assets/b.dep"}) {
+		t.Fatalf("AssetDependents() = %v", got)
+	}
+
+	var mu sync.Mutex
+	reloaded := make([]AssetFile, 0)
+	unsubscribe := SubscribeAssetEvents(func(event AssetEvent) {
+		if event.Kind == AssetReloaded {
+			mu.Lock()
+			reloaded = append(reloaded, event.File)
+			mu.Unlock()
+		}
+	})
+	defer unsubscribe()
+
+	done := make(chan error, 1)
+	go func() { done <- ReloadAsset("assets/a.txt") }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ReloadAsset() did not return")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(reloaded, []AssetFile{"assets/a.txt", "assets/b.dep"}) {
+		t.Fatalf("reloaded = %v", reloaded)
+	}
+	if got := assetRefs("assets/a.txt"); got != 1 {
+		t.Fatalf("refs after reload = %d, want 1", got)
+	}
+}
+
+func TestAssetDependencyRefs(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		load  AssetFile
+		err   error
+		op    string // "", "unload", "reload"
+		refs  int
+	}{
+		{
+			name:  "retained while loaded",
+			files: fstest.MapFS{"b.dep": {Data: []byte("assets/a.txt")}},
+			load:  "assets/b.dep",
+			refs:  1,
+		},
+		{
+			name:  "released on unload",
+			files: fstest.MapFS{"b.dep": {Data: []byte("assets/a.txt")}},
+			load:  "assets/b.dep",
+			op:    "unload",
+			refs:  0,
+		},
+		{
+			name:  "kept on reload",
+			files: fstest.MapFS{"b.dep": {Data: []byte("assets/a.txt")}},
+			load:  "assets/b.dep",
+			op:    "reload",
+			refs:  1,
+		},
+		{
+			name:  "released on import failure",
+			files: fstest.MapFS{"b.dep": {Data: []byte("assets/a.txt!")}},
+			load:  "assets/b.dep",
+			err:   errImportFailed,
+			refs:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAssets(t)
+			textImporter(t, "txt")
+			dependentImporter(t)
+			tt.files["a.txt"] = &fstest.MapFile{Data: []byte("a")}
+			mapFS(t, tt.files)
+
+			if err := LoadAssets(tt.load); !errors.Is(err, tt.err) {
+				t.Fatalf("LoadAssets() error = %v, want %v", err, tt.err)
+			}
+
+			var err error
+			switch tt.op {
+			case "unload":
+				err = UnloadAssets(tt.load)
+			case "reload":
+				err = ReloadAsset(tt.load)
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", tt.op, err)
+			}
+
+			if got := assetRefs("assets/a.txt"); got != tt.refs {
+				t.Fatalf("refs = %d, want %d", got, tt.refs)
+			}
+			if tt.err != nil && len(AssetDependencies(tt.load)) != 0 {
+				t.Fatalf("AssetDependencies() = %v after failure", AssetDependencies(tt.load))
+			}
+		})
+	}
+}
+
+func TestReloadAssetFailureKeepsDependencies(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, "txt")
+	dependentImporter(t)
+	files := mapFS(t, fstest.MapFS{
+		"a.txt": {Data: []byte("a")},
+		"c.txt": {Data: []byte("c")},
+		"b.dep": {Data: []byte("assets/a.txt")},
+	})
+
+	if err := LoadAssets("assets/b.dep"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The new contents retain a different dependency and then fail to import.
+	files["b.dep"] = &fstest.MapFile{Data: []byte("assets/c.txt!")}
+	if err := ReloadAsset("assets/b.dep"); !errors.Is(err, errImportFailed) {
+		t.Fatalf("ReloadAsset() error = %v, want %v", err, errImportFailed)
+	}
+
+	if got := AssetDependencies("assets/b.dep"); !slices.Equal(got, []AssetFile{"assets/a.txt"}) {
+		t.Fatalf("AssetDependencies() = %v after failed reload", got)
+	}
+	if got := assetRefs("assets/a.txt"); got != 1 {
+		t.Fatalf("refs of the previous dependency = %d, want 1", got)
+	}
+	if got := assetRefs("assets/c.txt"); got != 0 {
+		t.Fatalf("refs of the new dependency = %d, want 0", got)
+	}
+}
@@ -0,0 +1,72 @@
+package assets
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrAssetImportFailed  = errors.New("asset import failed")
+	ErrAssetCleanupFailed = errors.New("asset cleanup failed")
+	ErrAssetLoadPanicked  = errors.New("asset load panicked")
+)
+
+// ======================================================
+// Asset Error
+// ======================================================
+
+// AssetError records an error and the operation and asset file that caused it.
+//
+// Asset errors wrap one of the package's sentinel errors, or the error returned by a
+// filesystem or importer, so they can be tested with errors.Is and errors.As.
+//
+//	if err := finch.LoadAssets(file); errors.Is(err, finch.ErrAssetManagerNotFound) {
+//		...
+//	}
+//
+//	var assetErr *finch.AssetError
+//	if errors.As(err, &assetErr) {
+//		log.Println(assetErr.Op, assetErr.File)
+//	}
+type AssetError struct {
+	Op   string    // Operation that failed, such as "load", "unload" or "get"
+	File AssetFile // Asset file the operation was performed on, if any
+	Type AssetType // Asset type of the importer involved, if any
+	Err  error     // Underlying cause
+}
+
+// NewAssetError returns an AssetError for an operation on an asset file.
+func NewAssetError(op string, file AssetFile, err error) *AssetError {
+	return &AssetError{
+		Op:   op,
+		File: file,
+		Type: file.Type(),
+		Err:  err,
+	}
+}
+
+func (e *AssetError) Error() string {
+	var b strings.Builder
+
+	b.WriteString(e.Op)
+
+	switch {
+	case e.File != "":
+		b.WriteString(" ")
+		b.WriteString(e.File.Path())
+	case e.Type != "":
+		b.WriteString(" ")
+		b.WriteString(e.Type.String())
+	}
+
+	if e.Err != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+
+	return b.String()
+}
+
+func (e *AssetError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,441 @@
+package assets
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+// fragments is asset data with sub-assets, for fragment lookups.
+type fragments map[string]any
+
+func (f fragments) AssetFragment(name string) (any, bool) {
+	data, exists := f[name]
+	return data, exists
+}
+
+func TestAssetErrorSentinels(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T) error
+		want error
+		op   string
+	}{
+		{
+			name: "importer not found",
+			run: func(t *testing.T) error {
+				return LoadAssets("assets/hero.png")
+			},
+			want: ErrAssetManagerNotFound,
+			op:   "load",
+		},
+		{
+			name: "importer nil",
+			run: func(t *testing.T) error {
+				return RegisterAssetImporter(nil)
+			},
+			want: ErrAssetManagerNil,
+			op:   "register",
+		},
+		{
+			name: "importer conflict",
+			run: func(t *testing.T) error {
+				textImporter(t, "txt")
+				return RegisterAssetImporter(&AssetImporter{AssetTypes: []AssetType{"txt"}})
+			},
+			want: ErrAssetManagerConflict,
+			op:   "register",
+		},
+		{
+			name: "importer type empty",
+			run: func(t *testing.T) error {
+				return RegisterAssetImporter(&AssetImporter{AssetTypes: []AssetType{" "}})
+			},
+			want: ErrAssetInvalidType,
+			op:   "register",
+		},
+		{
+			name: "file type empty",
+			run: func(t *testing.T) error {
+				return LoadAssets("assets/README")
+			},
+			want: ErrAssetTypeEmpty,
+			op:   "load",
+		},
+		{
+			name: "import failed",
+			run: func(t *testing.T) error {
+				mapFS(t, fstest.MapFS{"bad.txt": {Data: []byte("bad")}})
+				RegisterAssetImporter(&AssetImporter{
+					AssetTypes: []AssetType{"txt"},
+					ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+						return nil, errors.New("malformed")
+					},
+				})
+				return LoadAssets("assets/bad.txt")
+			},
+			want: ErrAssetImportFailed,
+			op:   "load",
+		},
+		{
+			name: "already loaded",
+			run: func(t *testing.T) error {
+				textImporter(t, "txt")
+				mapFS(t, fstest.MapFS{"a.txt": {}})
+				if err := LoadAssets("assets/a.txt"); err != nil {
+					t.Fatal(err)
+				}
+				return LoadAssets("assets/a.txt")
+			},
+			want: ErrAssetIsLoaded,
+			op:   "load",
+		},
+		{
+			name: "not loaded",
+			run: func(t *testing.T) error {
+				textImporter(t, "txt")
+				_, err := GetAsset[string]("assets/a.txt")
+				return err
+			},
+			want: ErrAssetNotLoaded,
+			op:   "get",
+		},
+		{
+			name: "unload not loaded",
+			run: func(t *testing.T) error {
+				textImporter(t, "txt")
+				return UnloadAssets("assets/a.txt")
+			},
+			want: ErrAssetNotLoaded,
+			op:   "unload",
+		},
+		{
+			name: "type mismatch",
+			run: func(t *testing.T) error {
+				textImporter(t, "txt")
+				mapFS(t, fstest.MapFS{"a.txt": {}})
+				if err := LoadAssets("assets/a.txt"); err != nil {
+					t.Fatal(err)
+				}
+				_, err := GetAsset[int]("assets/a.txt")
+				return err
+			},
+			want: ErrAssetTypeMismatch,
+			op:   "get",
+		},
+		{
+			name: "cleanup failed",
+			run: func(t *testing.T) error {
+				mapFS(t, fstest.MapFS{"a.txt": {}})
+				RegisterAssetImporter(&AssetImporter{
+					AssetTypes:       []AssetType{"txt"},
+					ProcessAssetFile: func(file AssetFile, data []byte) (any, error) { return "", nil },
+					CleanupAssetFile: func(file AssetFile, data any) error { return errors.New("busy") },
+				})
+				if err := LoadAssets("assets/a.txt"); err != nil {
+					t.Fatal(err)
+				}
+				return UnloadAssets("assets/a.txt")
+			},
+			want: ErrAssetCleanupFailed,
+			op:   "unload",
+		},
+		{
+			name: "filesystem nil",
+			run: func(t *testing.T) error {
+				return RegisterAssetFilesystem("assets", nil)
+			},
+			want: ErrAssetFilesystemNil,
+			op:   "mount",
+		},
+		{
+			name: "filesystem conflict",
+			run: func(t *testing.T) error {
+				mapFS(t, fstest.MapFS{})
+				return RegisterAssetFilesystem("assets", fstest.MapFS{})
+			},
+			want: ErrAssetFilesystemConflict,
+			op:   "mount",
+		},
+		{
+			name: "layer not found",
+			run: func(t *testing.T) error {
+				return UnmountAssetFilesystem("assets", "dlc")
+			},
+			want: ErrAssetLayerNotFound,
+			op:   "unmount",
+		},
+		{
+			name: "path mapping invalid",
+			run: func(t *testing.T) error {
+				return RegisterAssetFilesystemMapped("assets", fstest.MapFS{}, AssetPathMapping(9))
+			},
+			want: ErrAssetPathMappingInvalid,
+			op:   "mount",
+		},
+		{
+			name: "archive unsupported",
+			run: func(t *testing.T) error {
+				return RegisterAssetArchiveData("assets", "assets.rar", nil)
+			},
+			want: ErrAssetArchiveUnsupported,
+			op:   "mount",
+		},
+		{
+			name: "budget invalid",
+			run: func(t *testing.T) error {
+				return ConfigureAssetCache(AssetCacheOptions{Budget: -1})
+			},
+			want: ErrAssetBudgetInvalid,
+			op:   "configure",
+		},
+		{
+			name: "not retained",
+			run: func(t *testing.T) error {
+				textImporter(t, "txt")
+				mapFS(t, fstest.MapFS{"a.txt": {}})
+				if err := LoadAssets("assets/a.txt"); err != nil {
+					t.Fatal(err)
+				}
+				return ReleaseAsset("assets/a.txt")
+			},
+			want: ErrAssetNotRetained,
+			op:   "release",
+		},
+		{
+			name: "fragment unsupported",
+			run: func(t *testing.T) error {
+				textImporter(t, "txt")
+				mapFS(t, fstest.MapFS{"a.txt": {}})
+				if err := LoadAssets("assets/a.txt"); err != nil {
+					t.Fatal(err)
+				}
+				_, err := GetAsset[any]("assets/a.txt#hero")
+				return err
+			},
+			want: ErrAssetFragmentUnsupported,
+			op:   "get",
+		},
+		{
+			name: "fragment not found",
+			run: func(t *testing.T) error {
+				mapFS(t, fstest.MapFS{"sprites.atlas": {}})
+				RegisterAssetImporter(&AssetImporter{
+					AssetTypes: []AssetType{"atlas"},
+					ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+						return fragments{"hero": 1}, nil
+					},
+				})
+				if err := LoadAssets("assets/sprites.atlas"); err != nil {
+					t.Fatal(err)
+				}
+				_, err := GetAsset[any]("assets/sprites.atlas#villain")
+				return err
+			},
+			want: ErrAssetFragmentNotFound,
+			op:   "get",
+		},
+		{
+			name: "dependency cycle",
+			run: func(t *testing.T) error {
+				return LoadAssetDependencies("assets/a.txt", "assets/a.txt")
+			},
+			want: ErrAssetDependencyCycle,
+			op:   "load dependency",
+		},
+		{
+			name: "manifest nil",
+			run: func(t *testing.T) error {
+				return RegisterAssetManifest(nil)
+			},
+			want: ErrAssetManifestNil,
+			op:   "register",
+		},
+		{
+			name: "group conflict",
+			run: func(t *testing.T) error {
+				manifest := &AssetManifest{Groups: map[string][]string{"ui": nil}}
+				if err := RegisterAssetManifest(manifest); err != nil {
+					t.Fatal(err)
+				}
+				return RegisterAssetManifest(manifest)
+			},
+			want: ErrAssetGroupConflict,
+			op:   "register",
+		},
+		{
+			name: "group not found",
+			run: func(t *testing.T) error {
+				return LoadGroup("ui")
+			},
+			want: ErrAssetGroupNotFound,
+			op:   "resolve",
+		},
+		{
+			name: "manifest group not found",
+			run: func(t *testing.T) error {
+				_, err := (&AssetManifest{}).Resolve("ui")
+				return err
+			},
+			want: ErrAssetGroupNotFound,
+			op:   "resolve",
+		},
+		{
+			name: "group not loaded",
+			run: func(t *testing.T) error {
+				return UnloadGroup("ui")
+			},
+			want: ErrAssetGroupNotLoaded,
+			op:   "unload",
+		},
+		{
+			name: "manifest invalid type",
+			run: func(t *testing.T) error {
+				_, err := ParseAssetManifest("txt", nil)
+				return err
+			},
+			want: ErrAssetManifestInvalidType,
+			op:   "parse manifest",
+		},
+		{
+			name: "manifest no matches",
+			run: func(t *testing.T) error {
+				mapFS(t, fstest.MapFS{})
+				return (&AssetManifest{Groups: map[string][]string{"ui": {"assets/ui/*.png"}}}).Validate()
+			},
+			want: ErrAssetManifestNoMatches,
+			op:   "validate",
+		},
+		{
+			name: "manifest file not found",
+			run: func(t *testing.T) error {
+				mapFS(t, fstest.MapFS{})
+				return (&AssetManifest{Groups: map[string][]string{"ui": {"assets/ui/button.png"}}}).Validate()
+			},
+			want: ErrAssetManifestFileNotFound,
+			op:   "validate",
+		},
+		{
+			name: "hash manifest invalid type",
+			run: func(t *testing.T) error {
+				_, err := ParseAssetHashManifest("txt", nil)
+				return err
+			},
+			want: ErrAssetManifestInvalidType,
+			op:   "parse hash manifest",
+		},
+		{
+			name: "hash manifest nil",
+			run: func(t *testing.T) error {
+				return SetAssetVerification(nil, AssetVerifyListed)
+			},
+			want: ErrAssetHashManifestNil,
+			op:   "configure",
+		},
+		{
+			name: "verify mode invalid",
+			run: func(t *testing.T) error {
+				return SetAssetVerification(&AssetHashManifest{}, AssetVerifyMode(9))
+			},
+			want: ErrAssetVerifyModeInvalid,
+			op:   "configure",
+		},
+		{
+			name: "verification disabled",
+			run: func(t *testing.T) error {
+				return VerifyAssetFiles()
+			},
+			want: ErrAssetVerificationDisabled,
+			op:   "verify",
+		},
+		{
+			name: "hash mismatch",
+			run: func(t *testing.T) error {
+				mapFS(t, fstest.MapFS{"a.txt": {Data: []byte("tampered")}})
+				manifest := &AssetHashManifest{Files: map[AssetFile]AssetStamp{"assets/a.txt": NewAssetStamp([]byte("original"))}}
+				if err := SetAssetVerification(manifest, AssetVerifyListed); err != nil {
+					t.Fatal(err)
+				}
+				return VerifyAssetFiles()
+			},
+			want: ErrAssetHashMismatch,
+			op:   "verify",
+		},
+		{
+			name: "hash not listed",
+			run: func(t *testing.T) error {
+				mapFS(t, fstest.MapFS{"a.txt": {}})
+				if err := SetAssetVerification(&AssetHashManifest{}, AssetVerifyStrict); err != nil {
+					t.Fatal(err)
+				}
+				return VerifyAssetFiles("assets/a.txt")
+			},
+			want: ErrAssetHashNotListed,
+			op:   "verify",
+		},
+		{
+			name: "path empty",
+			run: func(t *testing.T) error {
+				_, err := NewAssetFile("")
+				return err
+			},
+			want: ErrAssetPathEmpty,
+			op:   "resolve",
+		},
+		{
+			name: "path escapes root",
+			run: func(t *testing.T) error {
+				_, err := NewAssetFile("assets/../../etc/passwd")
+				return err
+			},
+			want: ErrAssetPathEscapesRoot,
+			op:   "resolve",
+		},
+		{
+			name: "path invalid",
+			run: func(t *testing.T) error {
+				_, err := NewAssetFile("C:/assets/hero.png")
+				return err
+			},
+			want: ErrAssetPathInvalid,
+			op:   "resolve",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAssets(t)
+
+			err := tt.run(t)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+
+			var assetErr *AssetError
+			if !errors.As(err, &assetErr) {
+				t.Fatalf("error %v is not an *AssetError", err)
+			}
+			if assetErr.Op != tt.op {
+				t.Fatalf("AssetError.Op = %q, want %q", assetErr.Op, tt.op)
+			}
+		})
+	}
+}
+
+func TestAssetErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  *AssetError
+		want string
+	}{
+		{err: &AssetError{Op: "load", File: "assets/a.txt", Type: "txt", Err: ErrAssetNotLoaded}, want: "load assets/a.txt: asset not loaded"},
+		{err: &AssetError{Op: "register", Type: "txt", Err: ErrAssetManagerConflict}, want: "register txt: asset manager conflict"},
+		{err: &AssetError{Op: "verify", Err: ErrAssetVerificationDisabled}, want: "verify: asset verification is disabled"},
+		{err: &AssetError{Op: "configure"}, want: "configure"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
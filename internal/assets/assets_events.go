@@ -0,0 +1,229 @@
+package assets
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ======================================================
+// Asset Event Kind
+// ======================================================
+
+// AssetEventKind identifies a stage in the lifecycle of an asset file.
+type AssetEventKind int
+
+const (
+	AssetLoadStarted AssetEventKind = iota
+	AssetLoaded
+	AssetLoadFailed
+	AssetUnloaded
+	AssetReloaded
+	AssetEvicted
+)
+
+func (k AssetEventKind) String() string {
+	switch k {
+	case AssetLoadStarted:
+		return "load_started"
+	case AssetLoaded:
+		return "loaded"
+	case AssetLoadFailed:
+		return "load_failed"
+	case AssetUnloaded:
+		return "unloaded"
+	case AssetReloaded:
+		return "reloaded"
+	case AssetEvicted:
+		return "evicted"
+	default:
+		return "unknown"
+	}
+}
+
+func (k AssetEventKind) IsValid() bool {
+	return k >= AssetLoadStarted && k <= AssetEvicted
+}
+
+// ======================================================
+// Asset Event
+// ======================================================
+
+// AssetEvent describes something that happened to an asset file.
+//
+// Duration and Bytes are set for loaded and reloaded events, and Err is set for failed loads.
+type AssetEvent struct {
+	Kind     AssetEventKind
+	File     AssetFile
+	Duration time.Duration
+	Bytes    int64
+	Err      error
+}
+
+// AssetEventFunc is called for every asset event.
+type AssetEventFunc func(event AssetEvent)
+
+// AssetLoadTiming is the most recent load duration of an asset file.
+type AssetLoadTiming struct {
+	File     AssetFile
+	Duration time.Duration
+	Bytes    int64
+}
+
+// AssetLoadReport aggregates load timings of the assets loaded so far.
+type AssetLoadReport struct {
+	// Slowest lists the slowest asset loads, slowest first.
+	Slowest []AssetLoadTiming
+	// Groups is the total load time of the files of every loaded group.
+	Groups map[string]time.Duration
+	// Total is the total load time of every recorded asset.
+	Total time.Duration
+	// Bytes is the total number of bytes read by every recorded asset.
+	Bytes int64
+	// Count is the number of recorded assets.
+	Count int
+}
+
+var (
+	assetLogger           = slog.Default()
+	assetEventSubscribers = make(map[int]AssetEventFunc)
+	assetEventIDs         = 0
+	assetLoadTimings      = make(map[AssetFile]AssetLoadTiming)
+	assetEventsMu         = sync.Mutex{}
+)
+
+// SetAssetLogger sets the logger asset events are written to. Apps use their context's logger by default.
+func SetAssetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	assetEventsMu.Lock()
+	defer assetEventsMu.Unlock()
+
+	assetLogger = logger
+}
+
+// SubscribeAssetEvents registers a callback that is invoked for every asset event.
+//
+// Callbacks run on the goroutine that caused the event, which may be a background load
+// batch. The returned function removes the subscription.
+func SubscribeAssetEvents(fn AssetEventFunc) (unsubscribe func()) {
+	if fn == nil {
+		return func() {}
+	}
+
+	assetEventsMu.Lock()
+	defer assetEventsMu.Unlock()
+
+	id := assetEventIDs
+	assetEventIDs++
+	assetEventSubscribers[id] = fn
+
+	return func() {
+		assetEventsMu.Lock()
+		defer assetEventsMu.Unlock()
+
+		delete(assetEventSubscribers, id)
+	}
+}
+
+// GetAssetLoadReport returns the load timings recorded so far, including the given number of slowest assets.
+func GetAssetLoadReport(slowest int) AssetLoadReport {
+	assetEventsMu.Lock()
+	timings := make([]AssetLoadTiming, 0, len(assetLoadTimings))
+	for _, timing := range assetLoadTimings {
+		timings = append(timings, timing)
+	}
+	assetEventsMu.Unlock()
+
+	report := AssetLoadReport{
+		Groups: make(map[string]time.Duration),
+		Count:  len(timings),
+	}
+
+	byFile := make(map[AssetFile]time.Duration, len(timings))
+	for _, timing := range timings {
+		report.Total += timing.Duration
+		report.Bytes += timing.Bytes
+		byFile[timing.File] = timing.Duration
+	}
+
+	slices.SortFunc(timings, func(a, b AssetLoadTiming) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	report.Slowest = timings[:min(max(slowest, 0), len(timings))]
+
+	assetGroupsMu.Lock()
+	for group, files := range assetGroupsLoaded {
+		for _, file := range files {
+			report.Groups[group] += byFile[file]
+		}
+	}
+	assetGroupsMu.Unlock()
+
+	return report
+}
+
+// ResetAssetLoadReport clears the recorded load timings.
+func ResetAssetLoadReport() {
+	assetEventsMu.Lock()
+	defer assetEventsMu.Unlock()
+
+	clear(assetLoadTimings)
+}
+
+// emitAssetEvent records, logs and dispatches an asset event.
+//
+// It must not be called while holding the assets lock, since subscribers may access assets.
+func emitAssetEvent(event AssetEvent) {
+	assetEventsMu.Lock()
+	logger := assetLogger
+	subscribers := make([]AssetEventFunc, 0, len(assetEventSubscribers))
+	for _, fn := range assetEventSubscribers {
+		subscribers = append(subscribers, fn)
+	}
+	if event.Kind == AssetLoaded || event.Kind == AssetReloaded {
+		assetLoadTimings[event.File] = AssetLoadTiming{
+			File:     event.File,
+			Duration: event.Duration,
+			Bytes:    event.Bytes,
+		}
+	}
+	assetEventsMu.Unlock()
+
+	logAssetEvent(logger, event)
+
+	for _, fn := range subscribers {
+		fn(event)
+	}
+}
+
+func emitAssetEvicted(files []AssetFile) {
+	for _, file := range files {
+		emitAssetEvent(AssetEvent{Kind: AssetEvicted, File: file})
+	}
+}
+
+func logAssetEvent(logger *slog.Logger, event AssetEvent) {
+	attrs := []any{
+		slog.String("file", event.File.Path()),
+	}
+
+	switch event.Kind {
+	case AssetLoadStarted:
+		logger.Debug("Loading asset", attrs...)
+	case AssetLoaded:
+		logger.Debug("Loaded asset", append(attrs, slog.Duration("duration", event.Duration), slog.Int64("bytes", event.Bytes))...)
+	case AssetLoadFailed:
+		logger.Error("Failed to load asset", append(attrs, slog.Any("error", event.Err))...)
+	case AssetUnloaded:
+		logger.Debug("Unloaded asset", attrs...)
+	case AssetReloaded:
+		logger.Info("Reloaded asset", append(attrs, slog.Duration("duration", event.Duration), slog.Int64("bytes", event.Bytes))...)
+	case AssetEvicted:
+		logger.Debug("Evicted asset", attrs...)
+	}
+}
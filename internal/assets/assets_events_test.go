@@ -0,0 +1,212 @@
+package assets
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordedEvent is an asset event without its timing, which varies between runs.
+type recordedEvent struct {
+	Kind AssetEventKind
+	File AssetFile
+}
+
+func TestAssetEvents(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, "txt")
+	mapFS(t, tenByteFiles("a.txt", "b.txt"))
+
+	var events []recordedEvent
+	unsubscribe := SubscribeAssetEvents(func(event AssetEvent) {
+		events = append(events, recordedEvent{event.Kind, event.File})
+
+		switch event.Kind {
+		case AssetLoaded, AssetReloaded:
+			if event.Bytes != 10 || event.Err != nil {
+				t.Errorf("%s event of %s has %d bytes and error %v, want 10 bytes", event.Kind, event.File, event.Bytes, event.Err)
+			}
+		case AssetLoadFailed:
+			if !errors.Is(event.Err, fs.ErrNotExist) {
+				t.Errorf("load failed event of %s has error %v, want %v", event.File, event.Err, fs.ErrNotExist)
+			}
+		}
+	})
+
+	var other int
+	unsubscribeOther := SubscribeAssetEvents(func(AssetEvent) { other++ })
+	defer unsubscribeOther()
+
+	if unsubscribeNil := SubscribeAssetEvents(nil); unsubscribeNil == nil {
+		t.Fatal("SubscribeAssetEvents(nil) returned no unsubscribe function")
+	}
+
+	steps := []struct {
+		name string
+		run  func() error
+		want []recordedEvent
+	}{
+		{
+			name: "load",
+			run:  func() error { return LoadAssets("assets/a.txt") },
+			want: []recordedEvent{{AssetLoadStarted, "assets/a.txt"}, {AssetLoaded, "assets/a.txt"}},
+		},
+		{
+			name: "load failed",
+			run: func() error {
+				if err := LoadAssets("assets/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+					return err
+				}
+				return nil
+			},
+			want: []recordedEvent{{AssetLoadStarted, "assets/missing.txt"}, {AssetLoadFailed, "assets/missing.txt"}},
+		},
+		{
+			name: "reload",
+			run:  func() error { return ReloadAsset("assets/a.txt") },
+			want: []recordedEvent{{AssetReloaded, "assets/a.txt"}},
+		},
+		{
+			name: "evict",
+			run: func() error {
+				if err := ConfigureAssetCache(AssetCacheOptions{Budget: 15}); err != nil {
+					return err
+				}
+				return LoadAssets("assets/b.txt")
+			},
+			want: []recordedEvent{{AssetLoadStarted, "assets/b.txt"}, {AssetLoaded, "assets/b.txt"}, {AssetEvicted, "assets/a.txt"}},
+		},
+		{
+			name: "unload",
+			run:  func() error { return UnloadAssets("assets/b.txt") },
+			want: []recordedEvent{{AssetUnloaded, "assets/b.txt"}},
+		},
+	}
+
+	total := 0
+	for _, step := range steps {
+		events = nil
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if !slices.Equal(events, step.want) {
+			t.Fatalf("%s events = %v, want %v", step.name, events, step.want)
+		}
+		total += len(step.want)
+	}
+
+	if other != total {
+		t.Fatalf("second subscriber received %d events, want %d", other, total)
+	}
+
+	unsubscribe()
+	unsubscribe()
+
+	events = nil
+	if err := LoadAssets("assets/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("events after unsubscribing = %v, want none", events)
+	}
+	if other != total+2 {
+		t.Fatalf("second subscriber received %d events, want %d after unsubscribing the first", other, total+2)
+	}
+}
+
+func TestAssetLogger(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, "txt")
+	mapFS(t, tenByteFiles("a.txt"))
+
+	var buf bytes.Buffer
+	SetAssetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if err := LoadAssets("assets/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReloadAsset("assets/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	LoadAssets("assets/missing.txt")
+
+	want := []string{
+		`level=DEBUG msg="Loading asset" file=assets/a.txt`,
+		`level=DEBUG msg="Loaded asset" file=assets/a.txt duration=`,
+		`level=INFO msg="Reloaded asset" file=assets/a.txt duration=`,
+		`level=ERROR msg="Failed to load asset" file=assets/missing.txt error=`,
+	}
+	for _, line := range want {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("log is missing %q:\n%s", line, buf.String())
+		}
+	}
+	if !strings.Contains(buf.String(), "bytes=10") {
+		t.Errorf("log is missing the loaded bytes:\n%s", buf.String())
+	}
+
+	SetAssetLogger(nil)
+	assetEventsMu.Lock()
+	logger := assetLogger
+	assetEventsMu.Unlock()
+	if logger != slog.Default() {
+		t.Fatal("SetAssetLogger(nil) did not restore the default logger")
+	}
+}
+
+func TestGetAssetLoadReport(t *testing.T) {
+	resetAssets(t)
+
+	events := []AssetEvent{
+		{Kind: AssetLoaded, File: "assets/a.txt", Duration: 10 * time.Millisecond, Bytes: 100},
+		{Kind: AssetLoaded, File: "assets/b.txt", Duration: 30 * time.Millisecond, Bytes: 50},
+		{Kind: AssetLoaded, File: "assets/c.txt", Duration: 20 * time.Millisecond, Bytes: 10},
+		{Kind: AssetLoadFailed, File: "assets/d.txt", Duration: 40 * time.Millisecond},
+		{Kind: AssetUnloaded, File: "assets/c.txt"},
+		// A reload replaces the file's previous timing.
+		{Kind: AssetReloaded, File: "assets/a.txt", Duration: 5 * time.Millisecond, Bytes: 120},
+	}
+	for _, event := range events {
+		emitAssetEvent(event)
+	}
+
+	assetGroupsMu.Lock()
+	assetGroupsLoaded["ui"] = []AssetFile{"assets/a.txt", "assets/b.txt"}
+	assetGroupsLoaded["level"] = []AssetFile{"assets/c.txt", "assets/d.txt"}
+	assetGroupsMu.Unlock()
+
+	report := GetAssetLoadReport(2)
+
+	if report.Count != 3 || report.Total != 55*time.Millisecond || report.Bytes != 180 {
+		t.Fatalf("report totals = %d files, %v, %d bytes, want 3 files, 55ms, 180 bytes", report.Count, report.Total, report.Bytes)
+	}
+
+	slowest := make([]AssetFile, 0, len(report.Slowest))
+	for _, timing := range report.Slowest {
+		slowest = append(slowest, timing.File)
+	}
+	if want := []AssetFile{"assets/b.txt", "assets/c.txt"}; !slices.Equal(slowest, want) {
+		t.Fatalf("slowest = %v, want %v", slowest, want)
+	}
+
+	if report.Groups["ui"] != 35*time.Millisecond || report.Groups["level"] != 20*time.Millisecond {
+		t.Fatalf("group totals = %v, want ui 35ms and level 20ms", report.Groups)
+	}
+
+	if got := len(GetAssetLoadReport(-1).Slowest); got != 0 {
+		t.Fatalf("report of -1 slowest lists %d files", got)
+	}
+	if got := len(GetAssetLoadReport(10).Slowest); got != 3 {
+		t.Fatalf("report of 10 slowest lists %d files, want every file", got)
+	}
+
+	ResetAssetLoadReport()
+	if report := GetAssetLoadReport(2); report.Count != 0 || report.Total != 0 || len(report.Slowest) != 0 {
+		t.Fatalf("report after reset = %+v, want empty", report)
+	}
+}
@@ -0,0 +1,160 @@
+package assets
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ======================================================
+// Asset Fallbacks
+// ======================================================
+
+// AssetFallbackFunc returns a placeholder for an asset file that failed to load.
+//
+// The placeholder is shared by every failed file it is returned for, and is never passed to
+// the importer's CleanupAssetFile.
+type AssetFallbackFunc func(file AssetFile) any
+
+// AssetFailure records an asset file that failed to load.
+type AssetFailure struct {
+	File AssetFile
+	Err  error
+}
+
+// assetFailure is the failure record of an asset file, tracking whether its fallback has been reported.
+//
+// The modification time and layer of the file when it failed, if it existed, let hot reload load it
+// again once it changes.
+type assetFailure struct {
+	err      error
+	reported bool
+	modTime  time.Time
+	layer    string
+}
+
+var (
+	assetFallbacks = make(map[AssetType]AssetFallbackFunc)
+	assetsFailed   = make(map[AssetFile]*assetFailure)
+)
+
+// SetAssetFallback sets the placeholder returned by GetAsset for files of an asset type that failed to load,
+// overriding the FallbackAsset of the type's importer. A nil fallback restores the importer's.
+func SetAssetFallback(t AssetType, fallback AssetFallbackFunc) error {
+	t = NewAssetType(t.String())
+	if err := t.IsValid(); err != nil {
+		return &AssetError{Op: "fallback", Err: err}
+	}
+
+	assetsMu.Lock()
+	defer assetsMu.Unlock()
+
+	if fallback == nil {
+		delete(assetFallbacks, t)
+	} else {
+		assetFallbacks[t] = fallback
+	}
+
+	return nil
+}
+
+func MustSetAssetFallback(t AssetType, fallback AssetFallbackFunc) {
+	if err := SetAssetFallback(t, fallback); err != nil {
+		panic(err)
+	}
+}
+
+// FailedAssets returns the asset files whose most recent load failed, sorted by path.
+func FailedAssets() []AssetFailure {
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+
+	failures := make([]AssetFailure, 0, len(assetsFailed))
+	for file, failure := range assetsFailed {
+		failures = append(failures, AssetFailure{File: file, Err: failure.err})
+	}
+
+	slices.SortFunc(failures, func(a, b AssetFailure) int {
+		return strings.Compare(a.File.Path(), b.File.Path())
+	})
+
+	return failures
+}
+
+func IsAssetFailed(file AssetFile) bool {
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+
+	_, failed := assetsFailed[file]
+	return failed
+}
+
+// ClearFailedAssets forgets every recorded load failure. GetAsset stops returning fallbacks for the
+// cleared files until they fail again.
+func ClearFailedAssets() {
+	assetsMu.Lock()
+	defer assetsMu.Unlock()
+
+	clear(assetsFailed)
+}
+
+// recordAssetFailure records the result of loading an asset file. A nil error clears its failure.
+func recordAssetFailure(file AssetFile, err error) {
+	failure := &assetFailure{err: err}
+
+	if err != nil {
+		if src, err := resolveAssetFile(file); err == nil {
+			if info, err := src.stat(); err == nil {
+				failure.modTime = info.ModTime()
+				failure.layer = src.layer
+			}
+		}
+	}
+
+	assetsMu.Lock()
+	defer assetsMu.Unlock()
+
+	if err == nil {
+		delete(assetsFailed, file)
+		return
+	}
+
+	assetsFailed[file] = failure
+}
+
+// getAssetFallback returns the fallback of a failed asset file, logging the first time it is substituted.
+func getAssetFallback(file AssetFile) (any, bool) {
+	assetsMu.Lock()
+	failure, failed := assetsFailed[file]
+	if !failed {
+		assetsMu.Unlock()
+		return nil, false
+	}
+
+	fallback, exists := assetFallbacks[file.Type()]
+	if !exists {
+		if manager, ok := getAssetImporter(file.Type()); ok {
+			fallback = manager.FallbackAsset
+		}
+	}
+	if fallback == nil {
+		assetsMu.Unlock()
+		return nil, false
+	}
+
+	report := !failure.reported
+	failure.reported = true
+	err := failure.err
+	assetsMu.Unlock()
+
+	if report {
+		assetEventsMu.Lock()
+		logger := assetLogger
+		assetEventsMu.Unlock()
+
+		logger.Warn("Using fallback asset", slog.String("file", file.Path()), slog.Any("error", err))
+	}
+
+	return fallback(file), true
+}
@@ -0,0 +1,169 @@
+package assets
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var errBadAsset = errors.New("bad asset")
+
+// fallbackImporter registers an importer of types that loads files as strings, fails on files
+// reading "bad", and falls back to "fallback". It counts the files it processes and cleans up.
+func fallbackImporter(t *testing.T, types ...AssetType) (processed, cleanups *atomic.Int32) {
+	t.Helper()
+
+	processed, cleanups = &atomic.Int32{}, &atomic.Int32{}
+	err := RegisterAssetImporter(&AssetImporter{
+		AssetTypes: types,
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			processed.Add(1)
+			if string(data) == "bad" {
+				return nil, errBadAsset
+			}
+			return string(data), nil
+		},
+		CleanupAssetFile: func(file AssetFile, data any) error {
+			cleanups.Add(1)
+			return nil
+		},
+		FallbackAsset: func(file AssetFile) any {
+			return "fallback"
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return processed, cleanups
+}
+
+func TestAssetFallback(t *testing.T) {
+	resetAssets(t)
+	_, cleanups := fallbackImporter(t, "txt")
+	textImporter(t, "dat")
+	mapFS(t, fstest.MapFS{"bad.txt": {Data: []byte("bad")}})
+
+	var log bytes.Buffer
+	SetAssetLogger(slog.New(slog.NewTextHandler(&log, nil)))
+
+	if err := LoadAssets("assets/missing.txt", "assets/bad.txt", "assets/missing.dat"); err == nil {
+		t.Fatal("LoadAssets() of failing files succeeded")
+	}
+
+	failures := FailedAssets()
+	if len(failures) != 3 {
+		t.Fatalf("FailedAssets() = %v, want 3 failures", failures)
+	}
+	want := []struct {
+		file AssetFile
+		err  error
+	}{
+		{file: "assets/bad.txt", err: errBadAsset},
+		{file: "assets/missing.dat", err: fs.ErrNotExist},
+		{file: "assets/missing.txt", err: fs.ErrNotExist},
+	}
+	for i, w := range want {
+		if failures[i].File != w.file || !errors.Is(failures[i].Err, w.err) {
+			t.Errorf("failure %d = %s: %v, want %s: %v", i, failures[i].File, failures[i].Err, w.file, w.err)
+		}
+		if !IsAssetFailed(w.file) {
+			t.Errorf("IsAssetFailed(%s) = false", w.file)
+		}
+	}
+
+	for _, file := range []AssetFile{"assets/missing.txt", "assets/bad.txt", "assets/missing.txt"} {
+		if got, err := GetAsset[string](file); err != nil || got != "fallback" {
+			t.Fatalf("GetAsset(%s) = %q, %v, want the importer's fallback", file, got, err)
+		}
+	}
+	if got := strings.Count(log.String(), "Using fallback asset"); got != 2 {
+		t.Fatalf("fallback warnings = %d, want one per file:\n%s", got, log.String())
+	}
+
+	// Importers without a fallback return the error.
+	if _, err := GetAsset[string]("assets/missing.dat"); !errors.Is(err, ErrAssetNotLoaded) {
+		t.Fatalf("GetAsset() without a fallback error = %v, want %v", err, ErrAssetNotLoaded)
+	}
+
+	MustSetAssetFallback("TXT", func(file AssetFile) any { return "override " + file.Path() })
+	if got := MustGetAsset[string]("assets/bad.txt"); got != "override assets/bad.txt" {
+		t.Fatalf("GetAsset() with an override = %q", got)
+	}
+	MustSetAssetFallback("txt", nil)
+	if got := MustGetAsset[string]("assets/bad.txt"); got != "fallback" {
+		t.Fatalf("GetAsset() after removing the override = %q, want the importer's fallback", got)
+	}
+	if err := SetAssetFallback("", nil); err == nil {
+		t.Fatal("SetAssetFallback() of an empty type succeeded")
+	}
+
+	// Unloading a failed file clears its failure, and fallbacks are never cleaned up.
+	if err := UnloadAssets("assets/missing.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if IsAssetFailed("assets/missing.txt") || cleanups.Load() != 0 {
+		t.Fatalf("after unload, failed = %v and %d cleanups, want neither", IsAssetFailed("assets/missing.txt"), cleanups.Load())
+	}
+	if _, err := GetAsset[string]("assets/missing.txt"); !errors.Is(err, ErrAssetNotLoaded) {
+		t.Fatalf("GetAsset() after unload error = %v, want %v", err, ErrAssetNotLoaded)
+	}
+
+	ClearFailedAssets()
+	if failures := FailedAssets(); len(failures) != 0 {
+		t.Fatalf("FailedAssets() after clear = %v", failures)
+	}
+}
+
+func TestAssetFallbackReload(t *testing.T) {
+	resetAssets(t)
+	processed, _ := fallbackImporter(t, "txt")
+	files := mapFS(t, fstest.MapFS{"bad.txt": {Data: []byte("bad"), ModTime: time.Unix(100, 0)}})
+
+	if err := LoadAssets("assets/missing.txt", "assets/bad.txt"); !errors.Is(err, errBadAsset) || !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("LoadAssets() error = %v, want both failures", err)
+	}
+
+	// Files that failed and have not changed are not loaded again.
+	processed.Store(0)
+	if err := PollAssetChanges(); err != nil {
+		t.Fatal(err)
+	}
+	if processed.Load() != 0 || !IsAssetFailed("assets/bad.txt") || !IsAssetFailed("assets/missing.txt") {
+		t.Fatalf("poll of unchanged failures processed %d files", processed.Load())
+	}
+
+	// A reload of a fixed file loads it.
+	files["missing.txt"] = &fstest.MapFile{Data: []byte("found")}
+	if err := ReloadAsset("assets/missing.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if IsAssetFailed("assets/missing.txt") || MustGetAsset[string]("assets/missing.txt") != "found" {
+		t.Fatalf("after reload, failed = %v and data %q", IsAssetFailed("assets/missing.txt"), MustGetAsset[string]("assets/missing.txt"))
+	}
+
+	// Hot reload loads a failed file again once it changes, and keeps it failed while it is still bad.
+	files["bad.txt"] = &fstest.MapFile{Data: []byte("bad"), ModTime: time.Unix(200, 0)}
+	if err := PollAssetChanges(); !errors.Is(err, errBadAsset) {
+		t.Fatalf("PollAssetChanges() error = %v, want %v", err, errBadAsset)
+	}
+	if err := PollAssetChanges(); err != nil {
+		t.Fatalf("PollAssetChanges() of a failure seen before error = %v", err)
+	}
+
+	files["bad.txt"] = &fstest.MapFile{Data: []byte("good"), ModTime: time.Unix(300, 0)}
+	if err := PollAssetChanges(); err != nil {
+		t.Fatal(err)
+	}
+	if failures := FailedAssets(); len(failures) != 0 {
+		t.Fatalf("FailedAssets() after fixing every file = %v", failures)
+	}
+	if got := MustGetAsset[string]("assets/bad.txt"); got != "good" {
+		t.Fatalf("GetAsset() after the fix = %q, want the fixed file", got)
+	}
+}
@@ -0,0 +1,355 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+var (
+	ErrAssetHashMismatch         = errors.New("asset hash mismatch")
+	ErrAssetHashNotListed        = errors.New("asset is not listed in the hash manifest")
+	ErrAssetHashManifestNil      = errors.New("asset hash manifest is nil")
+	ErrAssetVerifyModeInvalid    = errors.New("asset verify mode is invalid")
+	ErrAssetVerificationDisabled = errors.New("asset verification is disabled")
+)
+
+// ======================================================
+// Asset Hash
+// ======================================================
+
+// AssetHash is the hex encoded SHA-256 hash of the contents of an asset file.
+type AssetHash string
+
+// HashAssetData returns the content hash of asset file data.
+func HashAssetData(data []byte) AssetHash {
+	sum := sha256.Sum256(data)
+	return AssetHash(hex.EncodeToString(sum[:]))
+}
+
+func (h AssetHash) String() string {
+	return string(h)
+}
+
+// Short returns the first 12 characters of the hash, enough to stamp the version of a file in a
+// URL or cache key.
+func (h AssetHash) Short() string {
+	if len(h) <= 12 {
+		return string(h)
+	}
+	return string(h[:12])
+}
+
+// AssetStamp records the content hash and size in bytes of an asset file.
+//
+// Streamed files are hashed only while verification is on, so their stamps otherwise have an empty hash.
+type AssetStamp struct {
+	Hash AssetHash `json:"hash" yaml:"hash"`
+	Size int64     `json:"size" yaml:"size"`
+}
+
+// NewAssetStamp returns the stamp of asset file data.
+func NewAssetStamp(data []byte) AssetStamp {
+	return AssetStamp{Hash: HashAssetData(data), Size: int64(len(data))}
+}
+
+var assetStamps = make(map[AssetFile]AssetStamp)
+
+// GetAssetStamp returns the content hash and size of a loaded asset file, as they were when it was last
+// loaded or reloaded. Evicted files keep their stamp until they are unloaded.
+func GetAssetStamp(file AssetFile) (AssetStamp, error) {
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+
+	stamp, exists := assetStamps[file]
+	if !exists {
+		return AssetStamp{}, NewAssetError("stamp", file, ErrAssetNotLoaded)
+	}
+	return stamp, nil
+}
+
+func MustGetAssetStamp(file AssetFile) AssetStamp {
+	stamp, err := GetAssetStamp(file)
+	if err != nil {
+		panic(err)
+	}
+	return stamp
+}
+
+// GetAssetStamps returns the stamps of every loaded asset file.
+func GetAssetStamps() map[AssetFile]AssetStamp {
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+
+	return maps.Clone(assetStamps)
+}
+
+// ======================================================
+// Asset Hash Manifest
+// ======================================================
+
+// AssetHashManifest maps asset paths to the content hash and size of the files of a build.
+//
+//	version: 1.4.0
+//	files:
+//	  assets/ui/button.png:
+//	    hash: 3f0a...
+//	    size: 1422
+type AssetHashManifest struct {
+	Version string                   `json:"version" yaml:"version"`
+	Files   map[AssetFile]AssetStamp `json:"files" yaml:"files"`
+}
+
+// AssetHashDiff lists the files that differ between two hash manifests, in lexical order.
+type AssetHashDiff struct {
+	Added   []AssetFile
+	Changed []AssetFile
+	Removed []AssetFile
+}
+
+// IsEmpty reports whether the manifests list the same files with the same contents.
+func (d AssetHashDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// BuildAssetHashManifest hashes the asset files matching the given paths or glob patterns (see path.Match),
+// read through the registered asset filesystems.
+func BuildAssetHashManifest(version string, patterns ...string) (*AssetHashManifest, error) {
+	files, err := resolveAssetPatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &AssetHashManifest{
+		Version: version,
+		Files:   make(map[AssetFile]AssetStamp, len(files)),
+	}
+
+	errs := make([]error, 0)
+	for _, file := range files {
+		data, err := readAssetFileUnverified(file)
+		if err != nil {
+			errs = append(errs, NewAssetError("hash", file, err))
+			continue
+		}
+		manifest.Files[file.Base()] = NewAssetStamp(data)
+	}
+
+	return manifest, errors.Join(errs...)
+}
+
+// ParseAssetHashManifest decodes a hash manifest from data. The format is selected by the asset type, which must be json, yaml or yml.
+func ParseAssetHashManifest(t AssetType, data []byte) (*AssetHashManifest, error) {
+	manifest := &AssetHashManifest{}
+
+	if err := DecodeAssetData(t, data, manifest); err != nil {
+		if errors.Is(err, ErrAssetDataFormatUnsupported) {
+			return nil, &AssetError{Op: "parse hash manifest", Type: t, Err: ErrAssetManifestInvalidType}
+		}
+		return nil, err
+	}
+
+	if manifest.Files == nil {
+		manifest.Files = make(map[AssetFile]AssetStamp)
+	}
+
+	return manifest, nil
+}
+
+// ReadAssetHashManifest reads and decodes a hash manifest file through the registered asset filesystems.
+//
+// The manifest file itself is never verified.
+func ReadAssetHashManifest(file AssetFile) (*AssetHashManifest, error) {
+	data, err := readAssetFileUnverified(file)
+	if err != nil {
+		return nil, NewAssetError("read hash manifest", file, err)
+	}
+
+	manifest, err := ParseAssetHashManifest(file.Type(), data)
+	if err != nil {
+		return nil, NewAssetError("read hash manifest", file, err)
+	}
+
+	return manifest, nil
+}
+
+// Lookup returns the stamp the manifest lists for an asset file, ignoring its fragment.
+func (m *AssetHashManifest) Lookup(file AssetFile) (AssetStamp, bool) {
+	stamp, exists := m.Files[file.Base()]
+	return stamp, exists
+}
+
+// Verify checks asset file data against the stamp the manifest lists for the file. Files that are not
+// listed pass.
+func (m *AssetHashManifest) Verify(file AssetFile, data []byte) error {
+	return m.verifyStamp(file, NewAssetStamp(data))
+}
+
+func (m *AssetHashManifest) verifyStamp(file AssetFile, actual AssetStamp) error {
+	expected, exists := m.Lookup(file)
+	if !exists {
+		return nil
+	}
+
+	if actual != expected {
+		return fmt.Errorf("%w: expected %s (%d bytes), got %s (%d bytes)",
+			ErrAssetHashMismatch, expected.Hash.Short(), expected.Size, actual.Hash.Short(), actual.Size)
+	}
+
+	return nil
+}
+
+// Diff returns the files added, changed and removed since a previous manifest, such as the files
+// a patch has to ship. A nil previous manifest lists nothing.
+func (m *AssetHashManifest) Diff(previous *AssetHashManifest) AssetHashDiff {
+	diff := AssetHashDiff{}
+	if previous == nil {
+		previous = &AssetHashManifest{}
+	}
+
+	for file, stamp := range m.Files {
+		before, exists := previous.Files[file]
+		switch {
+		case !exists:
+			diff.Added = append(diff.Added, file)
+		case before != stamp:
+			diff.Changed = append(diff.Changed, file)
+		}
+	}
+
+	for file := range previous.Files {
+		if _, exists := m.Files[file]; !exists {
+			diff.Removed = append(diff.Removed, file)
+		}
+	}
+
+	slices.Sort(diff.Added)
+	slices.Sort(diff.Changed)
+	slices.Sort(diff.Removed)
+
+	return diff
+}
+
+// ======================================================
+// Asset Verification
+// ======================================================
+
+// AssetVerifyMode selects which asset files are verified against the hash manifest as they are read.
+type AssetVerifyMode int
+
+const (
+	// AssetVerifyOff reads every file without verifying it.
+	AssetVerifyOff AssetVerifyMode = iota
+	// AssetVerifyListed refuses files whose contents differ from the manifest, and reads files it does not list.
+	AssetVerifyListed
+	// AssetVerifyStrict refuses files whose contents differ from the manifest, and files it does not list.
+	AssetVerifyStrict
+)
+
+func (m AssetVerifyMode) String() string {
+	switch m {
+	case AssetVerifyOff:
+		return "off"
+	case AssetVerifyListed:
+		return "listed"
+	case AssetVerifyStrict:
+		return "strict"
+	default:
+		return "unknown"
+	}
+}
+
+func (m AssetVerifyMode) IsValid() bool {
+	return m >= AssetVerifyOff && m <= AssetVerifyStrict
+}
+
+var (
+	assetHashManifest   *AssetHashManifest
+	assetHashVerifyMode = AssetVerifyOff
+	assetHashMu         = sync.RWMutex{}
+)
+
+// SetAssetVerification verifies every asset file read from now on against a hash manifest, including
+// files read by importers such as atlas pages and font sidecars. Files that fail verification fail
+// to load with ErrAssetHashMismatch, or ErrAssetHashNotListed in strict mode.
+//
+// AssetVerifyOff turns verification off, and the manifest may be nil.
+func SetAssetVerification(manifest *AssetHashManifest, mode AssetVerifyMode) error {
+	if !mode.IsValid() {
+		return &AssetError{Op: "configure", Err: fmt.Errorf("%w: %d", ErrAssetVerifyModeInvalid, mode)}
+	}
+	if manifest == nil && mode != AssetVerifyOff {
+		return &AssetError{Op: "configure", Err: ErrAssetHashManifestNil}
+	}
+
+	assetHashMu.Lock()
+	defer assetHashMu.Unlock()
+
+	assetHashManifest = manifest
+	assetHashVerifyMode = mode
+
+	return nil
+}
+
+func MustSetAssetVerification(manifest *AssetHashManifest, mode AssetVerifyMode) {
+	if err := SetAssetVerification(manifest, mode); err != nil {
+		panic(err)
+	}
+}
+
+// AssetVerification returns the hash manifest and mode asset files are verified with.
+func AssetVerification() (*AssetHashManifest, AssetVerifyMode) {
+	assetHashMu.RLock()
+	defer assetHashMu.RUnlock()
+
+	return assetHashManifest, assetHashVerifyMode
+}
+
+// VerifyAssetFiles reads asset files and checks them against the hash manifest, without loading them.
+// If no files are given, every file the manifest lists is checked, which detects a corrupted install
+// up front. Verification must be on, or ErrAssetVerificationDisabled is returned.
+func VerifyAssetFiles(files ...AssetFile) error {
+	manifest, mode := AssetVerification()
+	if mode == AssetVerifyOff {
+		return &AssetError{Op: "verify", Err: ErrAssetVerificationDisabled}
+	}
+
+	if len(files) == 0 {
+		files = slices.Sorted(maps.Keys(manifest.Files))
+	}
+
+	errs := make([]error, 0)
+	for _, file := range files {
+		if _, err := ReadAssetFile(file); err != nil {
+			errs = append(errs, NewAssetError("verify", file, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// verifyAssetData checks asset file data against the hash manifest, if verification is on.
+func verifyAssetData(file AssetFile, data []byte) error {
+	if _, mode := AssetVerification(); mode == AssetVerifyOff {
+		return nil
+	}
+	return verifyAssetStamp(file, NewAssetStamp(data))
+}
+
+// verifyAssetStamp checks the stamp of an asset file against the hash manifest, if verification is on.
+func verifyAssetStamp(file AssetFile, stamp AssetStamp) error {
+	manifest, mode := AssetVerification()
+	if mode == AssetVerifyOff {
+		return nil
+	}
+
+	if _, listed := manifest.Lookup(file); !listed && mode == AssetVerifyStrict {
+		return ErrAssetHashNotListed
+	}
+
+	return manifest.verifyStamp(file, stamp)
+}
@@ -0,0 +1,214 @@
+package assets
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestHashAssetData(t *testing.T) {
+	hash := HashAssetData(nil)
+	if hash != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Fatalf("HashAssetData(nil) = %s, want the SHA-256 of no data", hash)
+	}
+	if hash.Short() != "e3b0c44298fc" || AssetHash("abc").Short() != "abc" {
+		t.Fatalf("Short() = %s, %s", hash.Short(), AssetHash("abc").Short())
+	}
+
+	if stamp := NewAssetStamp([]byte("hello")); stamp.Size != 5 || stamp.Hash != HashAssetData([]byte("hello")) {
+		t.Fatalf("NewAssetStamp() = %+v", stamp)
+	}
+}
+
+func TestBuildAssetHashManifest(t *testing.T) {
+	resetAssets(t)
+	mapFS(t, fstest.MapFS{
+		"a.txt":    {Data: []byte("a")},
+		"b.txt":    {Data: []byte("b")},
+		"ui/c.png": {Data: []byte("c")},
+	})
+
+	manifest, err := BuildAssetHashManifest("1.0", "assets/*.txt", "assets/ui/c.png#frame")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[AssetFile]AssetStamp{
+		"assets/a.txt":    NewAssetStamp([]byte("a")),
+		"assets/b.txt":    NewAssetStamp([]byte("b")),
+		"assets/ui/c.png": NewAssetStamp([]byte("c")),
+	}
+	if manifest.Version != "1.0" || !reflect.DeepEqual(manifest.Files, want) {
+		t.Fatalf("BuildAssetHashManifest() = %+v, want %v", manifest, want)
+	}
+	if stamp, ok := manifest.Lookup("assets/ui/c.png#other"); !ok || stamp != want["assets/ui/c.png"] {
+		t.Fatalf("Lookup() of a fragment = %+v, %v, want the stamp of its file", stamp, ok)
+	}
+
+	if _, err := BuildAssetHashManifest("1.0", "assets/a.txt", "assets/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("BuildAssetHashManifest() of a missing file error = %v, want %v", err, fs.ErrNotExist)
+	}
+
+	formats := []struct {
+		format  AssetType
+		marshal func(v any) ([]byte, error)
+	}{
+		{format: "json", marshal: json.Marshal},
+		{format: "yaml", marshal: yaml.Marshal},
+	}
+
+	for _, tt := range formats {
+		data, err := tt.marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := ParseAssetHashManifest(tt.format, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(parsed, manifest) {
+			t.Fatalf("%s round trip = %+v, want %+v", tt.format, parsed, manifest)
+		}
+	}
+}
+
+func TestAssetVerification(t *testing.T) {
+	manifest := &AssetHashManifest{Files: map[AssetFile]AssetStamp{
+		"assets/good.txt":    NewAssetStamp([]byte("good")),
+		"assets/changed.txt": NewAssetStamp([]byte("original")),
+	}}
+
+	tests := []struct {
+		mode AssetVerifyMode
+		file AssetFile
+		err  error
+	}{
+		{mode: AssetVerifyOff, file: "assets/good.txt"},
+		{mode: AssetVerifyOff, file: "assets/changed.txt"},
+		{mode: AssetVerifyOff, file: "assets/unlisted.txt"},
+		{mode: AssetVerifyListed, file: "assets/good.txt"},
+		{mode: AssetVerifyListed, file: "assets/changed.txt", err: ErrAssetHashMismatch},
+		{mode: AssetVerifyListed, file: "assets/unlisted.txt"},
+		{mode: AssetVerifyStrict, file: "assets/good.txt"},
+		{mode: AssetVerifyStrict, file: "assets/changed.txt", err: ErrAssetHashMismatch},
+		{mode: AssetVerifyStrict, file: "assets/unlisted.txt", err: ErrAssetHashNotListed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String()+" "+tt.file.Path(), func(t *testing.T) {
+			resetAssets(t)
+			textImporter(t, "txt")
+			mapFS(t, fstest.MapFS{
+				"good.txt":     {Data: []byte("good")},
+				"changed.txt":  {Data: []byte("modified")},
+				"unlisted.txt": {Data: []byte("unlisted")},
+			})
+
+			if err := SetAssetVerification(manifest, tt.mode); err != nil {
+				t.Fatal(err)
+			}
+
+			err := LoadAssets(tt.file)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("LoadAssets() error = %v, want %v", err, tt.err)
+			}
+			if IsAssetLoaded(tt.file) != (tt.err == nil) {
+				t.Fatalf("IsAssetLoaded() = %v with error %v", IsAssetLoaded(tt.file), err)
+			}
+
+			if tt.mode == AssetVerifyOff {
+				return
+			}
+			if err := VerifyAssetFiles(tt.file); !errors.Is(err, tt.err) {
+				t.Fatalf("VerifyAssetFiles() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestVerifyAssetFilesListed(t *testing.T) {
+	resetAssets(t)
+	mapFS(t, fstest.MapFS{"good.txt": {Data: []byte("good")}})
+
+	manifest := &AssetHashManifest{Files: map[AssetFile]AssetStamp{
+		"assets/good.txt": NewAssetStamp([]byte("good")),
+		"assets/gone.txt": NewAssetStamp([]byte("gone")),
+	}}
+	MustSetAssetVerification(manifest, AssetVerifyListed)
+
+	err := VerifyAssetFiles()
+
+	var assetErr *AssetError
+	if !errors.Is(err, fs.ErrNotExist) || !errors.As(err, &assetErr) || assetErr.File != "assets/gone.txt" {
+		t.Fatalf("VerifyAssetFiles() error = %v, want the listed file missing from the install", err)
+	}
+	if IsAssetLoaded("assets/good.txt") {
+		t.Fatal("VerifyAssetFiles() loaded a file")
+	}
+}
+
+func TestAssetHashManifestVerify(t *testing.T) {
+	manifest := &AssetHashManifest{Files: map[AssetFile]AssetStamp{"assets/a.txt": NewAssetStamp([]byte("original"))}}
+
+	if err := manifest.Verify("assets/a.txt", []byte("original")); err != nil {
+		t.Fatal(err)
+	}
+	if err := manifest.Verify("assets/b.txt", []byte("anything")); err != nil {
+		t.Fatalf("Verify() of an unlisted file error = %v, want it to pass", err)
+	}
+
+	err := manifest.Verify("assets/a.txt#frame", []byte("modified!"))
+	if !errors.Is(err, ErrAssetHashMismatch) {
+		t.Fatalf("Verify() error = %v, want %v", err, ErrAssetHashMismatch)
+	}
+	for _, part := range []string{NewAssetStamp([]byte("original")).Hash.Short(), "(8 bytes)", NewAssetStamp([]byte("modified!")).Hash.Short(), "(9 bytes)"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("Verify() error %q does not name %q", err, part)
+		}
+	}
+}
+
+func TestAssetHashManifestDiff(t *testing.T) {
+	stamp := func(data string) AssetStamp { return NewAssetStamp([]byte(data)) }
+
+	previous := &AssetHashManifest{Version: "1.0", Files: map[AssetFile]AssetStamp{
+		"assets/a.txt": stamp("a"),
+		"assets/b.txt": stamp("b"),
+		"assets/c.txt": stamp("c"),
+		"assets/e.txt": stamp("e"),
+	}}
+	current := &AssetHashManifest{Version: "1.1", Files: map[AssetFile]AssetStamp{
+		"assets/a.txt": stamp("a"),
+		"assets/b.txt": stamp("b2"),
+		"assets/d.txt": stamp("d"),
+		"assets/f.txt": stamp("f"),
+		"assets/e.txt": {Hash: stamp("e").Hash, Size: 2},
+	}}
+
+	diff := current.Diff(previous)
+	want := AssetHashDiff{
+		Added:   []AssetFile{"assets/d.txt", "assets/f.txt"},
+		Changed: []AssetFile{"assets/b.txt", "assets/e.txt"},
+		Removed: []AssetFile{"assets/c.txt"},
+	}
+	if !reflect.DeepEqual(diff, want) || diff.IsEmpty() {
+		t.Fatalf("Diff() = %+v, want %+v", diff, want)
+	}
+
+	if diff := previous.Diff(previous); !diff.IsEmpty() {
+		t.Fatalf("Diff() of the same manifest = %+v, want empty", diff)
+	}
+
+	added := current.Diff(nil).Added
+	if want := slices.Sorted(maps.Keys(current.Files)); !slices.Equal(added, want) {
+		t.Fatalf("Diff(nil) added %v, want every file", added)
+	}
+}
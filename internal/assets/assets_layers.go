@@ -0,0 +1,440 @@
+package assets
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrAssetPathMappingInvalid = errors.New("asset path mapping is invalid")
+)
+
+// ======================================================
+// Asset Path Mapping
+// ======================================================
+
+// AssetPathMapping selects how asset paths map to locations within a mounted filesystem.
+type AssetPathMapping int
+
+const (
+	// AssetPathDetect maps paths as AssetPathRooted if the filesystem contains a directory named after
+	// the root, as embedded filesystems and archives of the root directory do, and as AssetPathRelative otherwise.
+	AssetPathDetect AssetPathMapping = iota
+	// AssetPathRelative strips the root, so "assets/ui/button.png" is read as "ui/button.png".
+	AssetPathRelative
+	// AssetPathRooted keeps the root, so "assets/ui/button.png" is read as "assets/ui/button.png".
+	AssetPathRooted
+)
+
+func (m AssetPathMapping) String() string {
+	switch m {
+	case AssetPathDetect:
+		return "detect"
+	case AssetPathRelative:
+		return "relative"
+	case AssetPathRooted:
+		return "rooted"
+	default:
+		return "unknown"
+	}
+}
+
+func (m AssetPathMapping) IsValid() bool {
+	return m >= AssetPathDetect && m <= AssetPathRooted
+}
+
+// detectAssetPathMapping resolves AssetPathDetect for a filesystem mounted at an asset root.
+func detectAssetPathMapping(root AssetRoot, filesystem fs.FS) AssetPathMapping {
+	if info, err := fs.Stat(filesystem, root.String()); err == nil && info.IsDir() {
+		return AssetPathRooted
+	}
+	return AssetPathRelative
+}
+
+// ======================================================
+// Asset Filesystem Layers
+// ======================================================
+
+// assetLayer is a filesystem mounted into the stack of an asset root.
+//
+// The closer is set for filesystems the package opened itself, such as archives, and is closed
+// when the layer is unmounted.
+type assetLayer struct {
+	name     string
+	priority int
+	fs       fs.FS
+	mapping  AssetPathMapping
+	closer   io.Closer
+}
+
+var (
+	assetFilesystems   = make(map[AssetRoot][]*assetLayer)
+	assetFilesystemsMu = sync.RWMutex{}
+)
+
+// MountAssetFilesystem adds a named filesystem layer to the stack of an asset root.
+//
+// Lookups fall through the stack from the highest priority layer to the lowest, so a
+// layer can override individual files of the layers below it. Layers with equal priority
+// are searched in the order they were mounted, most recent first.
+//
+//	finch.RegisterAssetFilesystem("assets", baseFS)               // "assets", priority 0
+//	finch.MountAssetFilesystem("assets", "dlc", 10, dlcArchive)
+//	finch.MountAssetFilesystem("assets", "mods", 20, os.DirFS(modDir))
+//
+// Asset paths are mapped into the filesystem as AssetPathDetect. Use MountAssetFilesystemMapped to
+// choose the mapping.
+func MountAssetFilesystem(root AssetRoot, layer string, priority int, filesystem fs.FS) error {
+	return MountAssetFilesystemMapped(root, layer, priority, filesystem, AssetPathDetect)
+}
+
+func MustMountAssetFilesystem(root AssetRoot, layer string, priority int, filesystem fs.FS) {
+	if err := MountAssetFilesystem(root, layer, priority, filesystem); err != nil {
+		panic(err)
+	}
+}
+
+// MountAssetFilesystemMapped adds a named filesystem layer to the stack of an asset root, mapping asset
+// paths into it as the given mapping selects.
+func MountAssetFilesystemMapped(root AssetRoot, layer string, priority int, filesystem fs.FS, mapping AssetPathMapping) error {
+	return mountAssetLayer(root, &assetLayer{name: layer, priority: priority, fs: filesystem, mapping: mapping})
+}
+
+func MustMountAssetFilesystemMapped(root AssetRoot, layer string, priority int, filesystem fs.FS, mapping AssetPathMapping) {
+	if err := MountAssetFilesystemMapped(root, layer, priority, filesystem, mapping); err != nil {
+		panic(err)
+	}
+}
+
+func mountAssetLayer(root AssetRoot, mounted *assetLayer) error {
+	layer, priority := mounted.name, mounted.priority
+
+	if err := root.IsValid(); err != nil {
+		return &AssetError{Op: "mount", Err: err}
+	}
+
+	if mounted.fs == nil {
+		return &AssetError{Op: "mount", Err: fmt.Errorf("%w: %s: %s", ErrAssetFilesystemNil, root, layer)}
+	}
+
+	if !mounted.mapping.IsValid() {
+		return &AssetError{Op: "mount", Err: fmt.Errorf("%w: %s: %s: %d", ErrAssetPathMappingInvalid, root, layer, mounted.mapping)}
+	}
+
+	if mounted.mapping == AssetPathDetect {
+		mounted.mapping = detectAssetPathMapping(root, mounted.fs)
+	}
+
+	assetFilesystemsMu.Lock()
+	defer assetFilesystemsMu.Unlock()
+
+	layers := assetFilesystems[root]
+
+	for _, l := range layers {
+		if l.name == layer {
+			return &AssetError{Op: "mount", Err: fmt.Errorf("%w: %s: %s", ErrAssetFilesystemConflict, root, layer)}
+		}
+	}
+
+	i := slices.IndexFunc(layers, func(l *assetLayer) bool {
+		return l.priority <= priority
+	})
+	if i < 0 {
+		i = len(layers)
+	}
+
+	assetFilesystems[root] = slices.Insert(layers, i, mounted)
+
+	return nil
+}
+
+// UnmountAssetFilesystem removes a named filesystem layer from the stack of an asset root.
+//
+// Loaded assets are not affected until they are reloaded. Archives mounted with MountAssetArchive
+// are closed.
+func UnmountAssetFilesystem(root AssetRoot, layer string) error {
+	assetFilesystemsMu.Lock()
+	defer assetFilesystemsMu.Unlock()
+
+	layers := assetFilesystems[root]
+
+	i := slices.IndexFunc(layers, func(l *assetLayer) bool {
+		return l.name == layer
+	})
+	if i < 0 {
+		return &AssetError{Op: "unmount", Err: fmt.Errorf("%w: %s: %s", ErrAssetLayerNotFound, root, layer)}
+	}
+
+	unmounted := layers[i]
+
+	layers = slices.Delete(layers, i, i+1)
+	if len(layers) == 0 {
+		delete(assetFilesystems, root)
+	} else {
+		assetFilesystems[root] = layers
+	}
+
+	if unmounted.closer != nil {
+		if err := unmounted.closer.Close(); err != nil {
+			return &AssetError{Op: "unmount", Err: fmt.Errorf("%s: %s: %w", root, layer, err)}
+		}
+	}
+
+	return nil
+}
+
+// AssetFilesystemLayers returns the layer names of an asset root, highest priority first.
+func AssetFilesystemLayers(root AssetRoot) []string {
+	assetFilesystemsMu.RLock()
+	defer assetFilesystemsMu.RUnlock()
+
+	names := make([]string, 0, len(assetFilesystems[root]))
+	for _, layer := range assetFilesystems[root] {
+		names = append(names, layer.name)
+	}
+	return names
+}
+
+// AssetFileLayer returns the name of the layer that served a loaded asset file.
+//
+// The name is empty if the file was read directly from disk.
+func AssetFileLayer(file AssetFile) (string, error) {
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+
+	entry, exists := assetCache[file]
+	if !exists {
+		return "", NewAssetError("layer", file, ErrAssetNotLoaded)
+	}
+
+	return entry.layer, nil
+}
+
+// ResolveAssetFileLayer returns the name of the layer that would currently serve an asset file.
+//
+// The name is empty if no filesystem is registered for the file's root.
+func ResolveAssetFileLayer(file AssetFile) (string, error) {
+	src, err := resolveAssetFile(file)
+	if err != nil {
+		return "", NewAssetError("resolve", file, err)
+	}
+	return src.layer, nil
+}
+
+// ======================================================
+// Asset Sources
+// ======================================================
+
+// assetSource locates an asset file within a filesystem layer, or on disk when fs is nil.
+//
+// A source resolved to be loaded holds the open file, so that remote filesystems serve its stat
+// and its contents from a single response. The file is closed once it is read, or handed over
+// when it is streamed; close releases it otherwise.
+type assetSource struct {
+	layer string
+	fs    fs.FS
+	path  string
+	info  fs.FileInfo
+	file  fs.File
+}
+
+func (s *assetSource) stat() (fs.FileInfo, error) {
+	if s.info != nil {
+		return s.info, nil
+	}
+
+	var err error
+	switch {
+	case s.file != nil:
+		s.info, err = s.file.Stat()
+	case s.fs == nil:
+		s.info, err = os.Stat(s.path)
+	default:
+		s.info, err = fs.Stat(s.fs, s.path)
+	}
+
+	return s.info, err
+}
+
+func (s *assetSource) read() ([]byte, error) {
+	if s.file != nil {
+		defer s.close()
+		return io.ReadAll(s.file)
+	}
+	if s.fs == nil {
+		return os.ReadFile(s.path)
+	}
+	return fs.ReadFile(s.fs, s.path)
+}
+
+// open opens the file as a stream, spooling it to a temporary file if its filesystem cannot seek.
+func (s *assetSource) open() (AssetStream, error) {
+	f := s.file
+	s.file = nil
+
+	if f == nil {
+		var err error
+		if s.fs == nil {
+			f, err = os.Open(s.path)
+		} else {
+			f, err = s.fs.Open(s.path)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if stream, ok := f.(AssetStream); ok {
+		return stream, nil
+	}
+	defer f.Close()
+
+	return spoolAssetStream(f)
+}
+
+// close closes the open file of the source, if it has not been read or streamed.
+func (s *assetSource) close() error {
+	if s.file == nil {
+		return nil
+	}
+	f := s.file
+	s.file = nil
+	return f.Close()
+}
+
+// resolveAssetFile returns the source of the highest priority layer that contains the asset file.
+//
+// If no filesystem is registered for the file's root, the source reads the path directly from disk.
+// Layers are probed with fs.Stat, and the info of the layer that contains the file is kept for stat.
+// Only layers that do not have the file are skipped; any other error, such as a permission or network
+// error, is returned, so a lower layer never silently serves a file a higher layer failed to read.
+func resolveAssetFile(file AssetFile) (*assetSource, error) {
+	root := file.Root()
+	layers := rootLayers(root)
+
+	if len(layers) == 0 {
+		return &assetSource{path: file.cleanPath()}, nil
+	}
+
+	for _, layer := range layers {
+		fpath := layer.fsPath(root, file.cleanPath())
+		info, err := fs.Stat(layer.fs, fpath)
+		switch {
+		case err == nil:
+			return &assetSource{layer: layer.name, fs: layer.fs, path: fpath, info: info}, nil
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, layerError(layer, file, err)
+		}
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: file.Path(), Err: fs.ErrNotExist}
+}
+
+// openAssetFile returns the source of the highest priority layer that contains the asset file, with
+// the file open. Layers are probed by opening the file, so that one request serves a remote file.
+// Errors other than fs.ErrNotExist stop the probe, as in resolveAssetFile.
+//
+// The caller must close the source.
+func openAssetFile(file AssetFile) (*assetSource, error) {
+	root := file.Root()
+	layers := rootLayers(root)
+
+	if len(layers) == 0 {
+		f, err := os.Open(file.cleanPath())
+		if err != nil {
+			return nil, err
+		}
+		return &assetSource{path: file.cleanPath(), file: f}, nil
+	}
+
+	for _, layer := range layers {
+		fpath := layer.fsPath(root, file.cleanPath())
+		f, err := layer.fs.Open(fpath)
+		switch {
+		case err == nil:
+			return &assetSource{layer: layer.name, fs: layer.fs, path: fpath, file: f}, nil
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, layerError(layer, file, err)
+		}
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: file.Path(), Err: fs.ErrNotExist}
+}
+
+// layerError wraps an error of a layer that failed to probe an asset file.
+func layerError(layer *assetLayer, file AssetFile, err error) error {
+	return NewAssetError("open", file, fmt.Errorf("layer %q: %w", layer.name, err))
+}
+
+// ReadAssetFile reads an asset file and verifies it against the hash manifest, if verification is on.
+func ReadAssetFile(file AssetFile) ([]byte, error) {
+	data, err := readAssetFileUnverified(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyAssetData(file, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// AssetFileExists reports whether an asset file can be read from its root, without reading it.
+func AssetFileExists(file AssetFile) (bool, error) {
+	src, err := resolveAssetFile(file)
+	if err == nil {
+		_, err = src.stat()
+	}
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, err
+	default:
+		return true, nil
+	}
+}
+
+func readAssetFileUnverified(file AssetFile) ([]byte, error) {
+	src, err := openAssetFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return src.read()
+}
+
+// rootLayers returns a snapshot of the layer stack of an asset root.
+func rootLayers(root AssetRoot) []*assetLayer {
+	assetFilesystemsMu.RLock()
+	defer assetFilesystemsMu.RUnlock()
+
+	return slices.Clone(assetFilesystems[root])
+}
+
+// fsPath maps an asset path to its location within the layer's filesystem.
+func (l *assetLayer) fsPath(root AssetRoot, fpath string) string {
+	fpath = strings.TrimPrefix(fpath, root.String())
+	fpath = strings.TrimPrefix(fpath, "/")
+
+	if l.mapping == AssetPathRooted {
+		fpath = path.Join(root.String(), fpath)
+	}
+	return fpath
+}
+
+// assetFile maps a location within the layer's filesystem back to its asset file.
+func (l *assetLayer) assetFile(root AssetRoot, fpath string) AssetFile {
+	if l.mapping == AssetPathRooted {
+		fpath = strings.TrimPrefix(fpath, root.String()+"/")
+	}
+	return AssetFile(root.String() + "/" + fpath)
+}
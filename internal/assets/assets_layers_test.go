@@ -0,0 +1,113 @@
+package assets
+
+import (
+	"errors"
+	"io/fs"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+// openCountFS counts the files opened from a filesystem. It implements only fs.FS, so every stat and
+// read of a file goes through Open.
+type openCountFS struct {
+	fs    fs.FS
+	opens atomic.Int32
+}
+
+func (f *openCountFS) Open(name string) (fs.File, error) {
+	f.opens.Add(1)
+	return f.fs.Open(name)
+}
+
+func TestLoadAssetsOpensFileOnce(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, "txt")
+
+	files := &openCountFS{fs: tenByteFiles("a.txt")}
+	if err := RegisterAssetFilesystem("assets", files); err != nil {
+		t.Fatal(err)
+	}
+
+	files.opens.Store(0)
+	if err := LoadAssets("assets/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got := files.opens.Load(); got != 1 {
+		t.Fatalf("load opened the file %d times, want 1", got)
+	}
+
+	files.opens.Store(0)
+	if err := ReloadAsset("assets/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got := files.opens.Load(); got != 1 {
+		t.Fatalf("reload opened the file %d times, want 1", got)
+	}
+}
+
+func TestLoadAssetsSkipsLayersWithoutFile(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, "txt")
+	mapFS(t, fstest.MapFS{"a.txt": {Data: []byte("base")}})
+
+	if err := MountAssetFilesystem("assets", "mod", 1, fstest.MapFS{"b.txt": {Data: []byte("mod")}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadAssets("assets/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := GetAsset[string]("assets/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layer, _ := AssetFileLayer("assets/a.txt"); data != "base" || layer == "mod" {
+		t.Fatalf("loaded %q from layer %q, want the base layer", data, layer)
+	}
+}
+
+// errFS fails every open with an error.
+type errFS struct {
+	err error
+}
+
+func (f errFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: f.err}
+}
+
+func TestLayerErrorsStopTheProbe(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, "txt")
+	mapFS(t, fstest.MapFS{"a.txt": {Data: []byte("base")}})
+
+	if err := MountAssetFilesystem("assets", "remote", 1, errFS{err: fs.ErrPermission}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := LoadAssets("assets/a.txt")
+	var assetErr *AssetError
+	if !errors.Is(err, fs.ErrPermission) || !errors.As(err, &assetErr) {
+		t.Fatalf("LoadAssets() error = %v, want the permission error of the remote layer", err)
+	}
+	if IsAssetLoaded("assets/a.txt") {
+		t.Fatal("the base layer served a file the remote layer failed to open")
+	}
+
+	if _, err := AssetFileExists("assets/a.txt"); !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("AssetFileExists() error = %v, want %v", err, fs.ErrPermission)
+	}
+	if _, err := ResolveAssetFileLayer("assets/a.txt"); !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("ResolveAssetFileLayer() error = %v, want %v", err, fs.ErrPermission)
+	}
+
+	if err := UnmountAssetFilesystem("assets", "remote"); err != nil {
+		t.Fatal(err)
+	}
+	if err := MountAssetFilesystem("assets", "remote", 1, errFS{err: fs.ErrNotExist}); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadAssets("assets/a.txt"); err != nil {
+		t.Fatalf("LoadAssets() error = %v, want layers without the file skipped", err)
+	}
+}
@@ -0,0 +1,401 @@
+package assets
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/adm87/finch-core/hashset"
+)
+
+var (
+	ErrAssetGroupNotFound        = errors.New("asset group not found")
+	ErrAssetGroupConflict        = errors.New("asset group conflict")
+	ErrAssetGroupNotLoaded       = errors.New("asset group not loaded")
+	ErrAssetManifestNil          = errors.New("asset manifest is nil")
+	ErrAssetManifestInvalidType  = errors.New("asset manifest has invalid type")
+	ErrAssetManifestFileNotFound = errors.New("asset manifest file not found")
+	ErrAssetManifestNoMatches    = errors.New("asset manifest pattern matched no files")
+)
+
+// ======================================================
+// Asset Manifest
+// ======================================================
+
+// AssetManifest defines named groups of asset files.
+//
+// Each group is a list of asset paths or glob patterns (see path.Match). Patterns are
+// resolved against the filesystem registered for their root, or against the disk if
+// no filesystem is registered.
+//
+//	groups:
+//	  common:
+//	    - assets/fonts/*.ttf
+//	  ui:
+//	    - assets/ui/*.png
+//	  level1:
+//	    - assets/levels/level1/*.json
+//	    - assets/levels/level1/tiles.png
+type AssetManifest struct {
+	Groups map[string][]string `json:"groups" yaml:"groups"`
+}
+
+// ParseAssetManifest decodes a manifest from data. The format is selected by the asset type, which must be json, yaml or yml.
+func ParseAssetManifest(t AssetType, data []byte) (*AssetManifest, error) {
+	manifest := &AssetManifest{}
+
+	if err := DecodeAssetData(t, data, manifest); err != nil {
+		if errors.Is(err, ErrAssetDataFormatUnsupported) {
+			return nil, &AssetError{Op: "parse manifest", Type: t, Err: ErrAssetManifestInvalidType}
+		}
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// ReadAssetManifest reads and decodes a manifest file through the registered asset filesystems.
+func ReadAssetManifest(file AssetFile) (*AssetManifest, error) {
+	data, err := ReadAssetFile(file)
+	if err != nil {
+		return nil, NewAssetError("read manifest", file, err)
+	}
+
+	manifest, err := ParseAssetManifest(file.Type(), data)
+	if err != nil {
+		return nil, NewAssetError("read manifest", file, err)
+	}
+
+	return manifest, nil
+}
+
+// Resolve expands the patterns of a group into the asset files they match.
+func (m *AssetManifest) Resolve(group string) ([]AssetFile, error) {
+	patterns, exists := m.Groups[group]
+	if !exists {
+		return nil, &AssetError{Op: "resolve", Err: fmt.Errorf("%w: %s", ErrAssetGroupNotFound, group)}
+	}
+	return resolveAssetPatterns(patterns)
+}
+
+// Validate checks that every pattern of every group matches at least one file, and
+// that every matched file has a registered AssetImporter.
+func (m *AssetManifest) Validate() error {
+	errs := make([]error, 0)
+
+	groups := make([]string, 0, len(m.Groups))
+	for group := range m.Groups {
+		groups = append(groups, group)
+	}
+	slices.Sort(groups)
+
+	for _, group := range groups {
+		for _, pattern := range m.Groups[group] {
+			files, err := matchAssetPattern(pattern)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("group %s: %w", group, err))
+				continue
+			}
+
+			if len(files) == 0 {
+				if isAssetPattern(pattern) {
+					errs = append(errs, fmt.Errorf("group %s: %w", group, &AssetError{Op: "validate", File: AssetFile(pattern), Err: ErrAssetManifestNoMatches}))
+				} else {
+					errs = append(errs, fmt.Errorf("group %s: %w", group, &AssetError{Op: "validate", File: AssetFile(pattern), Err: ErrAssetManifestFileNotFound}))
+				}
+				continue
+			}
+
+			for _, file := range files {
+				if !HasAssetTypeSupport(file.Type()) {
+					errs = append(errs, fmt.Errorf("group %s: %w", group, NewAssetError("validate", file, ErrAssetManagerNotFound)))
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ======================================================
+// Asset Groups
+// ======================================================
+
+var (
+	assetGroups       = make(map[string][]string)
+	assetGroupsLoaded = make(map[string][]AssetFile)
+	assetGroupsMu     = sync.Mutex{}
+
+	// assetGroupRefs counts the loaded groups that reference each file a group load loaded. Files that
+	// were already loaded when their group was, such as files loaded with LoadAssets, are not counted
+	// and are never unloaded by UnloadGroup.
+	//
+	// Group loads and unloads are serialized by assetGroupLoadsMu, so the files one owns are not
+	// claimed by another. It is held while files load, unlike assetGroupsMu, which importers and event
+	// subscribers may need.
+	assetGroupRefs    = make(map[AssetFile]int)
+	assetGroupLoadsMu = sync.Mutex{}
+)
+
+// RegisterAssetManifest makes the groups of a manifest available to LoadGroup and UnloadGroup.
+//
+// Group names are global; registering a group that already exists is an error.
+func RegisterAssetManifest(manifest *AssetManifest) error {
+	if manifest == nil {
+		return &AssetError{Op: "register", Err: ErrAssetManifestNil}
+	}
+
+	assetGroupsMu.Lock()
+	defer assetGroupsMu.Unlock()
+
+	for group := range manifest.Groups {
+		if _, exists := assetGroups[group]; exists {
+			return &AssetError{Op: "register", Err: fmt.Errorf("%w: %s", ErrAssetGroupConflict, group)}
+		}
+	}
+
+	for group, patterns := range manifest.Groups {
+		assetGroups[group] = slices.Clone(patterns)
+	}
+
+	return nil
+}
+
+func MustRegisterAssetManifest(manifest *AssetManifest) {
+	if err := RegisterAssetManifest(manifest); err != nil {
+		panic(err)
+	}
+}
+
+// LoadGroup resolves the patterns of a registered group and loads every matched asset file.
+//
+// Files that are already loaded, for example because they are shared with another group, are skipped.
+// Files of other groups that are no longer in the cache, because they were unloaded directly or evicted,
+// are loaded again.
+// If any file fails to load, the files this call loaded are unloaded again and the group is not
+// loaded. Loading a group that is already loaded does nothing.
+func LoadGroup(group string) error {
+	files, err := ResolveGroup(group)
+	if err != nil {
+		return err
+	}
+
+	assetGroupLoadsMu.Lock()
+	defer assetGroupLoadsMu.Unlock()
+
+	if IsGroupLoaded(group) {
+		return nil
+	}
+
+	if err := buildAssetRequests(hashset.New[AssetFile](), files); err != nil {
+		return err
+	}
+
+	owned := make([]AssetFile, 0, len(files))
+	errs := make([]error, 0)
+	for _, file := range files {
+		// A file another group owns may have been unloaded directly or evicted since, and is then
+		// loaded again instead of being skipped.
+		_, shared := assetGroupRefs[file]
+		if shared && IsAssetLoaded(file) {
+			owned = append(owned, file)
+			continue
+		}
+
+		err := loadAssetFile(file)
+		if errors.Is(err, ErrAssetIsLoaded) && !shared {
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrAssetIsLoaded) {
+			errs = append(errs, err)
+			continue
+		}
+		if !shared {
+			assetGroupRefs[file] = 0
+		}
+		owned = append(owned, file)
+	}
+
+	if len(errs) > 0 {
+		rollback := make([]AssetFile, 0, len(owned))
+		for _, file := range owned {
+			if assetGroupRefs[file] == 0 {
+				delete(assetGroupRefs, file)
+				rollback = append(rollback, file)
+			}
+		}
+		if err := UnloadAssets(rollback...); err != nil {
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, file := range owned {
+		assetGroupRefs[file]++
+	}
+
+	assetGroupsMu.Lock()
+	assetGroupsLoaded[group] = files
+	assetGroupsMu.Unlock()
+
+	return nil
+}
+
+func MustLoadGroup(group string) {
+	if err := LoadGroup(group); err != nil {
+		panic(err)
+	}
+}
+
+// UnloadGroup unloads the asset files the group loaded.
+//
+// Files that also belong to another loaded group remain loaded, and so do files that were already
+// loaded when the group was.
+func UnloadGroup(group string) error {
+	assetGroupLoadsMu.Lock()
+	defer assetGroupLoadsMu.Unlock()
+
+	assetGroupsMu.Lock()
+	files, loaded := assetGroupsLoaded[group]
+	delete(assetGroupsLoaded, group)
+	assetGroupsMu.Unlock()
+
+	if !loaded {
+		return &AssetError{Op: "unload", Err: fmt.Errorf("%w: %s", ErrAssetGroupNotLoaded, group)}
+	}
+
+	released := make([]AssetFile, 0, len(files))
+	for _, file := range files {
+		refs, owned := assetGroupRefs[file]
+		if !owned {
+			continue
+		}
+		if refs > 1 {
+			assetGroupRefs[file]--
+			continue
+		}
+		delete(assetGroupRefs, file)
+		released = append(released, file)
+	}
+
+	// Files unloaded directly since the group loaded them are skipped.
+	assetsMu.RLock()
+	pending := make([]AssetFile, 0, len(released))
+	for _, file := range released {
+		if _, exists := assetCache[file]; exists || assetsEvicted.Contains(file) {
+			pending = append(pending, file)
+		}
+	}
+	assetsMu.RUnlock()
+
+	return UnloadAssets(pending...)
+}
+
+func MustUnloadGroup(group string) {
+	if err := UnloadGroup(group); err != nil {
+		panic(err)
+	}
+}
+
+// ResolveGroup expands the patterns of a registered group into the asset files they match.
+//
+// Plain paths are always included, so missing files surface as load errors.
+func ResolveGroup(group string) ([]AssetFile, error) {
+	assetGroupsMu.Lock()
+	patterns, exists := assetGroups[group]
+	assetGroupsMu.Unlock()
+
+	if !exists {
+		return nil, &AssetError{Op: "resolve", Err: fmt.Errorf("%w: %s", ErrAssetGroupNotFound, group)}
+	}
+
+	return resolveAssetPatterns(patterns)
+}
+
+func IsGroupLoaded(group string) bool {
+	assetGroupsMu.Lock()
+	defer assetGroupsMu.Unlock()
+
+	_, loaded := assetGroupsLoaded[group]
+	return loaded
+}
+
+func resolveAssetPatterns(patterns []string) ([]AssetFile, error) {
+	files := make([]AssetFile, 0, len(patterns))
+	seen := hashset.New[AssetFile]()
+	errs := make([]error, 0)
+
+	for _, pattern := range patterns {
+		if !isAssetPattern(pattern) {
+			if file := AssetFile(pattern); !seen.Contains(file) {
+				seen.Add(file)
+				files = append(files, file)
+			}
+			continue
+		}
+
+		matches, err := matchAssetPattern(pattern)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, file := range matches {
+			if !seen.Contains(file) {
+				seen.Add(file)
+				files = append(files, file)
+			}
+		}
+	}
+
+	return files, errors.Join(errs...)
+}
+
+// matchAssetPattern returns the existing asset files matching a pattern, in lexical order.
+//
+// Matches are merged across every filesystem layer of the pattern's root.
+func matchAssetPattern(pattern string) ([]AssetFile, error) {
+	root := AssetFile(pattern).Root()
+	layers := rootLayers(root)
+
+	if len(layers) == 0 {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, &AssetError{Op: "resolve", File: AssetFile(pattern), Err: err}
+		}
+		files := make([]AssetFile, 0, len(matches))
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() {
+				files = append(files, AssetFile(filepath.ToSlash(match)))
+			}
+		}
+		return files, nil
+	}
+
+	matched := hashset.New[AssetFile]()
+	for _, layer := range layers {
+		matches, err := fs.Glob(layer.fs, layer.fsPath(root, pattern))
+		if err != nil {
+			return nil, &AssetError{Op: "resolve", File: AssetFile(pattern), Err: err}
+		}
+		for _, match := range matches {
+			if info, err := fs.Stat(layer.fs, match); err == nil && !info.IsDir() {
+				matched.Add(layer.assetFile(root, match))
+			}
+		}
+	}
+
+	files := matched.ToSlice()
+	slices.Sort(files)
+
+	return files, nil
+}
+
+func isAssetPattern(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
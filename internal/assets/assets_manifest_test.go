@@ -0,0 +1,178 @@
+package assets
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAssetGroups(t *testing.T) {
+	files := fstest.MapFS{
+		"ui/button.txt": {Data: []byte("button")},
+		"ui/panel.txt":  {Data: []byte("panel")},
+		"shared.txt":    {Data: []byte("shared")},
+		"own.txt":       {Data: []byte("own")},
+	}
+	groups := map[string][]string{
+		"ui":     {"assets/ui/*.txt", "assets/shared.txt"},
+		"level":  {"assets/shared.txt", "assets/own.txt"},
+		"broken": {"assets/ui/button.txt", "assets/missing.txt"},
+	}
+
+	type step struct {
+		op    string // "load", "unload", "evict", "group", "ungroup"
+		name  string
+		err   error
+		check map[AssetFile]bool
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "load and unload",
+			steps: []step{
+				{op: "group", name: "ui", check: map[AssetFile]bool{"assets/ui/button.txt": true, "assets/shared.txt": true}},
+				{op: "ungroup", name: "ui", check: map[AssetFile]bool{"assets/ui/button.txt": false, "assets/shared.txt": false}},
+			},
+		},
+		{
+			name: "shared files stay until the last group unloads",
+			steps: []step{
+				{op: "group", name: "ui"},
+				{op: "group", name: "level"},
+				{op: "ungroup", name: "ui", check: map[AssetFile]bool{"assets/ui/panel.txt": false, "assets/shared.txt": true}},
+				{op: "ungroup", name: "level", check: map[AssetFile]bool{"assets/shared.txt": false, "assets/own.txt": false}},
+			},
+		},
+		{
+			name: "files loaded directly are not owned",
+			steps: []step{
+				{op: "load", name: "assets/shared.txt"},
+				{op: "group", name: "ui"},
+				{op: "ungroup", name: "ui", check: map[AssetFile]bool{"assets/ui/button.txt": false, "assets/shared.txt": true}},
+				{op: "unload", name: "assets/shared.txt", check: map[AssetFile]bool{"assets/shared.txt": false}},
+			},
+		},
+		{
+			name: "failed load rolls back",
+			steps: []step{
+				{op: "group", name: "broken", err: fs.ErrNotExist, check: map[AssetFile]bool{"assets/ui/button.txt": false}},
+				{op: "ungroup", name: "broken", err: ErrAssetGroupNotLoaded},
+			},
+		},
+		{
+			name: "failed load keeps files of other groups",
+			steps: []step{
+				{op: "group", name: "ui"},
+				{op: "group", name: "broken", err: fs.ErrNotExist, check: map[AssetFile]bool{"assets/ui/button.txt": true}},
+				{op: "ungroup", name: "ui", check: map[AssetFile]bool{"assets/ui/button.txt": false}},
+			},
+		},
+		{
+			name: "loading twice is a no-op",
+			steps: []step{
+				{op: "group", name: "ui"},
+				{op: "group", name: "ui"},
+				{op: "ungroup", name: "ui", check: map[AssetFile]bool{"assets/ui/button.txt": false}},
+				{op: "ungroup", name: "ui", err: ErrAssetGroupNotLoaded},
+			},
+		},
+		{
+			name: "files unloaded directly are skipped",
+			steps: []step{
+				{op: "group", name: "ui"},
+				{op: "unload", name: "assets/shared.txt"},
+				{op: "ungroup", name: "ui", check: map[AssetFile]bool{"assets/ui/panel.txt": false}},
+			},
+		},
+		{
+			name: "shared files unloaded directly are loaded again",
+			steps: []step{
+				{op: "group", name: "ui"},
+				{op: "unload", name: "assets/shared.txt"},
+				{op: "group", name: "level", check: map[AssetFile]bool{"assets/shared.txt": true}},
+				{op: "ungroup", name: "level", check: map[AssetFile]bool{"assets/shared.txt": true, "assets/own.txt": false}},
+				{op: "ungroup", name: "ui", check: map[AssetFile]bool{"assets/shared.txt": false}},
+			},
+		},
+		{
+			name: "shared files evicted are loaded again",
+			steps: []step{
+				{op: "group", name: "ui"},
+				{op: "evict", check: map[AssetFile]bool{"assets/shared.txt": false}},
+				{op: "group", name: "level", check: map[AssetFile]bool{"assets/shared.txt": true, "assets/own.txt": true}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAssets(t)
+			mapFS(t, files)
+			RegisterAssetImporter(&AssetImporter{
+				AssetTypes: []AssetType{"txt"},
+				ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+					return string(data), nil
+				},
+			})
+			if err := RegisterAssetManifest(&AssetManifest{Groups: groups}); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, step := range tt.steps {
+				var err error
+				switch step.op {
+				case "load":
+					err = LoadAssets(AssetFile(step.name))
+				case "unload":
+					err = UnloadAssets(AssetFile(step.name))
+				case "evict":
+					err = errors.Join(ConfigureAssetCache(AssetCacheOptions{Budget: 1}), ConfigureAssetCache(AssetCacheOptions{}))
+				case "group":
+					err = LoadGroup(step.name)
+				case "ungroup":
+					err = UnloadGroup(step.name)
+				}
+
+				if step.err == nil && err != nil || !errors.Is(err, step.err) {
+					t.Fatalf("step %d: %s %s error = %v, want %v", i, step.op, step.name, err, step.err)
+				}
+				for file, loaded := range step.check {
+					if IsAssetLoaded(file) != loaded {
+						t.Fatalf("step %d: IsAssetLoaded(%s) = %t, want %t", i, file, !loaded, loaded)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestResolveGroup(t *testing.T) {
+	resetAssets(t)
+	mapFS(t, fstest.MapFS{
+		"ui/a.png": {},
+		"ui/b.png": {},
+		"ui/c.txt": {},
+	})
+
+	err := RegisterAssetManifest(&AssetManifest{Groups: map[string][]string{
+		"ui": {"assets/ui/*.png", "assets/ui/a.png", "assets/ui/missing.png"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ResolveGroup("ui")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []AssetFile{"assets/ui/a.png", "assets/ui/b.png", "assets/ui/missing.png"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("ResolveGroup() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,192 @@
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ======================================================
+// Asset Ref
+// ======================================================
+
+// AssetRef is a typed handle to an asset file whose data is expected to be of type T.
+//
+// Refs are created with NewAssetRef, which checks T against the output type declared by the
+// importer of the file's type. Refs created before their importer is registered are checked
+// when it is. In data files, refs are marshalled as their path.
+//
+//	type Level struct {
+//		Tiles finch.AssetRef[*ebiten.Image] `json:"tiles"`
+//	}
+type AssetRef[T any] struct {
+	file AssetFile
+}
+
+// NewAssetRef returns a typed handle to the asset file at path.
+func NewAssetRef[T any](path string) (AssetRef[T], error) {
+	file := AssetFile(path)
+
+	if err := registerAssetRef(file, reflect.TypeFor[T]()); err != nil {
+		return AssetRef[T]{}, err
+	}
+
+	return AssetRef[T]{file: file}, nil
+}
+
+func MustAssetRef[T any](path string) AssetRef[T] {
+	ref, err := NewAssetRef[T](path)
+	if err != nil {
+		panic(err)
+	}
+	return ref
+}
+
+func (r AssetRef[T]) File() AssetFile {
+	return r.file
+}
+
+func (r AssetRef[T]) Path() string {
+	return r.file.Path()
+}
+
+func (r AssetRef[T]) String() string {
+	return r.file.Path()
+}
+
+func (r AssetRef[T]) IsZero() bool {
+	return r.file == ""
+}
+
+func (r AssetRef[T]) IsLoaded() bool {
+	return IsAssetLoaded(r.file)
+}
+
+func (r AssetRef[T]) Load() error {
+	return LoadAssets(r.file)
+}
+
+func (r AssetRef[T]) MustLoad() {
+	if err := r.Load(); err != nil {
+		panic(err)
+	}
+}
+
+func (r AssetRef[T]) Unload() error {
+	return UnloadAssets(r.file)
+}
+
+func (r AssetRef[T]) MustUnload() {
+	if err := r.Unload(); err != nil {
+		panic(err)
+	}
+}
+
+func (r AssetRef[T]) Get() (T, error) {
+	return GetAsset[T](r.file)
+}
+
+func (r AssetRef[T]) MustGet() T {
+	return MustGetAsset[T](r.file)
+}
+
+func (r AssetRef[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.file.Path())
+}
+
+func (r *AssetRef[T]) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err != nil {
+		return err
+	}
+	return r.set(path)
+}
+
+func (r AssetRef[T]) MarshalYAML() (any, error) {
+	return r.file.Path(), nil
+}
+
+func (r *AssetRef[T]) UnmarshalYAML(node *yaml.Node) error {
+	var path string
+	if err := node.Decode(&path); err != nil {
+		return err
+	}
+	return r.set(path)
+}
+
+func (r *AssetRef[T]) set(path string) error {
+	if path == "" {
+		*r = AssetRef[T]{}
+		return nil
+	}
+
+	ref, err := NewAssetRef[T](path)
+	if err != nil {
+		return err
+	}
+
+	*r = ref
+
+	return nil
+}
+
+// ======================================================
+// Asset Ref Registry
+// ======================================================
+
+var (
+	assetRefTypes   = make(map[AssetFile][]reflect.Type)
+	assetRefTypesMu = sync.Mutex{}
+)
+
+// registerAssetRef records the type a ref expects from an asset file and checks it against the file's importer.
+func registerAssetRef(file AssetFile, t reflect.Type) error {
+	if manager, exists := getAssetImporter(file.Type()); exists {
+		if err := checkAssetRefType(manager, file, t); err != nil {
+			return err
+		}
+	}
+
+	assetRefTypesMu.Lock()
+	defer assetRefTypesMu.Unlock()
+
+	if !slices.Contains(assetRefTypes[file], t) {
+		assetRefTypes[file] = append(assetRefTypes[file], t)
+	}
+
+	return nil
+}
+
+// checkAssetRefs checks every ref created so far against an importer that is being registered.
+func checkAssetRefs(manager *AssetImporter) error {
+	if manager.OutputType == nil {
+		return nil
+	}
+
+	assetRefTypesMu.Lock()
+	defer assetRefTypesMu.Unlock()
+
+	for file, types := range assetRefTypes {
+		if !slices.Contains(manager.AssetTypes, file.Type()) {
+			continue
+		}
+		for _, t := range types {
+			if err := checkAssetRefType(manager, file, t); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkAssetRefType(manager *AssetImporter, file AssetFile, t reflect.Type) error {
+	if manager.OutputType == nil || manager.OutputType.AssignableTo(t) {
+		return nil
+	}
+	return NewAssetError("ref", file, fmt.Errorf("%w: importer produces %s, ref expects %s", ErrAssetTypeMismatch, manager.OutputType, t))
+}
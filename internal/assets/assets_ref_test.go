@@ -0,0 +1,159 @@
+package assets
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"gopkg.in/yaml.v3"
+)
+
+// stringImporter registers an importer of types that loads files as strings and declares its output type.
+func stringImporter(t *testing.T, types ...AssetType) {
+	t.Helper()
+
+	err := RegisterAssetImporter(&AssetImporter{
+		AssetTypes: types,
+		OutputType: reflect.TypeFor[string](),
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			return string(data), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type refLevel struct {
+	Name  string           `json:"name" yaml:"name"`
+	Tiles AssetRef[string] `json:"tiles" yaml:"tiles"`
+	Music AssetRef[any]    `json:"music" yaml:"music"`
+}
+
+func TestAssetRefRoundTrip(t *testing.T) {
+	resetAssets(t)
+	stringImporter(t, "txt")
+
+	level := refLevel{
+		Name:  "forest",
+		Tiles: MustAssetRef[string]("assets/tiles.txt"),
+	}
+
+	formats := []struct {
+		name      string
+		marshal   func(v any) ([]byte, error)
+		unmarshal func(data []byte, v any) error
+		want      string
+	}{
+		{name: "json", marshal: json.Marshal, unmarshal: json.Unmarshal, want: `{"name":"forest","tiles":"assets/tiles.txt","music":""}`},
+		{name: "yaml", marshal: yaml.Marshal, unmarshal: yaml.Unmarshal, want: "name: forest\ntiles: assets/tiles.txt\nmusic: \"\"\n"},
+	}
+
+	for _, tt := range formats {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.marshal(level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Fatalf("marshalled %q, want %q", data, tt.want)
+			}
+
+			decoded := refLevel{Music: MustAssetRef[any]("assets/old.txt")}
+			if err := tt.unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if decoded != level {
+				t.Fatalf("decoded %+v, want %+v", decoded, level)
+			}
+			if !decoded.Music.IsZero() || decoded.Tiles.File() != "assets/tiles.txt" || decoded.Tiles.String() != "assets/tiles.txt" {
+				t.Fatalf("decoded refs = %q and %q, want the tiles path and an empty ref", decoded.Tiles, decoded.Music)
+			}
+		})
+	}
+}
+
+func TestAssetRefOutputType(t *testing.T) {
+	resetAssets(t)
+	stringImporter(t, "txt")
+
+	if _, err := NewAssetRef[string]("assets/a.txt"); err != nil {
+		t.Fatalf("NewAssetRef[string]() error = %v", err)
+	}
+	if _, err := NewAssetRef[any]("assets/a.txt"); err != nil {
+		t.Fatalf("NewAssetRef[any]() error = %v, want the output type to be assignable", err)
+	}
+	if _, err := NewAssetRef[int]("assets/other.bin"); err != nil {
+		t.Fatalf("NewAssetRef() of a type without an importer error = %v", err)
+	}
+
+	_, err := NewAssetRef[int]("assets/a.txt")
+	var assetErr *AssetError
+	if !errors.Is(err, ErrAssetTypeMismatch) || !errors.As(err, &assetErr) || assetErr.File != "assets/a.txt" {
+		t.Fatalf("NewAssetRef[int]() error = %v, want %v for the file", err, ErrAssetTypeMismatch)
+	}
+
+	var level struct {
+		Tiles AssetRef[int] `json:"tiles" yaml:"tiles"`
+	}
+	if err := json.Unmarshal([]byte(`{"tiles":"assets/a.txt"}`), &level); !errors.Is(err, ErrAssetTypeMismatch) {
+		t.Fatalf("json.Unmarshal() error = %v, want %v", err, ErrAssetTypeMismatch)
+	}
+	if err := yaml.Unmarshal([]byte("tiles: assets/a.txt\n"), &level); !errors.Is(err, ErrAssetTypeMismatch) {
+		t.Fatalf("yaml.Unmarshal() error = %v, want %v", err, ErrAssetTypeMismatch)
+	}
+	if err := json.Unmarshal([]byte(`{"tiles":3}`), &level); err == nil {
+		t.Fatal("json.Unmarshal() of a number succeeded")
+	}
+}
+
+func TestAssetRefCheckedOnRegister(t *testing.T) {
+	resetAssets(t)
+
+	if _, err := NewAssetRef[int]("assets/a.dat"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := RegisterAssetImporter(&AssetImporter{
+		AssetTypes: []AssetType{"dat"},
+		OutputType: reflect.TypeFor[string](),
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			return string(data), nil
+		},
+	})
+	if !errors.Is(err, ErrAssetTypeMismatch) {
+		t.Fatalf("RegisterAssetImporter() error = %v, want %v for the earlier ref", err, ErrAssetTypeMismatch)
+	}
+	if _, exists := getAssetImporter("dat"); exists {
+		t.Fatal("the importer was registered despite the mismatched ref")
+	}
+
+	// Importers without a declared output type accept any ref.
+	textImporter(t, "dat")
+}
+
+func TestAssetRefLoad(t *testing.T) {
+	resetAssets(t)
+	stringImporter(t, "txt")
+	mapFS(t, fstest.MapFS{"a.txt": {Data: []byte("hello")}})
+
+	ref := MustAssetRef[string]("assets/a.txt")
+	if ref.IsLoaded() {
+		t.Fatal("ref is loaded before Load")
+	}
+
+	ref.MustLoad()
+	if !ref.IsLoaded() || ref.MustGet() != "hello" {
+		t.Fatalf("ref after Load = %v, %q", ref.IsLoaded(), ref.MustGet())
+	}
+
+	ref.MustUnload()
+	if ref.IsLoaded() {
+		t.Fatal("ref is loaded after Unload")
+	}
+	if _, err := ref.Get(); !errors.Is(err, ErrAssetNotLoaded) {
+		t.Fatalf("Get() after Unload error = %v, want %v", err, ErrAssetNotLoaded)
+	}
+}
@@ -0,0 +1,280 @@
+package assets
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultAssetHotReloadInterval is the polling interval used when hot reload is enabled without one.
+const DefaultAssetHotReloadInterval = 500 * time.Millisecond
+
+// ======================================================
+// Asset Hot Reload
+// ======================================================
+
+var (
+	hotReloadEnabled  = false
+	hotReloadInterval = DefaultAssetHotReloadInterval
+	hotReloadLastPoll = time.Time{}
+	reloadMu          = sync.Mutex{}
+)
+
+// EnableAssetHotReload turns on polling of loaded asset files for modifications.
+//
+// Hot reload is a development feature. Each poll stats every loaded asset file and
+// reloads the ones whose modification time has changed. Polling is driven by the
+// application's update loop, so importers and event subscribers run on the game thread.
+func EnableAssetHotReload(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultAssetHotReloadInterval
+	}
+
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	hotReloadEnabled = true
+	hotReloadInterval = interval
+	hotReloadLastPoll = time.Time{}
+}
+
+// DisableAssetHotReload turns off polling of loaded asset files.
+func DisableAssetHotReload() {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	hotReloadEnabled = false
+}
+
+func IsAssetHotReloadEnabled() bool {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	return hotReloadEnabled
+}
+
+// PollAssetChanges checks every loaded asset file for modifications and reloads the ones that changed.
+// Files that failed to load are loaded again once they change, or once they exist.
+//
+// A file is also considered modified when a different filesystem layer now serves it, for
+// example after a mod has been mounted over its root. Files that can no longer be read are
+// skipped; they remain loaded with their previous data.
+func PollAssetChanges() error {
+	errs := make([]error, 0)
+
+	for _, file := range modifiedAssetFiles() {
+		if err := ReloadAsset(file); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ReloadAsset re-imports a loaded asset file in place.
+//
+// The importer processes the current file contents, the previous asset data is passed
+// to the importer's cleanup function, and an AssetReloaded event is emitted. The assets
+// that depend on the file are reloaded afterwards, each emitting its own event.
+//
+// A file that failed to load is loaded again, so that once it is fixed it replaces its fallback.
+func ReloadAsset(file AssetFile) error {
+	assetsMu.RLock()
+	_, loaded := assetCache[file]
+	_, failed := assetsFailed[file]
+	assetsMu.RUnlock()
+
+	if failed && !loaded {
+		return LoadAssets(file)
+	}
+
+	if err := tryReload(file); err != nil {
+		return err
+	}
+
+	errs := []error{reloadAssetFile(file)}
+
+	assetsMu.Lock()
+	assetsLoading.Remove(file)
+	assetsLoadingDone.Broadcast()
+	assetsMu.Unlock()
+
+	// Dependents retain the file again while they reload, so they must wait until it is no longer loading.
+	for _, dependent := range AssetDependents(file) {
+		errs = append(errs, ReloadAsset(dependent))
+	}
+
+	return errors.Join(errs...)
+}
+
+func MustReloadAsset(file AssetFile) {
+	if err := ReloadAsset(file); err != nil {
+		panic(err)
+	}
+}
+
+// reloadAssetFile swaps the current contents of a loaded asset file into the cache and cleans up the previous data.
+//
+// The caller must have marked the file as loading.
+func reloadAssetFile(file AssetFile) error {
+	start := time.Now()
+
+	imported, previous, err := reimportAssetFile(file)
+	if err != nil {
+		err = NewAssetError("reload", file, err)
+		emitAssetEvent(AssetEvent{Kind: AssetLoadFailed, File: file, Duration: time.Since(start), Err: err})
+		return err
+	}
+
+	var cleanupErr error
+	if manager, exists := getAssetImporter(file.Type()); exists && manager.CleanupAssetFile != nil {
+		if err := manager.CleanupAssetFile(file, previous.data); err != nil {
+			cleanupErr = NewAssetError("reload", file, fmt.Errorf("%w: %w", ErrAssetCleanupFailed, err))
+		}
+	}
+
+	var closeErr error
+	if err := closeAssetStream(previous.stream); err != nil {
+		closeErr = NewAssetError("reload", file, err)
+	}
+
+	emitAssetEvent(AssetEvent{Kind: AssetReloaded, File: file, Duration: time.Since(start), Bytes: imported.stamp.Size})
+
+	return errors.Join(cleanupErr, closeErr, evictOverBudget())
+}
+
+// reimportAssetFile processes the current contents of a loaded asset file and swaps them into the cache.
+//
+// It returns the new and previous imports of the file; the previous stream, if any, is left for the
+// caller to close after cleaning up the previous data. Errors are returned unwrapped, for the caller
+// to wrap in an AssetError.
+func reimportAssetFile(file AssetFile) (*importedAsset, *importedAsset, error) {
+	src, err := openAssetFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer src.close()
+
+	info, err := src.stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manager, exists := getAssetImporter(file.Type())
+	if !exists {
+		return nil, nil, ErrAssetManagerNotFound
+	}
+
+	// The importer retains the dependencies of the new contents, so the previous ones are set
+	// aside and released only once the new contents have replaced them.
+	assetsMu.Lock()
+	retained := assetDependencies[file]
+	delete(assetDependencies, file)
+	assetsMu.Unlock()
+
+	imported, err := processAssetSource(manager, file, src)
+	if err != nil {
+		assetsMu.Lock()
+		releaseAssetDependencies(file)
+		if len(retained) > 0 {
+			assetDependencies[file] = retained
+		}
+		assetsMu.Unlock()
+		return nil, nil, err
+	}
+
+	assetsMu.Lock()
+	defer assetsMu.Unlock()
+
+	entry, exists := assetCache[file]
+	if !exists {
+		releaseAssetDependencies(file)
+		releaseAssetDependencyRefs(retained)
+		if manager.CleanupAssetFile != nil {
+			manager.CleanupAssetFile(file, imported.data)
+		}
+		closeAssetStream(imported.stream)
+		return nil, nil, ErrAssetNotLoaded
+	}
+
+	releaseAssetDependencyRefs(retained)
+
+	previous := &importedAsset{data: entry.data, stream: entry.stream}
+
+	entry.data = imported.data
+	entry.stream = imported.stream
+	entry.modTime = info.ModTime()
+	entry.layer = src.layer
+	entry.size = imported.size
+	entry.touch()
+	assetStamps[file] = imported.stamp
+
+	return imported, previous, nil
+}
+
+// PollAssetHotReload is called once per update and polls for asset changes when the interval has elapsed.
+func PollAssetHotReload(logger *slog.Logger) {
+	reloadMu.Lock()
+	if !hotReloadEnabled || time.Since(hotReloadLastPoll) < hotReloadInterval {
+		reloadMu.Unlock()
+		return
+	}
+	hotReloadLastPoll = time.Now()
+	reloadMu.Unlock()
+
+	if err := PollAssetChanges(); err != nil {
+		logger.Warn("Failed to hot reload assets", slog.Any("error", err))
+	}
+}
+
+func modifiedAssetFiles() []AssetFile {
+	assetsMu.RLock()
+	type stamp struct {
+		modTime time.Time
+		layer   string
+	}
+
+	loaded := make(map[AssetFile]stamp, len(assetCache)+len(assetsFailed))
+	for file, entry := range assetCache {
+		loaded[file] = stamp{modTime: entry.modTime, layer: entry.layer}
+	}
+	for file, failure := range assetsFailed {
+		loaded[file] = stamp{modTime: failure.modTime, layer: failure.layer}
+	}
+	assetsMu.RUnlock()
+
+	modified := make([]AssetFile, 0)
+	for file, entry := range loaded {
+		src, err := resolveAssetFile(file)
+		if err != nil {
+			continue
+		}
+		info, err := src.stat()
+		if err != nil {
+			continue
+		}
+		if src.layer != entry.layer || !info.ModTime().Equal(entry.modTime) {
+			modified = append(modified, file)
+		}
+	}
+
+	return modified
+}
+
+func tryReload(file AssetFile) error {
+	assetsMu.Lock()
+	defer assetsMu.Unlock()
+
+	if _, exists := assetCache[file]; !exists {
+		return NewAssetError("reload", file, ErrAssetNotLoaded)
+	}
+	if assetsLoading.Contains(file) {
+		return NewAssetError("reload", file, ErrAssetIsLoading)
+	}
+
+	assetsLoading.Add(file)
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package assets
+
+import (
+	"bytes"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestPollAssetHotReload(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, "txt")
+	dependentImporter(t)
+
+	modTime := time.Unix(1000, 0)
+	files := mapFS(t, fstest.MapFS{
+		"a.txt": {Data: []byte("a"), ModTime: modTime},
+		"b.dep": {Data: []byte("assets/a.txt"), ModTime: modTime},
+		"c.txt": {Data: []byte("c"), ModTime: modTime},
+	})
+
+	if err := LoadAssets("assets/b.dep", "assets/c.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var reloaded []AssetFile
+	unsubscribe := SubscribeAssetEvents(func(event AssetEvent) {
+		if event.Kind == AssetReloaded {
+			mu.Lock()
+			reloaded = append(reloaded, event.File)
+			mu.Unlock()
+		}
+	})
+	defer unsubscribe()
+
+	var log bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&log, nil))
+
+	// poll polls once and returns the files reloaded by it, in order.
+	poll := func() []AssetFile {
+		mu.Lock()
+		reloaded = nil
+		mu.Unlock()
+
+		PollAssetHotReload(logger)
+
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(reloaded)
+	}
+
+	change := func(name, data string) {
+		modTime = modTime.Add(time.Second)
+		files[name] = &fstest.MapFile{Data: []byte(data), ModTime: modTime}
+	}
+
+	change("a.txt", "a2")
+	if got := poll(); len(got) != 0 || IsAssetHotReloadEnabled() {
+		t.Fatalf("poll while disabled reloaded %v", got)
+	}
+
+	// The first poll after enabling runs at once, and reloads the dependents of changed files.
+	EnableAssetHotReload(time.Hour)
+	if !IsAssetHotReloadEnabled() {
+		t.Fatal("hot reload is not enabled")
+	}
+	if got, want := poll(), []AssetFile{"assets/a.txt", "assets/b.dep"}; !slices.Equal(got, want) {
+		t.Fatalf("poll reloaded %v, want %v", got, want)
+	}
+	if got := MustGetAsset[string]("assets/a.txt"); got != "a2" {
+		t.Fatalf("assets/a.txt = %q after reload, want the changed contents", got)
+	}
+
+	// Polls within the interval do nothing.
+	change("c.txt", "c2")
+	if got := poll(); len(got) != 0 {
+		t.Fatalf("poll within the interval reloaded %v", got)
+	}
+
+	EnableAssetHotReload(time.Nanosecond)
+	if got, want := poll(), []AssetFile{"assets/c.txt"}; !slices.Equal(got, want) {
+		t.Fatalf("poll reloaded %v, want %v", got, want)
+	}
+	if got := poll(); len(got) != 0 {
+		t.Fatalf("poll of unchanged files reloaded %v", got)
+	}
+
+	// Touching a file without changing its contents still reloads it, since only the modification time is polled.
+	change("c.txt", "c2")
+	if got, want := poll(), []AssetFile{"assets/c.txt"}; !slices.Equal(got, want) {
+		t.Fatalf("poll of a touched file reloaded %v, want %v", got, want)
+	}
+
+	// A file served by a newly mounted layer is reloaded from it.
+	if err := MountAssetFilesystem("assets", "mod", 1, fstest.MapFS{"c.txt": {Data: []byte("mod"), ModTime: modTime}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := poll(), []AssetFile{"assets/c.txt"}; !slices.Equal(got, want) {
+		t.Fatalf("poll after mounting a layer reloaded %v, want %v", got, want)
+	}
+	if got := MustGetAsset[string]("assets/c.txt"); got != "mod" {
+		t.Fatalf("assets/c.txt = %q, want the mod's file", got)
+	}
+
+	// Failed reloads are logged, and the file keeps its previous data.
+	change("b.dep", "assets/a.txt!")
+	if got := poll(); len(got) != 0 {
+		t.Fatalf("failed poll reloaded %v", got)
+	}
+	if !strings.Contains(log.String(), "Failed to hot reload assets") || !strings.Contains(log.String(), errImportFailed.Error()) {
+		t.Fatalf("log is missing the failed reload:\n%s", log.String())
+	}
+	if got := MustGetAsset[string]("assets/b.dep"); got != "assets/a.txt" {
+		t.Fatalf("assets/b.dep = %q after a failed reload, want its previous data", got)
+	}
+
+	DisableAssetHotReload()
+	change("a.txt", "a3")
+	if got := poll(); len(got) != 0 || IsAssetHotReloadEnabled() {
+		t.Fatalf("poll after disabling reloaded %v", got)
+	}
+}
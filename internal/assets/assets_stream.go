@@ -0,0 +1,182 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// ======================================================
+// Asset Streams
+// ======================================================
+
+// AssetStream is an open asset file handed to a streaming importer.
+//
+// The stream stays open for as long as the asset is loaded, and is closed after the importer's
+// CleanupAssetFile when the asset is unloaded, evicted or reloaded. Importers must not close it.
+// Files of filesystems that cannot seek, such as compressed zip entries, are copied to a temporary
+// file and streamed from there. The temporary file is removed when the stream is closed.
+type AssetStream interface {
+	fs.File
+	io.Seeker
+}
+
+// AssetStreamAllocator is a function that takes an open asset file and converts it into a usable form,
+// reading the file as it needs it instead of all at once.
+type AssetStreamAllocator func(file AssetFile, stream AssetStream) (any, error)
+
+// importedAsset is the result of processing an asset file with its importer.
+type importedAsset struct {
+	data   any
+	stream AssetStream
+	stamp  AssetStamp
+	size   int64
+}
+
+// processAssetSource reads or opens an asset file and processes it with its importer, preferring
+// ProcessAssetStream when the importer provides it.
+//
+// Errors are returned unwrapped, for the caller to wrap in an AssetError.
+func processAssetSource(manager *AssetImporter, file AssetFile, src *assetSource) (*importedAsset, error) {
+	if manager.ProcessAssetStream != nil {
+		return processAssetStream(manager, file, src)
+	}
+
+	if manager.ProcessAssetFile == nil {
+		return nil, ErrAssetManagerNil
+	}
+
+	data, err := src.read()
+	if err != nil {
+		return nil, err
+	}
+
+	stamp := NewAssetStamp(data)
+	if err := verifyAssetStamp(file, stamp); err != nil {
+		return nil, err
+	}
+
+	asset, err := manager.ProcessAssetFile(file, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAssetImportFailed, err)
+	}
+
+	return &importedAsset{
+		data:  asset,
+		stamp: stamp,
+		size:  estimateAssetSize(manager, file, data, asset),
+	}, nil
+}
+
+// processAssetStream opens an asset file and hands it to the importer's ProcessAssetStream. When
+// verification is on, the file is hashed first, so it is read once more than the importer reads it.
+func processAssetStream(manager *AssetImporter, file AssetFile, src *assetSource) (*importedAsset, error) {
+	stream, err := src.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var stamp AssetStamp
+	if _, mode := AssetVerification(); mode == AssetVerifyOff {
+		stamp, err = sizeAssetStream(stream)
+	} else if stamp, err = stampAssetStream(stream); err == nil {
+		err = verifyAssetStamp(file, stamp)
+	}
+	if err != nil {
+		stream.Close()
+		return nil, err
+	}
+
+	asset, err := manager.ProcessAssetStream(file, stream)
+	if err != nil {
+		stream.Close()
+		return nil, fmt.Errorf("%w: %w", ErrAssetImportFailed, err)
+	}
+
+	return &importedAsset{
+		data:   asset,
+		stream: stream,
+		stamp:  stamp,
+		size:   estimateAssetSize(manager, file, nil, asset),
+	}, nil
+}
+
+// stampAssetStream hashes a stream from its start, and seeks back to the start.
+func stampAssetStream(stream AssetStream) (AssetStamp, error) {
+	if _, err := stream.Seek(0, io.SeekStart); err != nil {
+		return AssetStamp{}, err
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, stream)
+	if err != nil {
+		return AssetStamp{}, err
+	}
+
+	if _, err := stream.Seek(0, io.SeekStart); err != nil {
+		return AssetStamp{}, err
+	}
+
+	return AssetStamp{Hash: AssetHash(hex.EncodeToString(hash.Sum(nil))), Size: size}, nil
+}
+
+// sizeAssetStream returns the stamp of a stream that is not verified, which records only its size.
+func sizeAssetStream(stream AssetStream) (AssetStamp, error) {
+	info, err := stream.Stat()
+	if err != nil {
+		return AssetStamp{}, err
+	}
+	return AssetStamp{Size: info.Size()}, nil
+}
+
+// closeAssetStream closes the stream of an asset, if it was streamed.
+func closeAssetStream(stream AssetStream) error {
+	if stream == nil {
+		return nil
+	}
+	return stream.Close()
+}
+
+// tempAssetStream streams a file of a filesystem that cannot seek from a temporary copy on disk.
+type tempAssetStream struct {
+	*os.File
+	info fs.FileInfo
+}
+
+// spoolAssetStream copies a file to a temporary file, and returns a stream of the copy that removes
+// it when closed.
+func spoolAssetStream(f fs.File) (AssetStream, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	temp, err := os.CreateTemp("", "finch-stream-*")
+	if err != nil {
+		return nil, err
+	}
+
+	stream := &tempAssetStream{File: temp, info: info}
+
+	if _, err := io.Copy(temp, f); err != nil {
+		stream.Close()
+		return nil, err
+	}
+	if _, err := temp.Seek(0, io.SeekStart); err != nil {
+		stream.Close()
+		return nil, err
+	}
+
+	return stream, nil
+}
+
+// Stat returns the info of the original file.
+func (s *tempAssetStream) Stat() (fs.FileInfo, error) { return s.info, nil }
+
+func (s *tempAssetStream) Close() error {
+	return errors.Join(s.File.Close(), os.Remove(s.File.Name()))
+}
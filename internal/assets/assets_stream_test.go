@@ -0,0 +1,120 @@
+package assets
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+// noSeekFS hides the Seek method of the files of a filesystem, like compressed archive entries.
+type noSeekFS struct {
+	fs fs.FS
+}
+
+type noSeekFile struct {
+	f fs.File
+}
+
+func (f noSeekFS) Open(name string) (fs.File, error) {
+	file, err := f.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return noSeekFile{f: file}, nil
+}
+
+func (f noSeekFile) Stat() (fs.FileInfo, error) { return f.f.Stat() }
+func (f noSeekFile) Read(p []byte) (int, error) { return f.f.Read(p) }
+func (f noSeekFile) Close() error               { return f.f.Close() }
+
+// streamImporter registers an importer of "bin" files that keeps their stream as the asset.
+func streamImporter(t *testing.T) {
+	t.Helper()
+
+	err := RegisterAssetImporter(&AssetImporter{
+		AssetTypes: []AssetType{"bin"},
+		ProcessAssetStream: func(file AssetFile, stream AssetStream) (any, error) {
+			return stream, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAssetStreams(t *testing.T) {
+	tests := []struct {
+		name   string
+		seek   bool
+		verify bool
+		spool  bool
+		hashed bool
+	}{
+		{name: "seekable", seek: true},
+		{name: "seekable verified", seek: true, verify: true, hashed: true},
+		{name: "spooled", spool: true},
+		{name: "spooled verified", verify: true, spool: true, hashed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAssets(t)
+			streamImporter(t)
+
+			files := fstest.MapFS{"a.bin": {Data: []byte("streamed data")}}
+			if tt.seek {
+				mapFS(t, files)
+			} else if err := RegisterAssetFilesystem("assets", noSeekFS{fs: files}); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.verify {
+				manifest, err := BuildAssetHashManifest("1", "assets/a.bin")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := SetAssetVerification(manifest, AssetVerifyStrict); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := LoadAssets("assets/a.bin"); err != nil {
+				t.Fatal(err)
+			}
+
+			stream, err := GetAsset[AssetStream]("assets/a.bin")
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(stream)
+			if err != nil || string(data) != "streamed data" {
+				t.Fatalf("stream read %q, %v", data, err)
+			}
+			if info, err := stream.Stat(); err != nil || info.Name() != "a.bin" {
+				t.Fatalf("stream stat = %v, %v", info, err)
+			}
+
+			stamp := MustGetAssetStamp("assets/a.bin")
+			if stamp.Size != int64(len("streamed data")) || (stamp.Hash != "") != tt.hashed {
+				t.Fatalf("stamp = %+v, want hashed %v", stamp, tt.hashed)
+			}
+
+			temp, spooled := stream.(*tempAssetStream)
+			if spooled != tt.spool {
+				t.Fatalf("stream is %T, want spooled %v", stream, tt.spool)
+			}
+
+			if err := UnloadAssets("assets/a.bin"); err != nil {
+				t.Fatal(err)
+			}
+			if spooled {
+				if _, err := os.Stat(temp.Name()); !errors.Is(err, fs.ErrNotExist) {
+					t.Fatalf("temporary file %s not removed on unload: %v", temp.Name(), err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package assets
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewAssetFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want AssetFile
+		err  error
+	}{
+		{path: "assets/sprites/hero.png", want: "assets/sprites/hero.png"},
+		{path: `assets\sprites\hero.png`, want: "assets/sprites/hero.png"},
+		{path: "/assets//sprites/./hero.png", want: "assets/sprites/hero.png"},
+		{path: "assets/sprites/../hero.png", want: "assets/hero.png"},
+		{path: "assets/sprites.atlas#hero", want: "assets/sprites.atlas#hero"},
+		{path: `assets\sprites.atlas#a\b`, want: `assets/sprites.atlas#a\b`},
+		{path: "", err: ErrAssetPathEmpty},
+		{path: " / ", err: ErrAssetPathEmpty},
+		{path: "assets/../hero.png", err: ErrAssetPathEscapesRoot},
+		{path: "../hero.png", err: ErrAssetPathEscapesRoot},
+		{path: `C:\assets\hero.png`, err: ErrAssetPathInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := NewAssetFile(tt.path)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("NewAssetFile(%q) error = %v, want %v", tt.path, err, tt.err)
+			}
+			if got != tt.want {
+				t.Fatalf("NewAssetFile(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssetFileType(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, "atlas.json", "json")
+
+	tests := []struct {
+		file AssetFile
+		want AssetType
+	}{
+		{file: "assets/hero.png", want: "png"},
+		{file: "assets/Hero.PNG", want: "png"},
+		{file: "assets/sprites.atlas.json", want: "atlas.json"},
+		{file: "assets/sprites.ATLAS.Json", want: "atlas.json"},
+		{file: "assets/level.data.json", want: "json"},
+		{file: "assets/archive.tar.gz", want: "gz"},
+		{file: "assets/sprites.atlas.json#hero", want: "atlas.json"},
+		{file: `assets\sprites\hero.atlas.json`, want: "atlas.json"},
+		{file: `assets\v1.2\README`, want: ""},
+		{file: "assets/.hidden", want: ""},
+		{file: "assets/.hidden.json", want: "json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file.Path(), func(t *testing.T) {
+			if got := tt.file.Type(); got != tt.want {
+				t.Fatalf("%q.Type() = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssetFileRoot(t *testing.T) {
+	tests := []struct {
+		file AssetFile
+		want AssetRoot
+	}{
+		{file: "assets/hero.png", want: "assets"},
+		{file: `assets\hero.png`, want: "assets"},
+		{file: "/assets/hero.png", want: "assets"},
+		{file: "hero.png", want: "hero.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file.Path(), func(t *testing.T) {
+			if got := tt.file.Root(); got != tt.want {
+				t.Fatalf("%q.Root() = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssetFileBackslashesResolve(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, "txt")
+	mapFS(t, fstest.MapFS{"sprites/hero.txt": {Data: []byte("hero")}})
+
+	for _, file := range []AssetFile{`assets\sprites\hero.txt`, "assets//sprites/hero.txt", "/assets/sprites/hero.txt"} {
+		t.Run(file.Path(), func(t *testing.T) {
+			if err := LoadAssets(file); err != nil {
+				t.Fatal(err)
+			}
+			defer UnloadAssets(file)
+
+			got, err := GetAsset[string](file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != "hero" {
+				t.Fatalf("GetAsset(%q) = %q, want %q", file, got, "hero")
+			}
+		})
+	}
+}
+
+func TestRegisterAssetImporterNormalizesTypes(t *testing.T) {
+	resetAssets(t)
+	textImporter(t, ".TXT")
+
+	if !HasAssetTypeSupport("txt") || !HasAssetTypeSupport(".Txt") {
+		t.Fatal("HasAssetTypeSupport() = false for a registered type")
+	}
+
+	err := RegisterAssetImporter(&AssetImporter{AssetTypes: []AssetType{"txt"}})
+	if !errors.Is(err, ErrAssetManagerConflict) {
+		t.Fatalf("RegisterAssetImporter() error = %v, want %v", err, ErrAssetManagerConflict)
+	}
+}
@@ -0,0 +1,122 @@
+package assets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/adm87/finch-core/internal/bindata"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	JsonAssetType = "json"
+	YamlAssetType = "yaml"
+	YmlAssetType  = "yml"
+)
+
+var (
+	ErrAssetDataFormatUnsupported = errors.New("asset data format is unsupported")
+	ErrAssetDataTrailing          = errors.New("asset data has trailing content")
+)
+
+// RegisterDataImporter registers an importer that decodes data files of the given asset types into values of type T.
+//
+// The format of each type is selected by its last part, which must be json, yaml or yml. If no types are
+// given, all three are registered. Multi-part types decode different files of the same format into different
+// values, as in "enemy.yaml" and "item.yaml". Decoding is strict: fields that do not exist in T, and content
+// after the first value or document, are reported as errors. Enum fields can be decoded by implementing the
+// json and yaml marshalers with the enum package helpers.
+//
+//	finch.MustRegisterDataImporter[EnemyStats]("enemy.yaml")
+//	stats := finch.MustGetAsset[EnemyStats]("assets/data/goblin.enemy.yaml")
+func RegisterDataImporter[T any](types ...AssetType) error {
+	if len(types) == 0 {
+		types = []AssetType{JsonAssetType, YamlAssetType, YmlAssetType}
+	}
+
+	for _, t := range types {
+		if _, err := AssetDataFormat(t); err != nil {
+			return &AssetError{Op: "register", Type: t, Err: err}
+		}
+	}
+
+	return RegisterAssetImporter(&AssetImporter{
+		AssetTypes: types,
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			var value T
+			if err := DecodeAssetData(file.Type(), data, &value); err != nil {
+				return nil, err
+			}
+			return value, nil
+		},
+		OutputType: reflect.TypeFor[T](),
+	})
+}
+
+func MustRegisterDataImporter[T any](types ...AssetType) {
+	if err := RegisterDataImporter[T](types...); err != nil {
+		panic(err)
+	}
+}
+
+// DecodeAssetData strictly decodes json or yaml data into v, selecting the format by the asset type.
+//
+// The data must hold a single json value or yaml document. Yaml files may also hold the binary data
+// that finch-cook writes for them, which decodes as the source document did.
+func DecodeAssetData(t AssetType, data []byte, v any) error {
+	format, err := AssetDataFormat(t)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case JsonAssetType:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(v); err != nil {
+			return err
+		}
+		if _, err := decoder.Token(); err != io.EOF {
+			return ErrAssetDataTrailing
+		}
+	default:
+		if bindata.IsBinary(data) {
+			decoder := bindata.NewDecoder(data)
+			decoder.KnownFields(true)
+			return decoder.Decode(v)
+		}
+
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(v); err != nil {
+			return err
+		}
+		if err := decoder.Decode(&yaml.Node{}); err != io.EOF {
+			return ErrAssetDataTrailing
+		}
+	}
+
+	return nil
+}
+
+// AssetDataFormat returns the data format of an asset type, which is the last part of its extension.
+func AssetDataFormat(t AssetType) (AssetType, error) {
+	format := NewAssetType(t.String())
+	if i := strings.LastIndex(format.String(), "."); i >= 0 {
+		format = format[i+1:]
+	}
+
+	switch format {
+	case JsonAssetType:
+		return JsonAssetType, nil
+	case YamlAssetType, YmlAssetType:
+		return YamlAssetType, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrAssetDataFormatUnsupported, t)
+	}
+}
@@ -0,0 +1,126 @@
+package assets
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adm87/finch-core/internal/bindata"
+)
+
+type dataStats struct {
+	Health int `json:"health" yaml:"health"`
+}
+
+// binaryData returns the binary encoding of a yaml document, as finch-cook writes it.
+func binaryData(document string) string {
+	data, err := bindata.Marshal([]byte(document))
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+func TestAssetDataFormat(t *testing.T) {
+	tests := []struct {
+		t    AssetType
+		want AssetType
+		err  error
+	}{
+		{t: "json", want: JsonAssetType},
+		{t: "yaml", want: YamlAssetType},
+		{t: "yml", want: YamlAssetType},
+		{t: ".YML", want: YamlAssetType},
+		{t: "enemy.yaml", want: YamlAssetType},
+		{t: "strings.json", want: JsonAssetType},
+		{t: "toml", err: ErrAssetDataFormatUnsupported},
+		{t: "json.txt", err: ErrAssetDataFormatUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.t.String(), func(t *testing.T) {
+			got, err := AssetDataFormat(tt.t)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("AssetDataFormat() error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Fatalf("AssetDataFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeAssetData(t *testing.T) {
+	tests := []struct {
+		name   string
+		t      AssetType
+		data   string
+		health int
+		err    error
+		fails  bool
+	}{
+		{name: "json", t: "json", data: `{"health": 3}`, health: 3},
+		{name: "json trailing whitespace", t: "json", data: "{\"health\": 3}\n\n", health: 3},
+		{name: "json trailing value", t: "json", data: `{"health": 3} {"health": 4}`, err: ErrAssetDataTrailing},
+		{name: "json trailing garbage", t: "json", data: `{"health": 3}}`, err: ErrAssetDataTrailing},
+		{name: "json unknown field", t: "json", data: `{"health": 3, "mana": 1}`, fails: true},
+		{name: "json truncated", t: "json", data: `{"health": `, fails: true},
+		{name: "yaml", t: "yaml", data: "health: 3\n", health: 3},
+		{name: "yaml comment", t: "yaml", data: "health: 3\n# done\n", health: 3},
+		{name: "yaml second document", t: "yaml", data: "health: 3\n---\nhealth: 4\n", err: ErrAssetDataTrailing},
+		{name: "yaml unknown field", t: "yml", data: "health: 3\nmana: 1\n", fails: true},
+		{name: "multi-part type", t: "enemy.yaml", data: "health: 5\n", health: 5},
+		{name: "binary", t: "enemy.yaml", data: binaryData("health: 6\n"), health: 6},
+		{name: "binary unknown field", t: "yaml", data: binaryData("health: 6\nmana: 1\n"), fails: true},
+		{name: "binary in json", t: "json", data: binaryData("health: 6\n"), fails: true},
+		{name: "unsupported", t: "toml", data: "health = 3", err: ErrAssetDataFormatUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stats dataStats
+			err := DecodeAssetData(tt.t, []byte(tt.data), &stats)
+			if tt.fails {
+				if err == nil {
+					t.Fatal("DecodeAssetData() succeeded, want an error")
+				}
+				return
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("DecodeAssetData() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && stats.Health != tt.health {
+				t.Fatalf("health = %d, want %d", stats.Health, tt.health)
+			}
+		})
+	}
+}
+
+func TestRegisterDataImporter(t *testing.T) {
+	resetAssets(t)
+	mapFS(t, fstest.MapFS{
+		"goblin.enemy.yaml": {Data: []byte("health: 7\n")},
+		"broken.enemy.yaml": {Data: []byte("health: 7\nmana: 1\n")},
+	})
+
+	if err := RegisterDataImporter[dataStats]("toml"); !errors.Is(err, ErrAssetDataFormatUnsupported) {
+		t.Fatalf("RegisterDataImporter() error = %v, want %v", err, ErrAssetDataFormatUnsupported)
+	}
+	if err := RegisterDataImporter[dataStats]("enemy.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadAssets("assets/goblin.enemy.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := GetAsset[dataStats]("assets/goblin.enemy.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Health != 7 {
+		t.Fatalf("health = %d, want 7", stats.Health)
+	}
+	if err := LoadAssets("assets/broken.enemy.yaml"); !errors.Is(err, ErrAssetImportFailed) {
+		t.Fatalf("LoadAssets() error = %v, want %v", err, ErrAssetImportFailed)
+	}
+}
@@ -0,0 +1,103 @@
+package assets
+
+import (
+	"log/slog"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/adm87/finch-core/hashset"
+)
+
+// resetAssets clears the asset runtime before and after a test, since its state is package-level.
+func resetAssets(t *testing.T) {
+	t.Helper()
+
+	reset := func() {
+		assetsMu.Lock()
+		assetCache = make(map[AssetFile]*assetEntry)
+		assetsLoading = hashset.New[AssetFile]()
+		assetsEvicted = hashset.New[AssetFile]()
+		assetDependencies = make(map[AssetFile][]AssetFile)
+		assetDependenciesLoading = make(map[AssetFile]AssetFile)
+		assetCacheOptions = AssetCacheOptions{}
+		assetFallbacks = make(map[AssetType]AssetFallbackFunc)
+		assetsFailed = make(map[AssetFile]*assetFailure)
+		assetStamps = make(map[AssetFile]AssetStamp)
+		assetsMu.Unlock()
+
+		assetImportersMu.Lock()
+		assetManagers = make(map[AssetType]*AssetImporter)
+		assetImportersMu.Unlock()
+
+		assetFilesystemsMu.Lock()
+		assetFilesystems = make(map[AssetRoot][]*assetLayer)
+		assetFilesystemsMu.Unlock()
+
+		assetGroupsMu.Lock()
+		assetGroups = make(map[string][]string)
+		assetGroupsLoaded = make(map[string][]AssetFile)
+		assetGroupRefs = make(map[AssetFile]int)
+		assetGroupsMu.Unlock()
+
+		assetEventsMu.Lock()
+		assetEventSubscribers = make(map[int]AssetEventFunc)
+		assetLoadTimings = make(map[AssetFile]AssetLoadTiming)
+		assetLogger = slog.New(slog.DiscardHandler)
+		assetEventsMu.Unlock()
+
+		assetHashMu.Lock()
+		assetHashManifest = nil
+		assetHashVerifyMode = AssetVerifyOff
+		assetHashMu.Unlock()
+
+		assetRefTypesMu.Lock()
+		assetRefTypes = make(map[AssetFile][]reflect.Type)
+		assetRefTypesMu.Unlock()
+
+		reloadMu.Lock()
+		hotReloadEnabled = false
+		hotReloadLastPoll = time.Time{}
+		reloadMu.Unlock()
+
+		assetClock.Store(0)
+		assetEvictions.Store(0)
+		assetReloads.Store(0)
+	}
+
+	reset()
+	t.Cleanup(reset)
+}
+
+// textImporter registers an importer of types that loads files as strings, counting its cleanups.
+func textImporter(t *testing.T, types ...AssetType) *atomic.Int32 {
+	t.Helper()
+
+	cleanups := &atomic.Int32{}
+	err := RegisterAssetImporter(&AssetImporter{
+		AssetTypes: types,
+		ProcessAssetFile: func(file AssetFile, data []byte) (any, error) {
+			return string(data), nil
+		},
+		CleanupAssetFile: func(file AssetFile, data any) error {
+			cleanups.Add(1)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cleanups
+}
+
+// mapFS registers a map filesystem as the base layer of the "assets" root.
+func mapFS(t *testing.T, files fstest.MapFS) fstest.MapFS {
+	t.Helper()
+
+	if err := RegisterAssetFilesystem("assets", files); err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
@@ -0,0 +1,358 @@
+// Package bindata implements the finch binary data format, a compact encoding of yaml documents.
+//
+// Binary data is laid out as:
+//
+//	header | magic "FDAT", uint8 version
+//	value  | the document's root value
+//
+// Each value is a uint8 tag followed by its payload:
+//
+//	null, false, true | no payload
+//	int               | zigzag varint
+//	uint              | uvarint, for integers above the int64 range
+//	float             | float64 bits, little endian
+//	string            | uvarint length, bytes
+//	scalar            | yaml tag and value as strings, for timestamps, binary and custom tags
+//	list              | uvarint count, values
+//	map               | uvarint count, key and value pairs
+//
+// Aliases are expanded when encoding, up to MaxAliasNodes nodes, and comments and styles are dropped. Binary data parses
+// into a yaml.Node, so values decode through the same yaml tags and unmarshalers as the source
+// document.
+package bindata
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	Magic   = "FDAT"
+	Version = 1
+
+	headerSize = len(Magic) + 1
+
+	// MaxDepth is the deepest nesting of lists and maps that is encoded or decoded.
+	MaxDepth = 1024
+
+	// MaxAliasNodes is the largest number of nodes that aliases expand to when encoding, so documents
+	// such as the "billion laughs" fail instead of expanding without bound.
+	MaxAliasNodes = 1 << 16
+)
+
+const (
+	tagNull uint8 = iota
+	tagFalse
+	tagTrue
+	tagInt
+	tagUint
+	tagFloat
+	tagString
+	tagScalar
+	tagList
+	tagMap
+)
+
+var (
+	ErrInvalidData    = errors.New("invalid finch binary data")
+	ErrInvalidVersion = errors.New("unsupported finch binary data version")
+	ErrTooDeep        = errors.New("finch binary data is nested too deeply")
+	ErrTooManyAliases = errors.New("finch binary data aliases expand to too many nodes")
+)
+
+// IsBinary reports whether data starts with the binary data magic.
+func IsBinary(data []byte) bool {
+	return len(data) >= len(Magic) && string(data[:len(Magic)]) == Magic
+}
+
+// ======================================================
+// Encoding
+// ======================================================
+
+// Encode returns the binary encoding of a yaml document or value node. Empty documents encode as null.
+// Aliases are expanded, up to MaxAliasNodes nodes in all.
+func Encode(node *yaml.Node) ([]byte, error) {
+	b := append([]byte(Magic), Version)
+
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind == 0 || node.Kind == yaml.DocumentNode {
+		return append(b, tagNull), nil
+	}
+
+	e := &encoder{}
+	return e.value(b, node, 0, false)
+}
+
+// Marshal returns the binary encoding of a yaml document.
+func Marshal(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	return Encode(&node)
+}
+
+// encoder counts the nodes expanded from aliases.
+type encoder struct {
+	aliased int
+}
+
+func (e *encoder) value(b []byte, node *yaml.Node, depth int, aliased bool) ([]byte, error) {
+	if depth > MaxDepth {
+		return nil, ErrTooDeep
+	}
+	if aliased {
+		if e.aliased++; e.aliased > MaxAliasNodes {
+			return nil, ErrTooManyAliases
+		}
+	}
+
+	switch node.Kind {
+	case yaml.AliasNode:
+		return e.value(b, node.Alias, depth+1, true)
+	case yaml.SequenceNode:
+		b = append(b, tagList)
+		b = binary.AppendUvarint(b, uint64(len(node.Content)))
+		for _, child := range node.Content {
+			var err error
+			if b, err = e.value(b, child, depth+1, aliased); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case yaml.MappingNode:
+		if len(node.Content)%2 != 0 {
+			return nil, fmt.Errorf("%w: mapping with %d nodes", ErrInvalidData, len(node.Content))
+		}
+		b = append(b, tagMap)
+		b = binary.AppendUvarint(b, uint64(len(node.Content)/2))
+		for _, child := range node.Content {
+			var err error
+			if b, err = e.value(b, child, depth+1, aliased); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case yaml.ScalarNode:
+		return encodeScalar(b, node)
+	default:
+		return nil, fmt.Errorf("%w: node kind %d", ErrInvalidData, node.Kind)
+	}
+}
+
+func encodeScalar(b []byte, node *yaml.Node) ([]byte, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return append(b, tagNull), nil
+	case "!!bool":
+		var v bool
+		if err := node.Decode(&v); err != nil {
+			return nil, err
+		}
+		if v {
+			return append(b, tagTrue), nil
+		}
+		return append(b, tagFalse), nil
+	case "!!int":
+		var v int64
+		if err := node.Decode(&v); err == nil {
+			return binary.AppendVarint(append(b, tagInt), v), nil
+		}
+		var u uint64
+		if err := node.Decode(&u); err != nil {
+			return nil, err
+		}
+		return binary.AppendUvarint(append(b, tagUint), u), nil
+	case "!!float":
+		var v float64
+		if err := node.Decode(&v); err != nil {
+			return nil, err
+		}
+		return binary.LittleEndian.AppendUint64(append(b, tagFloat), math.Float64bits(v)), nil
+	case "!!str":
+		return appendString(append(b, tagString), node.Value), nil
+	default:
+		return appendString(appendString(append(b, tagScalar), node.Tag), node.Value), nil
+	}
+}
+
+func appendString(b []byte, s string) []byte {
+	return append(binary.AppendUvarint(b, uint64(len(s))), s...)
+}
+
+// ======================================================
+// Parsing
+// ======================================================
+
+// Parse returns the yaml node encoded by binary data.
+func Parse(data []byte) (*yaml.Node, error) {
+	if len(data) < headerSize || !IsBinary(data) {
+		return nil, fmt.Errorf("%w: bad magic", ErrInvalidData)
+	}
+	if version := data[len(Magic)]; version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidVersion, version)
+	}
+
+	p := &parser{data: data[headerSize:]}
+	node, err := p.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.data) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidData, len(p.data))
+	}
+
+	return node, nil
+}
+
+type parser struct {
+	data []byte
+}
+
+func (p *parser) value(depth int) (*yaml.Node, error) {
+	if depth > MaxDepth {
+		return nil, ErrTooDeep
+	}
+
+	tag, err := p.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case tagNull:
+		return scalar("!!null", "null"), nil
+	case tagFalse:
+		return scalar("!!bool", "false"), nil
+	case tagTrue:
+		return scalar("!!bool", "true"), nil
+	case tagInt:
+		v, n := binary.Varint(p.data)
+		if n <= 0 {
+			return nil, fmt.Errorf("%w: bad int", ErrInvalidData)
+		}
+		p.data = p.data[n:]
+		return scalar("!!int", strconv.FormatInt(v, 10)), nil
+	case tagUint:
+		v, err := p.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		return scalar("!!int", strconv.FormatUint(v, 10)), nil
+	case tagFloat:
+		if len(p.data) < 8 {
+			return nil, fmt.Errorf("%w: bad float", ErrInvalidData)
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(p.data))
+		p.data = p.data[8:]
+		return scalar("!!float", formatFloat(v)), nil
+	case tagString:
+		s, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		return scalar("!!str", s), nil
+	case tagScalar:
+		t, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		s, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		return scalar(t, s), nil
+	case tagList, tagMap:
+		count, err := p.count()
+		if err != nil {
+			return nil, err
+		}
+
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if tag == tagMap {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			count *= 2
+		}
+
+		node.Content = make([]*yaml.Node, 0, min(count, len(p.data)))
+		for range count {
+			child, err := p.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown tag %d", ErrInvalidData, tag)
+	}
+}
+
+func (p *parser) byte() (uint8, error) {
+	if len(p.data) == 0 {
+		return 0, fmt.Errorf("%w: unexpected end", ErrInvalidData)
+	}
+	b := p.data[0]
+	p.data = p.data[1:]
+	return b, nil
+}
+
+func (p *parser) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(p.data)
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: bad uvarint", ErrInvalidData)
+	}
+	p.data = p.data[n:]
+	return v, nil
+}
+
+// count reads the length of a list or map, which is bounded by the remaining data since every
+// value takes at least one byte.
+func (p *parser) count() (int, error) {
+	count, err := p.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if count > uint64(len(p.data)) {
+		return 0, fmt.Errorf("%w: count %d exceeds the remaining %d bytes", ErrInvalidData, count, len(p.data))
+	}
+	return int(count), nil
+}
+
+func (p *parser) string() (string, error) {
+	n, err := p.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if n > uint64(len(p.data)) {
+		return "", fmt.Errorf("%w: string of %d bytes exceeds the remaining %d bytes", ErrInvalidData, n, len(p.data))
+	}
+	s := string(p.data[:n])
+	p.data = p.data[n:]
+	return s, nil
+}
+
+func scalar(tag, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
+
+// formatFloat formats a float as a yaml float scalar.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return ".nan"
+	case math.IsInf(v, 1):
+		return ".inf"
+	case math.IsInf(v, -1):
+		return "-.inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
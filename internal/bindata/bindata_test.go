@@ -0,0 +1,274 @@
+package bindata
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type stats struct {
+	Name    string   `yaml:"name"`
+	Health  int      `yaml:"health"`
+	Speed   float64  `yaml:"speed"`
+	Tags    []string `yaml:"tags"`
+	Boss    bool
+	Drops   map[string]drop `yaml:"drops"`
+	Extra   *stats          `yaml:"extra"`
+	Ignored string          `yaml:"-"`
+	Base    base            `yaml:"base"`
+	Span    span            `yaml:"span"`
+}
+
+type common struct {
+	ID string `yaml:"id"`
+}
+
+type base struct {
+	common `yaml:",inline"`
+	Level  int `yaml:"level"`
+}
+
+// span unmarshals itself from a "min-max" string.
+type span struct {
+	Min, Max int
+}
+
+func (s *span) UnmarshalYAML(node *yaml.Node) error {
+	_, err := fmt.Sscanf(node.Value, "%d-%d", &s.Min, &s.Max)
+	return err
+}
+
+type drop struct {
+	Chance float64 `yaml:"chance"`
+}
+
+type inlined struct {
+	stats `yaml:",inline"`
+	Rest  map[string]any `yaml:",inline"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{name: "empty document", yaml: ""},
+		{name: "null", yaml: "null"},
+		{name: "scalars", yaml: "a: 1\nb: -2\nc: 1.5\nd: true\ne: false\nf: text\ng: ~\n"},
+		{name: "big integers", yaml: "min: -9223372036854775808\nmax: 18446744073709551615\n"},
+		{name: "special floats", yaml: "[.inf, -.inf, 1e300, 0.1]"},
+		{name: "quoted numbers stay strings", yaml: "a: \"1\"\nb: 'true'\n"},
+		{name: "nested", yaml: "a:\n  b: [1, [2, 3], {c: d}]\n"},
+		{name: "non-string keys", yaml: "1: one\ntrue: yes\n"},
+		{name: "aliases", yaml: "base: &base {health: 3}\ngoblin: *base\n"},
+		{name: "timestamps", yaml: "at: 2026-01-02T03:04:05Z\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal([]byte(tt.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !IsBinary(data) {
+				t.Fatalf("Marshal() = %q, want binary data", data)
+			}
+
+			var want, got any
+			if err := yaml.Unmarshal([]byte(tt.yaml), &want); err != nil {
+				t.Fatal(err)
+			}
+			if err := NewDecoder(data).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Decode() = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestNaN(t *testing.T) {
+	data, err := Marshal([]byte(".nan"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v float64
+	if err := NewDecoder(data).Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(v) {
+		t.Fatalf("Decode() = %v, want NaN", v)
+	}
+}
+
+func TestDecodeStruct(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		knownFields bool
+		want        any
+		err         bool
+	}{
+		{
+			name: "fields",
+			yaml: "name: goblin\nhealth: 3\nspeed: 1.5\ntags: [a, b]\nboss: true\ndrops: {gold: {chance: 0.5}}\nextra: {health: 1}\n",
+			want: &stats{Name: "goblin", Health: 3, Speed: 1.5, Tags: []string{"a", "b"}, Boss: true,
+				Drops: map[string]drop{"gold": {Chance: 0.5}}, Extra: &stats{Health: 1}},
+		},
+		{name: "unknown field", yaml: "health: 3\nmana: 1\n", knownFields: true, err: true},
+		{name: "unknown field allowed", yaml: "health: 3\nmana: 1\n", want: &stats{Health: 3}},
+		{name: "unknown nested field", yaml: "extra: {mana: 1}\n", knownFields: true, err: true},
+		{name: "unknown field in map value", yaml: "drops: {gold: {weight: 1}}\n", knownFields: true, err: true},
+		{name: "ignored field", yaml: "ignored: x\n", knownFields: true, err: true},
+		{name: "known merged fields", yaml: "extra:\n  <<: {health: 3}\n  speed: 2\n", knownFields: true, want: &stats{Extra: &stats{Health: 3, Speed: 2}}},
+		{name: "unknown merged field", yaml: "extra:\n  <<: {mana: 3}\n", knownFields: true, err: true},
+		{name: "type mismatch", yaml: "health: many\n", err: true},
+		{name: "embedded inline fields", yaml: "base: {id: orc, level: 2}\n", knownFields: true, want: &stats{Base: base{common: common{ID: "orc"}, Level: 2}}},
+		{name: "unknown embedded inline field", yaml: "base: {id: orc, rank: 2}\n", knownFields: true, err: true},
+		{name: "custom unmarshaler", yaml: "span: 1-3\n", knownFields: true, want: &stats{Span: span{Min: 1, Max: 3}}},
+		{name: "custom unmarshaler error", yaml: "span: wide\n", knownFields: true, err: true},
+		{name: "aliases", yaml: "drops: {a: &d {chance: 0.5}, b: *d}\n", knownFields: true, want: &stats{Drops: map[string]drop{"a": {Chance: 0.5}, "b": {Chance: 0.5}}}},
+		{name: "quoted numbers stay strings", yaml: "name: \"123\"\ntags: [\"true\", \"1.5\", \"null\"]\n", knownFields: true, want: &stats{Name: "123", Tags: []string{"true", "1.5", "null"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal([]byte(tt.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := &stats{}
+			decoder := NewDecoder(data)
+			decoder.KnownFields(tt.knownFields)
+			err = decoder.Decode(got)
+			if (err != nil) != tt.err {
+				t.Fatalf("Decode() error = %v, want error %v", err, tt.err)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Decode() = %+v, want %+v", got, tt.want)
+			}
+
+			// The yaml decoder agrees on which documents are valid.
+			decodeErr := func() error {
+				decoder := yaml.NewDecoder(strings.NewReader(tt.yaml))
+				decoder.KnownFields(tt.knownFields)
+				return decoder.Decode(&stats{})
+			}()
+			if (decodeErr != nil) != tt.err {
+				t.Fatalf("yaml Decode() error = %v, want error %v", decodeErr, tt.err)
+			}
+		})
+	}
+}
+
+func TestDecodeInline(t *testing.T) {
+	data, err := Marshal([]byte("health: 3\nmana: 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got inlined
+	decoder := NewDecoder(data)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Health != 3 || got.Rest["mana"] != 1 {
+		t.Fatalf("Decode() = %+v", got)
+	}
+}
+
+func TestDecodeTime(t *testing.T) {
+	data, err := Marshal([]byte("2026-01-02T03:04:05Z"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got time.Time
+	if err := NewDecoder(data).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("Decode() = %v, want %v", got, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	header := Magic + string(rune(Version))
+
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{name: "empty", data: "", err: ErrInvalidData},
+		{name: "bad magic", data: "FDAX\x01\x00", err: ErrInvalidData},
+		{name: "bad version", data: Magic + "\x02\x00", err: ErrInvalidVersion},
+		{name: "no value", data: header, err: ErrInvalidData},
+		{name: "unknown tag", data: header + "\xff", err: ErrInvalidData},
+		{name: "trailing bytes", data: header + "\x00\x00", err: ErrInvalidData},
+		{name: "truncated int", data: header + "\x03\x80", err: ErrInvalidData},
+		{name: "truncated float", data: header + "\x05\x00\x00", err: ErrInvalidData},
+		{name: "string past end", data: header + "\x06\x05ab", err: ErrInvalidData},
+		{name: "count past end", data: header + "\x08\xff\xff\xff\xff\x0f", err: ErrInvalidData},
+		{name: "truncated list", data: header + "\x08\x02\x00", err: ErrInvalidData},
+		{name: "truncated map", data: header + "\x09\x01\x00", err: ErrInvalidData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data)); !errors.Is(err, tt.err) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseDepth(t *testing.T) {
+	data := []byte(Magic + string(rune(Version)))
+	for range MaxDepth + 2 {
+		data = append(data, tagList, 1)
+	}
+	data = append(data, tagNull)
+
+	if _, err := Parse(data); !errors.Is(err, ErrTooDeep) {
+		t.Fatalf("Parse() error = %v, want %v", err, ErrTooDeep)
+	}
+}
+
+func TestMarshalAliases(t *testing.T) {
+	// Each level holds ten aliases of the one before, so the last expands to 10^9 values.
+	var b strings.Builder
+	b.WriteString("a: &a [x, x, x, x, x, x, x, x, x, x]\n")
+	for level := 'b'; level <= 'i'; level++ {
+		prev := string(level - 1)
+		fmt.Fprintf(&b, "%c: &%c [*%s, *%s, *%s, *%s, *%s, *%s, *%s, *%s, *%s, *%s]\n", level, level,
+			prev, prev, prev, prev, prev, prev, prev, prev, prev, prev)
+	}
+
+	if _, err := Marshal([]byte(b.String())); !errors.Is(err, ErrTooManyAliases) {
+		t.Fatalf("Marshal() error = %v, want %v", err, ErrTooManyAliases)
+	}
+
+	// A few aliases, as data files use them to share values, expand as usual.
+	data, err := Marshal([]byte("base: &base {health: 3}\nboss: {<<: *base, speed: 2}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]map[string]int
+	if err := NewDecoder(data).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["boss"]["health"] != 3 || got["boss"]["speed"] != 2 {
+		t.Fatalf("Decode() = %v", got)
+	}
+}
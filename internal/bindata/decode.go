@@ -0,0 +1,51 @@
+package bindata
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ======================================================
+// Decoder
+// ======================================================
+
+// Decoder decodes binary data into values, as a yaml.Decoder decodes a document.
+type Decoder struct {
+	data        []byte
+	knownFields bool
+}
+
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{data: data}
+}
+
+// KnownFields ensures that the keys of decoded mappings exist as fields in the struct they are
+// decoded into, as yaml.Decoder.KnownFields does.
+func (d *Decoder) KnownFields(enable bool) {
+	d.knownFields = enable
+}
+
+// Decode decodes the binary data into v.
+//
+// yaml.Node.Decode cannot check for unknown fields, so with KnownFields the node is written back
+// as a document and decoded by a yaml.Decoder, which checks them exactly as it does for the source.
+func (d *Decoder) Decode(v any) error {
+	node, err := Parse(d.data)
+	if err != nil {
+		return err
+	}
+
+	if !d.knownFields {
+		return node.Decode(v)
+	}
+
+	document, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(document))
+	decoder.KnownFields(true)
+	return decoder.Decode(v)
+}
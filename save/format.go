@@ -0,0 +1,94 @@
+package save
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ======================================================
+// Encoding
+// ======================================================
+
+// record is a decoded save, with its payload decompressed and verified.
+type record struct {
+	version    int
+	saved      time.Time
+	compressed bool
+	payload    []byte
+}
+
+// encode lays out a save of a json payload.
+func encode(r *record) ([]byte, error) {
+	stored := r.payload
+	flags := uint16(0)
+
+	if r.compressed {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(r.payload); err != nil {
+			return nil, err
+		}
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+		stored = buf.Bytes()
+		flags |= flagCompressed
+	}
+
+	checksum := sha256.Sum256(r.payload)
+
+	data := make([]byte, headerSize, headerSize+len(stored))
+	copy(data, Magic)
+	binary.LittleEndian.PutUint16(data[4:], Format)
+	binary.LittleEndian.PutUint16(data[6:], flags)
+	binary.LittleEndian.PutUint32(data[8:], uint32(r.version))
+	binary.LittleEndian.PutUint64(data[12:], uint64(r.saved.UnixNano()))
+	binary.LittleEndian.PutUint64(data[20:], uint64(len(stored)))
+	copy(data[28:], checksum[:])
+
+	return append(data, stored...), nil
+}
+
+// decode reads a save, decompressing its payload and verifying it against its checksum.
+func decode(data []byte) (*record, error) {
+	if len(data) < headerSize || string(data[:4]) != Magic {
+		return nil, ErrInvalidSave
+	}
+	if format := binary.LittleEndian.Uint16(data[4:]); format != Format {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidFormat, format)
+	}
+
+	flags := binary.LittleEndian.Uint16(data[6:])
+	r := &record{
+		version:    int(binary.LittleEndian.Uint32(data[8:])),
+		saved:      time.Unix(0, int64(binary.LittleEndian.Uint64(data[12:]))),
+		compressed: flags&flagCompressed != 0,
+	}
+
+	// A size that does not match the file means the save was truncated or appended to.
+	if size := binary.LittleEndian.Uint64(data[20:]); size != uint64(len(data)-headerSize) {
+		return nil, fmt.Errorf("%w: payload is %d bytes, expected %d", ErrInvalidSave, len(data)-headerSize, size)
+	}
+
+	r.payload = data[headerSize:]
+	if r.compressed {
+		gz, err := gzip.NewReader(bytes.NewReader(r.payload))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidSave, err)
+		}
+		if r.payload, err = io.ReadAll(gz); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidSave, err)
+		}
+	}
+
+	if sha256.Sum256(r.payload) != [32]byte(data[28:headerSize]) {
+		return nil, ErrChecksumMismatch
+	}
+
+	return r, nil
+}
@@ -0,0 +1,56 @@
+package save
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	saved := time.Unix(0, 1700000000000000000)
+
+	encoded := func(compressed bool) []byte {
+		data, err := encode(&record{version: 3, saved: saved, compressed: compressed, payload: []byte(`{"a":1}`)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data func() []byte
+		err  error
+	}{
+		{name: "plain", data: func() []byte { return encoded(false) }},
+		{name: "compressed", data: func() []byte { return encoded(true) }},
+		{name: "empty", data: func() []byte { return nil }, err: ErrInvalidSave},
+		{name: "bad magic", data: func() []byte { d := encoded(false); d[0] = 'X'; return d }, err: ErrInvalidSave},
+		{
+			name: "unknown format",
+			data: func() []byte { d := encoded(false); binary.LittleEndian.PutUint16(d[4:], Format+1); return d },
+			err:  ErrInvalidFormat,
+		},
+		{name: "truncated", data: func() []byte { d := encoded(false); return d[:len(d)-1] }, err: ErrInvalidSave},
+		{name: "appended", data: func() []byte { return append(encoded(false), '}') }, err: ErrInvalidSave},
+		{name: "corrupted payload", data: func() []byte { d := encoded(false); d[len(d)-2] = '2'; return d }, err: ErrChecksumMismatch},
+		{name: "corrupted gzip", data: func() []byte { d := encoded(true); d[headerSize] ^= 0xff; return d }, err: ErrInvalidSave},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := decode(tt.data())
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("decode() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+
+			if r.version != 3 || !r.saved.Equal(saved) || string(r.payload) != `{"a":1}` {
+				t.Fatalf("decode() = version %d, saved %v, payload %q", r.version, r.saved, r.payload)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package save
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// ======================================================
+// Migrations
+// ======================================================
+
+// Migration upgrades the payload of a save by one schema version.
+//
+// The payload is the save's json decoded without a schema: objects are map[string]any, arrays are
+// []any and numbers are json.Number, so no precision is lost. A migration may modify the payload in
+// place, and returns the upgraded payload.
+type Migration func(data any) (any, error)
+
+// RegisterMigration registers the migration that upgrades saves of schema version from to version
+// from+1. Every version below the store's version needs one for its saves to load.
+func (s *Store[T]) RegisterMigration(from int, migration Migration) error {
+	if from < 1 || from >= s.options.Version {
+		return fmt.Errorf("%w: %d, store version is %d", ErrMigrationInvalid, from, s.options.Version)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.migrations[from]; exists {
+		return fmt.Errorf("%w: %d", ErrMigrationExists, from)
+	}
+
+	s.migrations[from] = migration
+	return nil
+}
+
+func (s *Store[T]) MustRegisterMigration(from int, migration Migration) {
+	if err := s.RegisterMigration(from, migration); err != nil {
+		panic(err)
+	}
+}
+
+// migrate upgrades the payload of a save to the store's version, step by step. Payloads already at the
+// store's version are returned as they are. The store's lock must be held.
+func (s *Store[T]) migrate(r *record) ([]byte, error) {
+	if r.version > s.options.Version {
+		return nil, fmt.Errorf("%w: %d, store version is %d", ErrVersionUnsupported, r.version, s.options.Version)
+	}
+	if r.version < 1 {
+		return nil, fmt.Errorf("%w: schema version %d", ErrInvalidSave, r.version)
+	}
+	if r.version == s.options.Version {
+		return r.payload, nil
+	}
+
+	// Check the whole chain first, so a gap fails before any migration runs.
+	for version := r.version; version < s.options.Version; version++ {
+		if _, exists := s.migrations[version]; !exists {
+			return nil, fmt.Errorf("%w: %d to %d", ErrMigrationMissing, version, version+1)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(r.payload))
+	decoder.UseNumber()
+
+	var data any
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSave, err)
+	}
+
+	for version := r.version; version < s.options.Version; version++ {
+		var err error
+		if data, err = s.migrations[version](data); err != nil {
+			return nil, fmt.Errorf("%w: %d to %d: %w", ErrMigrationFailed, version, version+1, err)
+		}
+	}
+
+	return json.Marshal(data)
+}
@@ -0,0 +1,88 @@
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	// rename moves the "hp" field of a version 1 payload to "health".
+	rename := func(data any) (any, error) {
+		m := data.(map[string]any)
+		m["health"] = m["hp"]
+		delete(m, "hp")
+		return m, nil
+	}
+	// double doubles the health of a version 2 payload, checking that numbers stay json.Number.
+	double := func(data any) (any, error) {
+		m := data.(map[string]any)
+		n, err := m["health"].(json.Number).Int64()
+		if err != nil {
+			return nil, err
+		}
+		m["health"] = n * 2
+		return m, nil
+	}
+	errMigration := errors.New("migration failed")
+	failing := func(data any) (any, error) {
+		return nil, errMigration
+	}
+
+	tests := []struct {
+		name       string
+		migrations map[int]Migration
+		version    int
+		payload    string
+		want       string
+		err        error
+	}{
+		{name: "current", version: 3, payload: `{"health":5}`, want: `{"health":5}`},
+		{name: "one step", migrations: map[int]Migration{1: rename, 2: double}, version: 2, payload: `{"health":5}`, want: `{"health":10}`},
+		{name: "chain", migrations: map[int]Migration{1: rename, 2: double}, version: 1, payload: `{"hp":5}`, want: `{"health":10}`},
+		{name: "newer", version: 4, payload: `{}`, err: ErrVersionUnsupported},
+		{name: "invalid version", version: 0, payload: `{}`, err: ErrInvalidSave},
+		{name: "gap", migrations: map[int]Migration{2: double}, version: 1, payload: `{"hp":5}`, err: ErrMigrationMissing},
+		{name: "invalid payload", migrations: map[int]Migration{1: rename, 2: double}, version: 1, payload: `{`, err: ErrInvalidSave},
+		{name: "failed", migrations: map[int]Migration{1: failing, 2: double}, version: 1, payload: `{}`, err: errMigration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := MustNewStore[any](t.TempDir(), Options{Version: 3})
+			for from, migration := range tt.migrations {
+				s.MustRegisterMigration(from, migration)
+			}
+
+			got, err := s.migrate(&record{version: tt.version, payload: []byte(tt.payload)})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("migrate() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && string(got) != tt.want {
+				t.Fatalf("migrate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterMigration(t *testing.T) {
+	s := MustNewStore[any](t.TempDir(), Options{Version: 3})
+	noop := func(data any) (any, error) { return data, nil }
+
+	tests := []struct {
+		from int
+		err  error
+	}{
+		{from: 0, err: ErrMigrationInvalid},
+		{from: 1},
+		{from: 1, err: ErrMigrationExists},
+		{from: 2},
+		{from: 3, err: ErrMigrationInvalid},
+	}
+
+	for _, tt := range tests {
+		if err := s.RegisterMigration(tt.from, noop); !errors.Is(err, tt.err) {
+			t.Errorf("RegisterMigration(%d) error = %v, want %v", tt.from, err, tt.err)
+		}
+	}
+}
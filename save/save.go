@@ -0,0 +1,119 @@
+// Package save stores game saves in named slots, one file per slot in a directory.
+//
+// Saves are written atomically, so a crash mid-write leaves the previous save intact, and the
+// previous saves of a slot can be kept as numbered backups. Every save is checksummed, and a save
+// that fails its checksum falls back to the newest backup that passes.
+//
+// A save is laid out as:
+//
+//	header  | magic "FSAV", uint16 format, uint16 flags, uint32 schema version, int64 saved time,
+//	        | uint64 payload size, [32]byte SHA-256 of the json payload
+//	payload | the value encoded as json, gzip compressed if flag 1 is set
+//
+// The saved time is in unix nanoseconds and the payload size is the stored size. All integers are
+// little endian.
+//
+// Each save records the schema version of the store that wrote it. Saves of older versions are
+// upgraded when loaded by the migrations registered with the store, one version at a time.
+package save
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	Magic  = "FSAV"
+	Format = 1
+
+	// Extension is the default file extension of saves.
+	Extension = ".sav"
+
+	headerSize = 60
+
+	flagCompressed = 1 << 0
+)
+
+var (
+	ErrInvalidSave        = errors.New("invalid save")
+	ErrInvalidFormat      = errors.New("unsupported save format")
+	ErrChecksumMismatch   = errors.New("save checksum mismatch")
+	ErrSlotInvalid        = errors.New("save slot name is invalid")
+	ErrSlotNotFound       = errors.New("save slot not found")
+	ErrVersionInvalid     = errors.New("save schema version must be at least 1")
+	ErrVersionUnsupported = errors.New("save schema version is newer than the store")
+	ErrMigrationInvalid   = errors.New("save migration version is out of range")
+	ErrMigrationExists    = errors.New("save migration already registered")
+	ErrMigrationMissing   = errors.New("save migration not registered")
+	ErrMigrationFailed    = errors.New("save migration failed")
+	ErrBackupsInvalid     = errors.New("save backup count must not be negative")
+)
+
+// ======================================================
+// Options
+// ======================================================
+
+// Options configures a Store.
+type Options struct {
+	// Version is the current schema version, written into every save. Defaults to 1.
+	Version int
+
+	// Compress gzip compresses the payload of new saves. Saves are read whether compressed or not.
+	Compress bool
+
+	// Backups is the number of previous saves kept for each slot, as <slot><ext>.1 for the newest to
+	// <slot><ext>.N for the oldest.
+	Backups int
+
+	// Extension is the file extension of saves. Defaults to Extension.
+	Extension string
+}
+
+// ======================================================
+// Slot Info
+// ======================================================
+
+// SlotInfo describes the save of a slot.
+type SlotInfo struct {
+	Slot string
+
+	// Version is the schema version the save was written with, before any migration.
+	Version int
+
+	Saved      time.Time
+	Size       int64
+	Compressed bool
+
+	// Backup is the number of the backup the save was read from, or 0 for the slot's own file.
+	Backup int
+}
+
+// ======================================================
+// Directories
+// ======================================================
+
+// DefaultDir returns the conventional save directory of a game, a "saves" directory in the game's
+// directory of the user's configuration directory. The directory is not created.
+func DefaultDir(game string) (string, error) {
+	if game == "" || strings.ContainsAny(game, `/\`) {
+		return "", errors.New("game name must be a single path element")
+	}
+
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(config, game, "saves"), nil
+}
+
+// validSlot reports whether a slot name can be used as a file name on every platform.
+func validSlot(slot string) bool {
+	if slot == "" || slot == "." || slot == ".." || strings.TrimSpace(slot) != slot {
+		return false
+	}
+	return !strings.ContainsAny(slot, `/\:*?"<>|`)
+}
@@ -0,0 +1,326 @@
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adm87/finch-core/fsys"
+	"github.com/adm87/finch-core/hashset"
+)
+
+// ======================================================
+// Store
+// ======================================================
+
+// Store saves values of type T, encoded as json, in the slots of a directory.
+//
+// A store is safe for concurrent use, but it does not coordinate with other stores or processes using
+// the same directory.
+type Store[T any] struct {
+	dir     string
+	options Options
+
+	mu         sync.Mutex
+	migrations map[int]Migration
+}
+
+// NewStore returns a store of the saves in a directory, creating the directory if it does not exist.
+func NewStore[T any](dir string, options Options) (*Store[T], error) {
+	if options.Version == 0 {
+		options.Version = 1
+	}
+	if options.Version < 1 {
+		return nil, fmt.Errorf("%w: %d", ErrVersionInvalid, options.Version)
+	}
+	if options.Backups < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrBackupsInvalid, options.Backups)
+	}
+	if options.Extension == "" {
+		options.Extension = Extension
+	}
+	if strings.ContainsAny(options.Extension, `/\`) {
+		return nil, fmt.Errorf("save extension %q must not contain a path separator", options.Extension)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &Store[T]{
+		dir:        dir,
+		options:    options,
+		migrations: make(map[int]Migration),
+	}, nil
+}
+
+func MustNewStore[T any](dir string, options Options) *Store[T] {
+	return fsys.MustGet(NewStore[T](dir, options))
+}
+
+// Dir returns the directory of the store.
+func (s *Store[T]) Dir() string {
+	return s.dir
+}
+
+// Version returns the schema version the store writes.
+func (s *Store[T]) Version() int {
+	return s.options.Version
+}
+
+// Save writes a value to a slot. The slot's previous save, if it passes its checksum, becomes its
+// newest backup and the older backups shift down, dropping the oldest.
+func (s *Store[T]) Save(slot string, value T) error {
+	if !validSlot(slot) {
+		return fmt.Errorf("%w: %q", ErrSlotInvalid, slot)
+	}
+
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	data, err := encode(&record{
+		version:    s.options.Version,
+		saved:      time.Now(),
+		compressed: s.options.Compress,
+		payload:    payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.rotate(slot); err != nil {
+		return err
+	}
+
+	return fsys.WriteFileAtomic(s.path(slot, 0), data, 0o644)
+}
+
+func (s *Store[T]) MustSave(slot string, value T) {
+	fsys.Must(s.Save(slot, value))
+}
+
+// Load reads the value of a slot, upgraded to the store's version by its migrations.
+//
+// If the slot's save is missing or fails its checksum, the newest backup that passes is loaded
+// instead, and its number is reported in the returned info. Saves of a newer version than the store
+// are not loaded, and neither are their backups.
+func (s *Store[T]) Load(slot string) (T, SlotInfo, error) {
+	var value T
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, info, err := s.read(slot)
+	if err != nil {
+		return value, info, err
+	}
+
+	payload, err := s.migrate(r)
+	if err != nil {
+		return value, info, fmt.Errorf("%s: %w", s.path(slot, info.Backup), err)
+	}
+
+	if err := json.Unmarshal(payload, &value); err != nil {
+		return value, info, fmt.Errorf("%s: %w", s.path(slot, info.Backup), err)
+	}
+
+	return value, info, nil
+}
+
+func (s *Store[T]) MustLoad(slot string) (T, SlotInfo) {
+	value, info, err := s.Load(slot)
+	fsys.Must(err)
+	return value, info
+}
+
+// Info describes the save Load would read for a slot, without decoding or migrating its value.
+func (s *Store[T]) Info(slot string) (SlotInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, info, err := s.read(slot)
+	return info, err
+}
+
+// Exists reports whether a slot has a save or a backup.
+func (s *Store[T]) Exists(slot string) bool {
+	if !validSlot(slot) {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for backup := 0; backup <= s.options.Backups; backup++ {
+		if _, err := os.Stat(s.path(slot, backup)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Slots returns the sorted names of the slots that have a save or a backup in the store's directory.
+func (s *Store[T]) Slots() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := hashset.New[string]()
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if slot, ok := s.slotOf(entry.Name()); ok {
+			slots.Add(slot)
+		}
+	}
+
+	sorted := slots.ToSlice()
+	slices.Sort(sorted)
+	return sorted, nil
+}
+
+// Delete removes the save and the backups of a slot.
+func (s *Store[T]) Delete(slot string) error {
+	if !validSlot(slot) {
+		return fmt.Errorf("%w: %q", ErrSlotInvalid, slot)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := false
+	for backup := 0; backup <= s.options.Backups; backup++ {
+		err := os.Remove(s.path(slot, backup))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		removed = true
+	}
+
+	if !removed {
+		return fmt.Errorf("%w: %s", ErrSlotNotFound, slot)
+	}
+	return nil
+}
+
+// read reads and verifies the save of a slot, falling back to its backups. The store's lock must be
+// held.
+func (s *Store[T]) read(slot string) (*record, SlotInfo, error) {
+	info := SlotInfo{Slot: slot}
+
+	if !validSlot(slot) {
+		return nil, info, fmt.Errorf("%w: %q", ErrSlotInvalid, slot)
+	}
+
+	var first error
+	for backup := 0; backup <= s.options.Backups; backup++ {
+		name := s.path(slot, backup)
+
+		data, err := os.ReadFile(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		var r *record
+		if err == nil {
+			r, err = decode(data)
+		}
+		if err != nil {
+			if first == nil {
+				first = fmt.Errorf("%s: %w", name, err)
+			}
+			continue
+		}
+
+		info.Version = r.version
+		info.Saved = r.saved
+		info.Size = int64(len(data))
+		info.Compressed = r.compressed
+		info.Backup = backup
+
+		return r, info, nil
+	}
+
+	if first != nil {
+		return nil, info, first
+	}
+	return nil, info, fmt.Errorf("%w: %s", ErrSlotNotFound, slot)
+}
+
+// rotate shifts the backups of a slot down by one and copies its save into the newest backup. A save
+// that fails its checksum is not kept, so it cannot push out good backups. The store's lock must be
+// held.
+func (s *Store[T]) rotate(slot string) error {
+	if s.options.Backups == 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path(slot, 0))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := decode(data); err != nil {
+		return nil
+	}
+
+	for backup := s.options.Backups - 1; backup >= 1; backup-- {
+		err := os.Rename(s.path(slot, backup), s.path(slot, backup+1))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return fsys.WriteFileAtomic(s.path(slot, 1), data, 0o644)
+}
+
+// path returns the file of a slot's save, or of one of its backups.
+func (s *Store[T]) path(slot string, backup int) string {
+	name := slot + s.options.Extension
+	if backup > 0 {
+		name += "." + strconv.Itoa(backup)
+	}
+	return filepath.Join(s.dir, name)
+}
+
+// slotOf returns the slot of a save or backup file name.
+func (s *Store[T]) slotOf(name string) (string, bool) {
+	if slot, ok := strings.CutSuffix(name, s.options.Extension); ok {
+		return slot, validSlot(slot)
+	}
+
+	i := strings.LastIndex(name, s.options.Extension+".")
+	if i < 0 {
+		return "", false
+	}
+
+	if backup, err := strconv.Atoi(name[i+len(s.options.Extension)+1:]); err != nil || backup < 1 {
+		return "", false
+	}
+
+	slot := name[:i]
+	return slot, validSlot(slot)
+}
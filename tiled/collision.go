@@ -0,0 +1,150 @@
+package tiled
+
+import (
+	"strings"
+
+	"github.com/adm87/finch-core/partition"
+)
+
+// CollisionClass is the class or name that marks a layer or object as collision.
+const CollisionClass = "collision"
+
+// ======================================================
+// Collision Objects
+// ======================================================
+
+// ObjectFilter selects objects of a map. The layer is nil for collision shapes of tiles.
+type ObjectFilter func(layer *Layer, object *Object) bool
+
+// IsCollision is the default object filter. It selects the collision shapes of tiles, every
+// object of a layer named or classed "collision", objects classed "collision", and layers
+// or objects with a "collision" bool property set.
+func IsCollision(layer *Layer, object *Object) bool {
+	if layer == nil {
+		return true
+	}
+	if strings.EqualFold(layer.Name, CollisionClass) || strings.EqualFold(layer.Class, CollisionClass) {
+		return true
+	}
+	if strings.EqualFold(object.Class, CollisionClass) {
+		return true
+	}
+	return layer.Properties.Bool(CollisionClass) || object.Properties.Bool(CollisionClass)
+}
+
+// CollisionObjects returns the objects of the map selected by the filter, in world space.
+//
+// Objects of object groups are offset by their layer and its parents. Collision shapes of tiles
+// are placed at their tile's position, on orthogonal maps only. If filter is nil, IsCollision is used.
+func (m *Map) CollisionObjects(filter ObjectFilter) []*Object {
+	if filter == nil {
+		filter = IsCollision
+	}
+
+	var objects []*Object
+
+	var walk func(layers []*Layer, offsetX, offsetY float64)
+	walk = func(layers []*Layer, offsetX, offsetY float64) {
+		for _, layer := range layers {
+			x, y := offsetX+layer.OffsetX, offsetY+layer.OffsetY
+
+			switch layer.Type {
+			case ObjectGroup:
+				for _, object := range layer.Objects {
+					if filter(layer, object) {
+						objects = append(objects, offsetObject(object, x, y))
+					}
+				}
+			case TileLayer:
+				objects = append(objects, m.tileCollisionObjects(layer, x, y, filter)...)
+			case GroupLayer:
+				walk(layer.Layers, x, y)
+			}
+		}
+	}
+	walk(m.Layers, 0, 0)
+
+	return objects
+}
+
+func (m *Map) tileCollisionObjects(layer *Layer, offsetX, offsetY float64, filter ObjectFilter) []*Object {
+	if m.Orientation != "" && m.Orientation != "orthogonal" {
+		return nil
+	}
+
+	var objects []*Object
+
+	place := func(x, y int, gid GID) {
+		ref, id, ok := m.TilesetFor(gid)
+		if !ok || ref.Tileset == nil {
+			return
+		}
+		tile, ok := ref.Tileset.Tile(id)
+		if !ok {
+			return
+		}
+
+		// Tiles taller than the map grid are drawn upwards from the bottom of their cell.
+		tileX := offsetX + float64(x*m.TileWidth)
+		tileY := offsetY + float64((y+1)*m.TileHeight-ref.Tileset.TileHeight)
+
+		for _, object := range tile.Objects {
+			if filter(nil, object) {
+				objects = append(objects, offsetObject(object, tileX, tileY))
+			}
+		}
+	}
+
+	for i, gid := range layer.Data {
+		if !gid.IsEmpty() {
+			place(i%layer.Width, i/layer.Width, gid)
+		}
+	}
+
+	for _, chunk := range layer.Chunks {
+		for i, gid := range chunk.Data {
+			if !gid.IsEmpty() {
+				place(chunk.X+i%chunk.Width, chunk.Y+i/chunk.Width, gid)
+			}
+		}
+	}
+
+	return objects
+}
+
+// offsetObject returns the object moved by an offset. The object is copied unless the offset is zero.
+func offsetObject(object *Object, x, y float64) *Object {
+	if x == 0 && y == 0 {
+		return object
+	}
+	moved := *object
+	moved.X += x
+	moved.Y += y
+	return &moved
+}
+
+// ======================================================
+// Partitioning
+// ======================================================
+
+// Populate inserts objects into a partition structure and returns how many were inserted.
+// Objects without an area, such as points, are skipped.
+func Populate(p partition.Partitioning[*Object], objects []*Object) int {
+	count := 0
+	for _, object := range objects {
+		bounds := object.Bounds()
+		if bounds.Width <= 0 || bounds.Height <= 0 {
+			continue
+		}
+		if p.Insert(object) {
+			count++
+		}
+	}
+	return count
+}
+
+// PopulatePartitioning inserts the objects of the map selected by the filter into a partition
+// structure, such as a hashgrid or quadtree, and returns how many were inserted.
+func (m *Map) PopulatePartitioning(p partition.Partitioning[*Object], filter ObjectFilter) int {
+	return Populate(p, m.CollisionObjects(filter))
+}
@@ -0,0 +1,138 @@
+package tiled
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-core/partition"
+	"github.com/adm87/finch-core/partition/hashgrid"
+	"github.com/adm87/finch-core/partition/quadtree"
+)
+
+func TestCollisionObjects(t *testing.T) {
+	m, err := DecodeTMX(strings.NewReader(levelTMX))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []geom.Rect64
+	for _, object := range m.CollisionObjects(nil) {
+		got = append(got, object.Bounds())
+	}
+
+	want := []geom.Rect64{
+		// The tile shape, placed at each tile 1 of the ground layer. Tiles are 32 high on a 16 high
+		// grid, so they start 16 above their cell.
+		geom.NewRect64(2, -12, 12, 8),
+		geom.NewRect64(2, 4, 12, 8),
+		// The collision layer, offset by its group and itself.
+		geom.NewRect64(110, 55, 32, 16),
+		geom.NewRect64(114, 59, 0, 0),
+		// The collision classed object of the decor layer, offset by its group only.
+		geom.NewRect64(101, 52, 3, 4),
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("CollisionObjects() bounds = %v, want %v", got, want)
+	}
+
+	decor, _ := m.Layer("decor")
+	if decor.Objects[0].X != 1 {
+		t.Fatalf("CollisionObjects() moved the map's own object to %v", decor.Objects[0].X)
+	}
+
+	lamps := m.CollisionObjects(func(layer *Layer, object *Object) bool {
+		return layer != nil && object.Properties.Bool("light")
+	})
+	if len(lamps) != 1 || lamps[0].Name != "lamp" || lamps[0].X != 108 {
+		t.Fatalf("CollisionObjects() with a filter = %v, want the lamp offset by its group", lamps)
+	}
+
+	m.Orientation = "isometric"
+	if got := len(m.CollisionObjects(nil)); got != 3 {
+		t.Fatalf("isometric map has %d collision objects, want the 3 objects without tile shapes", got)
+	}
+}
+
+func TestIsCollision(t *testing.T) {
+	tests := []struct {
+		name   string
+		layer  *Layer
+		object *Object
+		want   bool
+	}{
+		{name: "tile shape", object: &Object{}, want: true},
+		{name: "layer name", layer: &Layer{Name: "Collision"}, object: &Object{}, want: true},
+		{name: "layer class", layer: &Layer{Class: "collision"}, object: &Object{}, want: true},
+		{name: "object class", layer: &Layer{}, object: &Object{Class: "COLLISION"}, want: true},
+		{name: "layer property", layer: &Layer{Properties: Properties{{Name: "collision", Type: "bool", Value: "true"}}}, object: &Object{}, want: true},
+		{name: "object property", layer: &Layer{}, object: &Object{Properties: Properties{{Name: "collision", Type: "bool", Value: "true"}}}, want: true},
+		{name: "property unset", layer: &Layer{}, object: &Object{Properties: Properties{{Name: "collision", Type: "bool", Value: "false"}}}},
+		{name: "other", layer: &Layer{Name: "decor"}, object: &Object{Name: "lamp"}},
+	}
+
+	for _, tt := range tests {
+		if got := IsCollision(tt.layer, tt.object); got != tt.want {
+			t.Errorf("IsCollision() of %s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPopulate(t *testing.T) {
+	partitions := []struct {
+		name string
+		new  func() partition.Partitioning[*Object]
+	}{
+		{name: "hashgrid", new: func() partition.Partitioning[*Object] { return hashgrid.New[*Object](32) }},
+		{name: "quadtree", new: func() partition.Partitioning[*Object] {
+			return quadtree.New[*Object](geom.NewRect64(-64, -64, 256, 256), 2, 4)
+		}},
+	}
+
+	for _, tt := range partitions {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := DecodeTMJ(strings.NewReader(levelTMJ))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p := tt.new()
+
+			// The point of the collision layer has no area, so it is skipped.
+			if got := m.PopulatePartitioning(p, nil); got != 4 {
+				t.Fatalf("PopulatePartitioning() = %d, want 4", got)
+			}
+
+			// Queries are broad, so they may also return the neighbours of the area.
+			contains := func(area geom.Rect64, bounds geom.Rect64) bool {
+				for object := range p.Query(area) {
+					if object.Bounds() == bounds {
+						return true
+					}
+				}
+				return false
+			}
+
+			if !contains(geom.NewRect64(112, 60, 4, 4), geom.NewRect64(110, 55, 32, 16)) {
+				t.Fatal("query of the collision layer did not find its rectangle")
+			}
+			if !contains(geom.NewRect64(4, -10, 4, 4), geom.NewRect64(2, -12, 12, 8)) {
+				t.Fatal("query of the ground tiles did not find the tile shape")
+			}
+			if got := len(p.Query(geom.NewRect64(-64, -64, 256, 256))); got != 4 {
+				t.Fatalf("query of the map found %d objects, want 4", got)
+			}
+			if got := len(p.Query(geom.NewRect64(1000, 1000, 8, 8))); got != 0 {
+				t.Fatalf("query outside the map found %d objects", got)
+			}
+		})
+	}
+
+	point := &Object{X: 4, Y: 4, Point: true}
+	flat := &Object{X: 0, Y: 0, Polyline: []geom.Point64{geom.NewPoint64(0, 0), geom.NewPoint64(10, 0)}}
+	box := &Object{X: 0, Y: 0, Width: 8, Height: 8}
+	if got := Populate(hashgrid.New[*Object](16), []*Object{point, flat, box}); got != 1 {
+		t.Fatalf("Populate() = %d, want only the object with an area", got)
+	}
+}
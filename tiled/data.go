@@ -0,0 +1,131 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/adm87/finch-core/geom"
+	"github.com/klauspost/compress/zstd"
+)
+
+// decodeTileData decodes csv or base64 encoded tile data, optionally zlib, gzip or zstd compressed.
+func decodeTileData(encoding, compression, data string) ([]GID, error) {
+	switch encoding {
+	case "csv":
+		return decodeCSV(data)
+	case "base64":
+		return decodeBase64(compression, data)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrEncodingUnsupported, encoding)
+	}
+}
+
+func decodeCSV(data string) ([]GID, error) {
+	fields := strings.FieldsFunc(data, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r' || r == ' ' || r == '\t'
+	})
+
+	gids := make([]GID, len(fields))
+	for i, field := range fields {
+		gid, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		gids[i] = GID(gid)
+	}
+
+	return gids, nil
+}
+
+func decodeBase64(compression, data string) ([]GID, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = bytes.NewReader(raw)
+	switch compression {
+	case "":
+	case "zlib":
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	case "gzip":
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		r = gr
+	case "zstd":
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return nil, fmt.Errorf("%w: compression %q", ErrEncodingUnsupported, compression)
+	}
+
+	raw, err = io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw)%4 != 0 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrDataSizeMismatch, len(raw))
+	}
+
+	gids := make([]GID, len(raw)/4)
+	for i := range gids {
+		gids[i] = GID(binary.LittleEndian.Uint32(raw[i*4:]))
+	}
+
+	return gids, nil
+}
+
+// checkTileData checks that decoded tile data covers a layer or chunk of the given size.
+func checkTileData(gids []GID, width, height int) error {
+	if len(gids) != width*height {
+		return fmt.Errorf("%w: %d tiles for %dx%d", ErrDataSizeMismatch, len(gids), width, height)
+	}
+	return nil
+}
+
+// parsePoints parses a list of points written as "x,y x,y ...".
+func parsePoints(points string) ([]geom.Point64, error) {
+	fields := strings.Fields(points)
+	parsed := make([]geom.Point64, 0, len(fields))
+
+	for _, field := range fields {
+		xs, ys, found := strings.Cut(field, ",")
+		if !found {
+			return nil, fmt.Errorf("tiled: invalid point %q", field)
+		}
+
+		x, err := strconv.ParseFloat(xs, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := strconv.ParseFloat(ys, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed = append(parsed, geom.NewPoint64(x, y))
+	}
+
+	return parsed, nil
+}
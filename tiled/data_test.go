@@ -0,0 +1,161 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/adm87/finch-core/geom"
+	"github.com/klauspost/compress/zstd"
+)
+
+// encodeTiles writes gids as base64 encoded tile data, compressed as Tiled does.
+func encodeTiles(t *testing.T, compression string, gids ...GID) string {
+	t.Helper()
+
+	raw := make([]byte, 0, len(gids)*4)
+	for _, gid := range gids {
+		raw = binary.LittleEndian.AppendUint32(raw, uint32(gid))
+	}
+
+	var buf bytes.Buffer
+
+	var w io.WriteCloser
+	switch compression {
+	case "":
+		buf.Write(raw)
+		return base64.StdEncoding.EncodeToString(buf.Bytes())
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "zstd":
+		zw, err := zstd.NewWriter(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = zw
+	default:
+		t.Fatalf("unknown compression %q", compression)
+	}
+
+	if _, err := w.Write(raw); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestDecodeTileData(t *testing.T) {
+	gids := []GID{1, 0, 3 | FlipHorizontal, 4 | FlipVertical | FlipDiagonal}
+
+	tests := []struct {
+		name        string
+		encoding    string
+		compression string
+		data        string
+		want        []GID
+		err         error
+	}{
+		{name: "csv", encoding: "csv", data: "1,0,2147483651,1610612740", want: gids},
+		{name: "csv rows", encoding: "csv", data: "\n1,0,\r\n 2147483651,\t1610612740\n", want: gids},
+		{name: "base64", encoding: "base64", data: encodeTiles(t, "", gids...), want: gids},
+		{name: "base64 gzip", encoding: "base64", compression: "gzip", data: encodeTiles(t, "gzip", gids...), want: gids},
+		{name: "base64 zlib", encoding: "base64", compression: "zlib", data: encodeTiles(t, "zlib", gids...), want: gids},
+		{name: "base64 zstd", encoding: "base64", compression: "zstd", data: encodeTiles(t, "zstd", gids...), want: gids},
+		{name: "base64 padded", encoding: "base64", data: "\n   " + encodeTiles(t, "", gids...) + "\n", want: gids},
+		{name: "unknown encoding", encoding: "xml", data: "", err: ErrEncodingUnsupported},
+		{name: "unknown compression", encoding: "base64", compression: "lz4", data: encodeTiles(t, ""), err: ErrEncodingUnsupported},
+		{name: "partial gid", encoding: "base64", data: base64.StdEncoding.EncodeToString([]byte{1, 0, 0, 0, 2}), err: ErrDataSizeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeTileData(tt.encoding, tt.compression, tt.data)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("decodeTileData() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && !slices.Equal(got, tt.want) {
+				t.Fatalf("decodeTileData() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name        string
+		encoding    string
+		compression string
+		data        string
+	}{
+		{name: "csv text", encoding: "csv", data: "1,two,3"},
+		{name: "csv overflow", encoding: "csv", data: "4294967296"},
+		{name: "base64 text", encoding: "base64", data: "not base64!"},
+		{name: "gzip of plain data", encoding: "base64", compression: "gzip", data: encodeTiles(t, "", gids...)},
+		{name: "zlib of plain data", encoding: "base64", compression: "zlib", data: encodeTiles(t, "", gids...)},
+		{name: "zstd of plain data", encoding: "base64", compression: "zstd", data: encodeTiles(t, "", gids...)},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeTileData(tt.encoding, tt.compression, tt.data); err == nil {
+				t.Fatal("decodeTileData() of invalid data succeeded")
+			}
+		})
+	}
+}
+
+func TestGIDFlags(t *testing.T) {
+	tests := []struct {
+		gid        GID
+		id         uint32
+		horizontal bool
+		vertical   bool
+		diagonal   bool
+	}{
+		{gid: 0},
+		{gid: 7, id: 7},
+		{gid: 7 | FlipHorizontal, id: 7, horizontal: true},
+		{gid: 7 | FlipVertical, id: 7, vertical: true},
+		{gid: 7 | FlipDiagonal, id: 7, diagonal: true},
+		{gid: 7 | FlipHorizontal | FlipVertical | FlipDiagonal | RotateHex120, id: 7, horizontal: true, vertical: true, diagonal: true},
+		{gid: FlipHorizontal, horizontal: true},
+	}
+
+	for _, tt := range tests {
+		if tt.gid.ID() != tt.id || tt.gid.IsEmpty() != (tt.id == 0) {
+			t.Errorf("GID(%#x) id = %d, empty = %v, want %d", uint32(tt.gid), tt.gid.ID(), tt.gid.IsEmpty(), tt.id)
+		}
+		if tt.gid.FlippedHorizontally() != tt.horizontal || tt.gid.FlippedVertically() != tt.vertical || tt.gid.FlippedDiagonally() != tt.diagonal {
+			t.Errorf("GID(%#x) flips = %v %v %v, want %v %v %v", uint32(tt.gid),
+				tt.gid.FlippedHorizontally(), tt.gid.FlippedVertically(), tt.gid.FlippedDiagonally(),
+				tt.horizontal, tt.vertical, tt.diagonal)
+		}
+	}
+}
+
+func TestParsePoints(t *testing.T) {
+	got, err := parsePoints("0,0 16,0.5  -8,32")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []geom.Point64{geom.NewPoint64(0, 0), geom.NewPoint64(16, 0.5), geom.NewPoint64(-8, 32)}
+	if !slices.Equal(got, want) {
+		t.Fatalf("parsePoints() = %v, want %v", got, want)
+	}
+
+	for _, points := range []string{"0,0 16", "a,0", "0,b"} {
+		if _, err := parsePoints(points); err == nil {
+			t.Errorf("parsePoints(%q) succeeded", points)
+		}
+	}
+}
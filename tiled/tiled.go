@@ -0,0 +1,363 @@
+// Package tiled decodes maps and tilesets made with the Tiled map editor, in both the
+// XML (.tmx, .tsx) and JSON (.tmj, .tsj) formats.
+//
+// External tilesets are referenced by source and resolved separately with
+// Map.ResolveTilesets, so the caller decides how tileset files are read.
+package tiled
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+var (
+	ErrTilesetNotResolved  = errors.New("tiled: tileset is not resolved")
+	ErrEncodingUnsupported = errors.New("tiled: tile data encoding is unsupported")
+	ErrDataSizeMismatch    = errors.New("tiled: tile data size does not match layer size")
+)
+
+// ======================================================
+// GID
+// ======================================================
+
+// GID is a global tile id, with the flip flags of the tile in its highest bits.
+// Zero means no tile.
+type GID uint32
+
+const (
+	FlipHorizontal GID = 0x80000000
+	FlipVertical   GID = 0x40000000
+	FlipDiagonal   GID = 0x20000000
+	RotateHex120   GID = 0x10000000
+
+	flipMask = FlipHorizontal | FlipVertical | FlipDiagonal | RotateHex120
+)
+
+// ID returns the global tile id without its flip flags.
+func (g GID) ID() uint32 {
+	return uint32(g &^ flipMask)
+}
+
+func (g GID) IsEmpty() bool {
+	return g.ID() == 0
+}
+
+func (g GID) FlippedHorizontally() bool {
+	return g&FlipHorizontal != 0
+}
+
+func (g GID) FlippedVertically() bool {
+	return g&FlipVertical != 0
+}
+
+func (g GID) FlippedDiagonally() bool {
+	return g&FlipDiagonal != 0
+}
+
+// ======================================================
+// Properties
+// ======================================================
+
+// Property is a custom property. Values are kept as their string representation.
+type Property struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// Properties is a list of custom properties.
+type Properties []Property
+
+func (p Properties) Get(name string) (Property, bool) {
+	for _, property := range p {
+		if property.Name == name {
+			return property, true
+		}
+	}
+	return Property{}, false
+}
+
+func (p Properties) String(name string) string {
+	property, _ := p.Get(name)
+	return property.Value
+}
+
+func (p Properties) Bool(name string) bool {
+	property, _ := p.Get(name)
+	value, _ := strconv.ParseBool(property.Value)
+	return value
+}
+
+func (p Properties) Int(name string) int {
+	property, _ := p.Get(name)
+	value, _ := strconv.Atoi(property.Value)
+	return value
+}
+
+func (p Properties) Float(name string) float64 {
+	property, _ := p.Get(name)
+	value, _ := strconv.ParseFloat(property.Value, 64)
+	return value
+}
+
+// ======================================================
+// Tileset
+// ======================================================
+
+// Image is an image referenced by a tileset, tile or image layer.
+type Image struct {
+	Source string
+	Width  int
+	Height int
+}
+
+// Frame is a frame of an animated tile.
+type Frame struct {
+	TileID   int
+	Duration time.Duration
+}
+
+// Tile holds the data of a tile that differs from its tileset's defaults.
+type Tile struct {
+	ID         int
+	Class      string
+	Properties Properties
+	Image      *Image    // Set for tiles of image collection tilesets
+	Objects    []*Object // Collision shapes, relative to the tile's top left corner
+	Animation  []Frame
+}
+
+// Tileset is a set of tiles cut from a single image, or an image collection.
+type Tileset struct {
+	Name       string
+	Class      string
+	TileWidth  int
+	TileHeight int
+	TileCount  int
+	Columns    int
+	Spacing    int
+	Margin     int
+	Image      *Image
+	Tiles      []*Tile
+	Properties Properties
+}
+
+// Tile returns the data of a tile, by its local id.
+func (t *Tileset) Tile(id int) (*Tile, bool) {
+	for _, tile := range t.Tiles {
+		if tile.ID == id {
+			return tile, true
+		}
+	}
+	return nil, false
+}
+
+// TileRect returns the position and size of a tile within the tileset image, by its local id.
+func (t *Tileset) TileRect(id int) (x, y, w, h int) {
+	columns := max(t.Columns, 1)
+	x = t.Margin + (id%columns)*(t.TileWidth+t.Spacing)
+	y = t.Margin + (id/columns)*(t.TileHeight+t.Spacing)
+	return x, y, t.TileWidth, t.TileHeight
+}
+
+// TilesetRef is a tileset used by a map, either embedded in it or referenced by source.
+type TilesetRef struct {
+	FirstGID GID
+	Source   string   // Path of an external tileset, relative to the map. Empty for embedded tilesets
+	Tileset  *Tileset // Nil until an external tileset is resolved
+}
+
+// ======================================================
+// Layer
+// ======================================================
+
+// LayerType is the kind of a map layer.
+type LayerType string
+
+const (
+	TileLayer   LayerType = "tilelayer"
+	ObjectGroup LayerType = "objectgroup"
+	ImageLayer  LayerType = "imagelayer"
+	GroupLayer  LayerType = "group"
+)
+
+// Chunk is a region of tiles of an infinite map's tile layer.
+type Chunk struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+	Data   []GID
+}
+
+// Layer is a layer of a map. Which fields are set depends on its type.
+type Layer struct {
+	ID         int
+	Name       string
+	Class      string
+	Type       LayerType
+	Visible    bool
+	Opacity    float64
+	OffsetX    float64
+	OffsetY    float64
+	Width      int
+	Height     int
+	Data       []GID     // Tiles of a finite tile layer, row by row
+	Chunks     []Chunk   // Tiles of an infinite tile layer
+	Objects    []*Object // Objects of an object group
+	Image      *Image    // Image of an image layer
+	Layers     []*Layer  // Children of a group layer
+	Properties Properties
+}
+
+// TileAt returns the tile at a position of a tile layer, or zero if there is none.
+func (l *Layer) TileAt(x, y int) GID {
+	if x >= 0 && y >= 0 && x < l.Width && y < l.Height && len(l.Data) == l.Width*l.Height {
+		return l.Data[y*l.Width+x]
+	}
+
+	for _, chunk := range l.Chunks {
+		cx, cy := x-chunk.X, y-chunk.Y
+		if cx >= 0 && cy >= 0 && cx < chunk.Width && cy < chunk.Height {
+			return chunk.Data[cy*chunk.Width+cx]
+		}
+	}
+
+	return 0
+}
+
+// ======================================================
+// Object
+// ======================================================
+
+// Object is an object of an object group, or a collision shape of a tile.
+//
+// Objects implement geom.Bounded, so they can be inserted into a partition structure.
+type Object struct {
+	ID         int
+	Name       string
+	Class      string
+	X          float64
+	Y          float64
+	Width      float64
+	Height     float64
+	Rotation   float64
+	GID        GID // Set for tile objects, whose position is their bottom left corner
+	Visible    bool
+	Ellipse    bool
+	Point      bool
+	Polygon    []geom.Point64 // Points relative to the object's position
+	Polyline   []geom.Point64 // Points relative to the object's position
+	Properties Properties
+}
+
+// Bounds returns the axis aligned bounds of the object. Rotation is not taken into account.
+func (o *Object) Bounds() geom.Rect64 {
+	points := o.Polygon
+	if len(points) == 0 {
+		points = o.Polyline
+	}
+
+	if len(points) > 0 {
+		minX, minY := math.Inf(1), math.Inf(1)
+		maxX, maxY := math.Inf(-1), math.Inf(-1)
+		for _, p := range points {
+			minX, minY = min(minX, p.X), min(minY, p.Y)
+			maxX, maxY = max(maxX, p.X), max(maxY, p.Y)
+		}
+		return geom.NewRect64(o.X+minX, o.Y+minY, maxX-minX, maxY-minY)
+	}
+
+	if o.GID != 0 {
+		return geom.NewRect64(o.X, o.Y-o.Height, o.Width, o.Height)
+	}
+
+	return geom.NewRect64(o.X, o.Y, o.Width, o.Height)
+}
+
+// ======================================================
+// Map
+// ======================================================
+
+// Map is a Tiled map.
+type Map struct {
+	Orientation string
+	RenderOrder string
+	Class       string
+	Width       int
+	Height      int
+	TileWidth   int
+	TileHeight  int
+	Infinite    bool
+	Tilesets    []*TilesetRef
+	Layers      []*Layer
+	Properties  Properties
+}
+
+// ResolveTilesets loads the external tilesets of the map with the given function, which
+// receives the source of each tileset as written in the map.
+func (m *Map) ResolveTilesets(load func(source string) (*Tileset, error)) error {
+	for _, ref := range m.Tilesets {
+		if ref.Tileset != nil || ref.Source == "" {
+			continue
+		}
+
+		tileset, err := load(ref.Source)
+		if err != nil {
+			return err
+		}
+		ref.Tileset = tileset
+	}
+	return nil
+}
+
+// TilesetFor returns the tileset a tile belongs to, and the tile's local id within it.
+func (m *Map) TilesetFor(gid GID) (*TilesetRef, int, bool) {
+	id := gid.ID()
+	if id == 0 {
+		return nil, 0, false
+	}
+
+	var found *TilesetRef
+	for _, ref := range m.Tilesets {
+		if ref.FirstGID.ID() <= id && (found == nil || ref.FirstGID > found.FirstGID) {
+			found = ref
+		}
+	}
+
+	if found == nil {
+		return nil, 0, false
+	}
+
+	return found, int(id - found.FirstGID.ID()), true
+}
+
+// Layer returns the first layer with the given name, searching group layers depth first.
+func (m *Map) Layer(name string) (*Layer, bool) {
+	for _, layer := range m.AllLayers() {
+		if layer.Name == name {
+			return layer, true
+		}
+	}
+	return nil, false
+}
+
+// AllLayers returns every layer of the map, with group layers followed by their children.
+func (m *Map) AllLayers() []*Layer {
+	layers := make([]*Layer, 0, len(m.Layers))
+
+	var walk func(children []*Layer)
+	walk = func(children []*Layer) {
+		for _, layer := range children {
+			layers = append(layers, layer)
+			walk(layer.Layers)
+		}
+	}
+	walk(m.Layers)
+
+	return layers
+}
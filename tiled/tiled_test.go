@@ -0,0 +1,112 @@
+package tiled
+
+import (
+	"slices"
+	"testing"
+)
+
+// checkLevel checks a decoded level fixture, which the TMX and TMJ tests write in both formats:
+//
+//   - a 2x2 orthogonal map of 16x16 tiles, with float and string properties;
+//   - an embedded tileset of 16x32 tiles, whose tile 1 has a collision shape, and an external tileset "props";
+//   - a "ground" tile layer of tiles 2, none, 2 flipped horizontally, and 1;
+//   - a "world" group offset by 100,50 holding a "collision" object group offset by 10,5, with a rectangle
+//     and a point, and a hidden "decor" object group, with a collision classed object, a lamp and a polyline.
+func checkLevel(t *testing.T, m *Map) {
+	t.Helper()
+
+	if m.Orientation != "orthogonal" || m.Width != 2 || m.Height != 2 || m.TileWidth != 16 || m.TileHeight != 16 || m.Infinite {
+		t.Fatalf("map = %s %dx%d of %dx%d tiles, infinite %v", m.Orientation, m.Width, m.Height, m.TileWidth, m.TileHeight, m.Infinite)
+	}
+	if m.Properties.Float("gravity") != 9.8 || m.Properties.String("title") != "Level One" {
+		t.Errorf("map properties = %v", m.Properties)
+	}
+
+	if len(m.Tilesets) != 2 {
+		t.Fatalf("map has %d tilesets, want 2", len(m.Tilesets))
+	}
+	tiles := m.Tilesets[0].Tileset
+	if tiles == nil || tiles.Name != "tiles" || tiles.TileHeight != 32 || tiles.Image == nil || tiles.Image.Source != "tiles.png" {
+		t.Fatalf("embedded tileset = %+v", tiles)
+	}
+	if tile, ok := tiles.Tile(1); !ok || tile.Class != "wall" || len(tile.Objects) != 1 {
+		t.Fatalf("tile 1 = %+v, want a wall with a collision shape", tile)
+	}
+	if ref := m.Tilesets[1]; ref.Source == "" || ref.Tileset != nil || ref.FirstGID != 5 {
+		t.Fatalf("external tileset = %+v, want an unresolved reference", ref)
+	}
+
+	ground, ok := m.Layer("ground")
+	if !ok || ground.Type != TileLayer || !ground.Visible || ground.Opacity != 1 {
+		t.Fatalf("ground layer = %+v", ground)
+	}
+	if want := []GID{2, 0, 2 | FlipHorizontal, 1}; !slices.Equal(ground.Data, want) {
+		t.Fatalf("ground tiles = %v, want %v", ground.Data, want)
+	}
+	if gid := ground.TileAt(0, 1); gid.ID() != 2 || !gid.FlippedHorizontally() || gid.FlippedVertically() {
+		t.Errorf("tile at 0,1 = %#x, want tile 2 flipped horizontally", uint32(gid))
+	}
+	if gid := ground.TileAt(2, 0); gid != 0 {
+		t.Errorf("tile outside the layer = %#x, want none", uint32(gid))
+	}
+
+	world, ok := m.Layer("world")
+	if !ok || world.Type != GroupLayer || world.OffsetX != 100 || world.OffsetY != 50 || len(world.Layers) != 2 {
+		t.Fatalf("world layer = %+v", world)
+	}
+
+	decor, ok := m.Layer("decor")
+	if !ok || decor.Type != ObjectGroup || decor.Visible || len(decor.Objects) != 3 {
+		t.Fatalf("decor layer = %+v", decor)
+	}
+	if lamp := decor.Objects[1]; lamp.Name != "lamp" || !lamp.Properties.Bool("light") {
+		t.Errorf("lamp = %+v", lamp)
+	}
+	if path := decor.Objects[2]; len(path.Polyline) != 3 || path.Bounds().Height != 5 || path.Bounds().Y != -5 {
+		t.Errorf("path = %+v, bounds %v", path, path.Bounds())
+	}
+
+	var names []string
+	for _, layer := range m.AllLayers() {
+		names = append(names, layer.Name)
+	}
+	if want := []string{"ground", "world", "collision", "decor"}; !slices.Equal(names, want) {
+		t.Errorf("AllLayers() = %v, want %v", names, want)
+	}
+
+	ref, id, ok := m.TilesetFor(6 | FlipDiagonal)
+	if !ok || ref != m.Tilesets[1] || id != 1 {
+		t.Errorf("TilesetFor(6) = %+v, %d, want tile 1 of the external tileset", ref, id)
+	}
+	if _, _, ok := m.TilesetFor(FlipHorizontal); ok {
+		t.Error("TilesetFor() of an empty tile found a tileset")
+	}
+}
+
+func TestTileAtChunks(t *testing.T) {
+	layer := &Layer{
+		Type:  TileLayer,
+		Width: 4, Height: 1,
+		Chunks: []Chunk{
+			{X: -2, Y: 0, Width: 2, Height: 1, Data: []GID{1, 0}},
+			{X: 0, Y: 0, Width: 2, Height: 1, Data: []GID{0, 2}},
+		},
+	}
+
+	tests := []struct {
+		x, y int
+		want GID
+	}{
+		{x: -2, y: 0, want: 1},
+		{x: -1, y: 0, want: 0},
+		{x: 1, y: 0, want: 2},
+		{x: 2, y: 0, want: 0},
+		{x: 0, y: 1, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := layer.TileAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("TileAt(%d, %d) = %d, want %d", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
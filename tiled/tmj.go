@@ -0,0 +1,368 @@
+package tiled
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+// ======================================================
+// TMJ/TSJ Decoding
+// ======================================================
+
+type jsonMap struct {
+	Orientation string         `json:"orientation"`
+	RenderOrder string         `json:"renderorder"`
+	Class       string         `json:"class"`
+	Width       int            `json:"width"`
+	Height      int            `json:"height"`
+	TileWidth   int            `json:"tilewidth"`
+	TileHeight  int            `json:"tileheight"`
+	Infinite    bool           `json:"infinite"`
+	Tilesets    []jsonTileset  `json:"tilesets"`
+	Layers      []jsonLayer    `json:"layers"`
+	Properties  []jsonProperty `json:"properties"`
+}
+
+type jsonTileset struct {
+	FirstGID    uint32         `json:"firstgid"`
+	Source      string         `json:"source"`
+	Name        string         `json:"name"`
+	Class       string         `json:"class"`
+	TileWidth   int            `json:"tilewidth"`
+	TileHeight  int            `json:"tileheight"`
+	TileCount   int            `json:"tilecount"`
+	Columns     int            `json:"columns"`
+	Spacing     int            `json:"spacing"`
+	Margin      int            `json:"margin"`
+	Image       string         `json:"image"`
+	ImageWidth  int            `json:"imagewidth"`
+	ImageHeight int            `json:"imageheight"`
+	Tiles       []jsonTile     `json:"tiles"`
+	Properties  []jsonProperty `json:"properties"`
+}
+
+type jsonTile struct {
+	ID          int            `json:"id"`
+	Class       string         `json:"class"`
+	Type        string         `json:"type"`
+	Image       string         `json:"image"`
+	ImageWidth  int            `json:"imagewidth"`
+	ImageHeight int            `json:"imageheight"`
+	ObjectGroup *jsonLayer     `json:"objectgroup"`
+	Properties  []jsonProperty `json:"properties"`
+	Animation   []struct {
+		TileID   int `json:"tileid"`
+		Duration int `json:"duration"`
+	} `json:"animation"`
+}
+
+type jsonLayer struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Class       string          `json:"class"`
+	Type        LayerType       `json:"type"`
+	Visible     *bool           `json:"visible"`
+	Opacity     *float64        `json:"opacity"`
+	OffsetX     float64         `json:"offsetx"`
+	OffsetY     float64         `json:"offsety"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Data        json.RawMessage `json:"data"`
+	Chunks      []jsonChunk     `json:"chunks"`
+	Objects     []jsonObject    `json:"objects"`
+	Image       string          `json:"image"`
+	ImageWidth  int             `json:"imagewidth"`
+	ImageHeight int             `json:"imageheight"`
+	Layers      []jsonLayer     `json:"layers"`
+	Properties  []jsonProperty  `json:"properties"`
+}
+
+type jsonChunk struct {
+	X      int             `json:"x"`
+	Y      int             `json:"y"`
+	Width  int             `json:"width"`
+	Height int             `json:"height"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type jsonObject struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Class      string         `json:"class"`
+	Type       string         `json:"type"`
+	X          float64        `json:"x"`
+	Y          float64        `json:"y"`
+	Width      float64        `json:"width"`
+	Height     float64        `json:"height"`
+	Rotation   float64        `json:"rotation"`
+	GID        uint32         `json:"gid"`
+	Visible    *bool          `json:"visible"`
+	Ellipse    bool           `json:"ellipse"`
+	Point      bool           `json:"point"`
+	Polygon    []jsonPoint    `json:"polygon"`
+	Polyline   []jsonPoint    `json:"polyline"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type jsonProperty struct {
+	Name  string          `json:"name"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// DecodeTMJ decodes a map in the JSON format.
+func DecodeTMJ(r io.Reader) (*Map, error) {
+	var j jsonMap
+	if err := json.NewDecoder(r).Decode(&j); err != nil {
+		return nil, err
+	}
+
+	m := &Map{
+		Orientation: j.Orientation,
+		RenderOrder: j.RenderOrder,
+		Class:       j.Class,
+		Width:       j.Width,
+		Height:      j.Height,
+		TileWidth:   j.TileWidth,
+		TileHeight:  j.TileHeight,
+		Infinite:    j.Infinite,
+		Properties:  convertJSONProperties(j.Properties),
+	}
+
+	for _, jt := range j.Tilesets {
+		ref := &TilesetRef{
+			FirstGID: GID(jt.FirstGID),
+			Source:   jt.Source,
+		}
+		if jt.Source == "" {
+			tileset, err := jt.convert()
+			if err != nil {
+				return nil, err
+			}
+			ref.Tileset = tileset
+		}
+		m.Tilesets = append(m.Tilesets, ref)
+	}
+
+	layers, err := convertJSONLayers(j.Layers)
+	if err != nil {
+		return nil, err
+	}
+	m.Layers = layers
+
+	return m, nil
+}
+
+// DecodeTSJ decodes an external tileset in the JSON format.
+func DecodeTSJ(r io.Reader) (*Tileset, error) {
+	var j jsonTileset
+	if err := json.NewDecoder(r).Decode(&j); err != nil {
+		return nil, err
+	}
+	return j.convert()
+}
+
+func (j jsonTileset) convert() (*Tileset, error) {
+	tileset := &Tileset{
+		Name:       j.Name,
+		Class:      j.Class,
+		TileWidth:  j.TileWidth,
+		TileHeight: j.TileHeight,
+		TileCount:  j.TileCount,
+		Columns:    j.Columns,
+		Spacing:    j.Spacing,
+		Margin:     j.Margin,
+		Image:      convertJSONImage(j.Image, j.ImageWidth, j.ImageHeight),
+		Properties: convertJSONProperties(j.Properties),
+	}
+
+	for _, jt := range j.Tiles {
+		tile := &Tile{
+			ID:         jt.ID,
+			Class:      firstNonEmpty(jt.Class, jt.Type),
+			Image:      convertJSONImage(jt.Image, jt.ImageWidth, jt.ImageHeight),
+			Properties: convertJSONProperties(jt.Properties),
+		}
+
+		if jt.ObjectGroup != nil {
+			tile.Objects = convertJSONObjects(jt.ObjectGroup.Objects)
+		}
+
+		for _, frame := range jt.Animation {
+			tile.Animation = append(tile.Animation, Frame{
+				TileID:   frame.TileID,
+				Duration: time.Duration(frame.Duration) * time.Millisecond,
+			})
+		}
+
+		tileset.Tiles = append(tileset.Tiles, tile)
+	}
+
+	return tileset, nil
+}
+
+func convertJSONImage(source string, width, height int) *Image {
+	if source == "" {
+		return nil
+	}
+	return &Image{Source: source, Width: width, Height: height}
+}
+
+func convertJSONProperties(js []jsonProperty) Properties {
+	if len(js) == 0 {
+		return nil
+	}
+
+	properties := make(Properties, 0, len(js))
+	for _, p := range js {
+		// Strings are unquoted; numbers, booleans and objects keep their JSON text.
+		value := string(p.Value)
+		if err := json.Unmarshal(p.Value, &value); err != nil {
+			value = string(p.Value)
+		}
+		properties = append(properties, Property{Name: p.Name, Type: p.Type, Value: value})
+	}
+	return properties
+}
+
+func convertJSONLayers(js []jsonLayer) ([]*Layer, error) {
+	layers := make([]*Layer, 0, len(js))
+
+	for _, j := range js {
+		layer := &Layer{
+			ID:         j.ID,
+			Name:       j.Name,
+			Class:      j.Class,
+			Type:       j.Type,
+			Visible:    j.Visible == nil || *j.Visible,
+			Opacity:    1,
+			OffsetX:    j.OffsetX,
+			OffsetY:    j.OffsetY,
+			Width:      j.Width,
+			Height:     j.Height,
+			Properties: convertJSONProperties(j.Properties),
+		}
+		if j.Opacity != nil {
+			layer.Opacity = *j.Opacity
+		}
+
+		switch j.Type {
+		case TileLayer:
+			if err := layer.convertJSONData(j); err != nil {
+				return nil, fmt.Errorf("layer %s: %w", j.Name, err)
+			}
+		case ObjectGroup:
+			layer.Objects = convertJSONObjects(j.Objects)
+		case ImageLayer:
+			layer.Image = convertJSONImage(j.Image, j.ImageWidth, j.ImageHeight)
+		case GroupLayer:
+			children, err := convertJSONLayers(j.Layers)
+			if err != nil {
+				return nil, err
+			}
+			layer.Layers = children
+		default:
+			continue
+		}
+
+		layers = append(layers, layer)
+	}
+
+	return layers, nil
+}
+
+func (l *Layer) convertJSONData(j jsonLayer) error {
+	if len(j.Chunks) == 0 {
+		if len(j.Data) == 0 {
+			return nil
+		}
+		gids, err := decodeJSONTiles(j.Encoding, j.Compression, j.Data)
+		if err != nil {
+			return err
+		}
+		if err := checkTileData(gids, l.Width, l.Height); err != nil {
+			return err
+		}
+		l.Data = gids
+		return nil
+	}
+
+	for _, jc := range j.Chunks {
+		gids, err := decodeJSONTiles(j.Encoding, j.Compression, jc.Data)
+		if err != nil {
+			return err
+		}
+		if err := checkTileData(gids, jc.Width, jc.Height); err != nil {
+			return err
+		}
+		l.Chunks = append(l.Chunks, Chunk{X: jc.X, Y: jc.Y, Width: jc.Width, Height: jc.Height, Data: gids})
+	}
+
+	return nil
+}
+
+// decodeJSONTiles decodes tile data written as an array of gids, or as an encoded string.
+func decodeJSONTiles(encoding, compression string, data json.RawMessage) ([]GID, error) {
+	if encoding == "base64" {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return nil, err
+		}
+		return decodeTileData(encoding, compression, text)
+	}
+
+	var gids []GID
+	if err := json.Unmarshal(data, &gids); err != nil {
+		return nil, err
+	}
+	return gids, nil
+}
+
+func convertJSONObjects(js []jsonObject) []*Object {
+	objects := make([]*Object, 0, len(js))
+
+	for _, j := range js {
+		objects = append(objects, &Object{
+			ID:         j.ID,
+			Name:       j.Name,
+			Class:      firstNonEmpty(j.Class, j.Type),
+			X:          j.X,
+			Y:          j.Y,
+			Width:      j.Width,
+			Height:     j.Height,
+			Rotation:   j.Rotation,
+			GID:        GID(j.GID),
+			Visible:    j.Visible == nil || *j.Visible,
+			Ellipse:    j.Ellipse,
+			Point:      j.Point,
+			Polygon:    convertJSONPoints(j.Polygon),
+			Polyline:   convertJSONPoints(j.Polyline),
+			Properties: convertJSONProperties(j.Properties),
+		})
+	}
+
+	return objects
+}
+
+func convertJSONPoints(js []jsonPoint) []geom.Point64 {
+	if len(js) == 0 {
+		return nil
+	}
+
+	points := make([]geom.Point64, len(js))
+	for i, p := range js {
+		points[i] = geom.NewPoint64(p.X, p.Y)
+	}
+	return points
+}
@@ -0,0 +1,176 @@
+package tiled
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const levelTMJ = `{
+ "orientation": "orthogonal", "renderorder": "right-down", "width": 2, "height": 2, "tilewidth": 16, "tileheight": 16, "infinite": false,
+ "properties": [
+  {"name": "gravity", "type": "float", "value": 9.8},
+  {"name": "title", "type": "string", "value": "Level One"}
+ ],
+ "tilesets": [
+  {
+   "firstgid": 1, "name": "tiles", "tilewidth": 16, "tileheight": 32, "tilecount": 4, "columns": 2,
+   "image": "tiles.png", "imagewidth": 32, "imageheight": 64,
+   "tiles": [
+    {"id": 1, "type": "wall", "objectgroup": {"type": "objectgroup", "objects": [{"id": 1, "x": 2, "y": 4, "width": 12, "height": 8}]}}
+   ]
+  },
+  {"firstgid": 5, "source": "props.tsj"}
+ ],
+ "layers": [
+  {"id": 1, "name": "ground", "type": "tilelayer", "width": 2, "height": 2, "data": [2, 0, 2147483650, 1]},
+  {
+   "id": 2, "name": "world", "type": "group", "offsetx": 100, "offsety": 50,
+   "layers": [
+    {
+     "id": 3, "name": "collision", "type": "objectgroup", "offsetx": 10, "offsety": 5,
+     "objects": [
+      {"id": 1, "x": 0, "y": 0, "width": 32, "height": 16},
+      {"id": 2, "x": 4, "y": 4, "point": true}
+     ]
+    },
+    {
+     "id": 4, "name": "decor", "type": "objectgroup", "visible": false,
+     "objects": [
+      {"id": 3, "class": "collision", "x": 1, "y": 2, "width": 3, "height": 4},
+      {"id": 4, "name": "lamp", "x": 8, "y": 8, "width": 4, "height": 4, "properties": [{"name": "light", "type": "bool", "value": true}]},
+      {"id": 5, "name": "path", "x": 0, "y": 0, "polyline": [{"x": 0, "y": 0}, {"x": 10, "y": -5}, {"x": 20, "y": 0}]}
+     ]
+    }
+   ]
+  }
+ ]
+}`
+
+const propsTSJ = `{
+ "name": "props", "tilewidth": 16, "tileheight": 16, "tilecount": 2, "columns": 2,
+ "image": "props.png", "imagewidth": 32, "imageheight": 16,
+ "tiles": [{"id": 0, "animation": [{"tileid": 0, "duration": 100}, {"tileid": 1, "duration": 150}]}]
+}`
+
+func TestDecodeTMJ(t *testing.T) {
+	m, err := DecodeTMJ(strings.NewReader(levelTMJ))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLevel(t, m)
+
+	err = m.ResolveTilesets(func(source string) (*Tileset, error) {
+		if source != "props.tsj" {
+			return nil, fmt.Errorf("unexpected tileset %q", source)
+		}
+		return DecodeTSJ(strings.NewReader(propsTSJ))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := m.Tilesets[1].Tileset
+	if props == nil || props.Name != "props" {
+		t.Fatalf("resolved tileset = %+v", props)
+	}
+	if tile, ok := props.Tile(0); !ok || len(tile.Animation) != 2 || tile.Animation[1].Duration.Milliseconds() != 150 {
+		t.Fatalf("animated tile = %+v", tile)
+	}
+
+	failed := errors.New("unreadable")
+	m, _ = DecodeTMJ(strings.NewReader(levelTMJ))
+	if err := m.ResolveTilesets(func(string) (*Tileset, error) { return nil, failed }); !errors.Is(err, failed) {
+		t.Fatalf("ResolveTilesets() error = %v, want %v", err, failed)
+	}
+}
+
+func TestDecodeTMJTileData(t *testing.T) {
+	gids := []GID{1, 0, 2 | FlipVertical, 3}
+
+	layer := func(fields string) string {
+		return `{"width": 2, "height": 2, "layers": [{"name": "ground", "type": "tilelayer", "width": 2, "height": 2, ` + fields + `}]}`
+	}
+
+	tests := []struct {
+		name   string
+		fields string
+		err    error
+	}{
+		{name: "array", fields: `"data": [1, 0, 1073741826, 3]`},
+		{name: "base64", fields: `"encoding": "base64", "data": "` + encodeTiles(t, "", gids...) + `"`},
+		{name: "base64 gzip", fields: `"encoding": "base64", "compression": "gzip", "data": "` + encodeTiles(t, "gzip", gids...) + `"`},
+		{name: "base64 zlib", fields: `"encoding": "base64", "compression": "zlib", "data": "` + encodeTiles(t, "zlib", gids...) + `"`},
+		{name: "base64 zstd", fields: `"encoding": "base64", "compression": "zstd", "data": "` + encodeTiles(t, "zstd", gids...) + `"`},
+		{name: "too many tiles", fields: `"data": [1, 0, 1073741826, 3, 4]`, err: ErrDataSizeMismatch},
+		{name: "unknown compression", fields: `"encoding": "base64", "compression": "lzma", "data": "` + encodeTiles(t, "", gids...) + `"`, err: ErrEncodingUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := DecodeTMJ(strings.NewReader(layer(tt.fields)))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("DecodeTMJ() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := m.Layers[0].Data; !slices.Equal(got, gids) {
+				t.Fatalf("tiles = %v, want %v", got, gids)
+			}
+		})
+	}
+}
+
+func TestDecodeTMJChunks(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields string
+		chunks [2]string
+	}{
+		{name: "array", chunks: [2]string{`[1, 0]`, `[0, 536870914]`}},
+		{
+			name:   "base64 gzip",
+			fields: `"encoding": "base64", "compression": "gzip", `,
+			chunks: [2]string{`"` + encodeTiles(t, "gzip", 1, 0) + `"`, `"` + encodeTiles(t, "gzip", 0, 2|FlipDiagonal) + `"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := fmt.Sprintf(`{"width": 4, "height": 1, "tilewidth": 16, "tileheight": 16, "infinite": true, "layers": [
+ {"name": "ground", "type": "tilelayer", "width": 4, "height": 1, %s"chunks": [
+  {"x": -2, "y": 0, "width": 2, "height": 1, "data": %s},
+  {"x": 0, "y": 0, "width": 2, "height": 1, "data": %s}
+ ]}
+]}`, tt.fields, tt.chunks[0], tt.chunks[1])
+
+			m, err := DecodeTMJ(strings.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !m.Infinite {
+				t.Fatal("map is not infinite")
+			}
+
+			ground := m.Layers[0]
+			if len(ground.Data) != 0 || len(ground.Chunks) != 2 {
+				t.Fatalf("ground has %d tiles and %d chunks, want 2 chunks", len(ground.Data), len(ground.Chunks))
+			}
+			if chunk := ground.Chunks[0]; chunk.X != -2 || chunk.Width != 2 || !slices.Equal(chunk.Data, []GID{1, 0}) {
+				t.Fatalf("first chunk = %+v", chunk)
+			}
+			if gid := ground.TileAt(1, 0); gid.ID() != 2 || !gid.FlippedDiagonally() {
+				t.Fatalf("tile at 1,0 = %#x, want tile 2 flipped diagonally", uint32(gid))
+			}
+
+			short := strings.Replace(data, `"width": 2, "height": 1`, `"width": 3, "height": 1`, 1)
+			if _, err := DecodeTMJ(strings.NewReader(short)); !errors.Is(err, ErrDataSizeMismatch) {
+				t.Fatalf("DecodeTMJ() of a short chunk error = %v, want %v", err, ErrDataSizeMismatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package tiled
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ======================================================
+// TMX/TSX Decoding
+// ======================================================
+
+type xmlMap struct {
+	Orientation string        `xml:"orientation,attr"`
+	RenderOrder string        `xml:"renderorder,attr"`
+	Class       string        `xml:"class,attr"`
+	Width       int           `xml:"width,attr"`
+	Height      int           `xml:"height,attr"`
+	TileWidth   int           `xml:"tilewidth,attr"`
+	TileHeight  int           `xml:"tileheight,attr"`
+	Infinite    int           `xml:"infinite,attr"`
+	Properties  xmlProperties `xml:"properties"`
+	Tilesets    []xmlTileset  `xml:"tileset"`
+	Layers      []xmlLayer    `xml:",any"`
+}
+
+type xmlTileset struct {
+	FirstGID   uint32        `xml:"firstgid,attr"`
+	Source     string        `xml:"source,attr"`
+	Name       string        `xml:"name,attr"`
+	Class      string        `xml:"class,attr"`
+	TileWidth  int           `xml:"tilewidth,attr"`
+	TileHeight int           `xml:"tileheight,attr"`
+	TileCount  int           `xml:"tilecount,attr"`
+	Columns    int           `xml:"columns,attr"`
+	Spacing    int           `xml:"spacing,attr"`
+	Margin     int           `xml:"margin,attr"`
+	Image      *xmlImage     `xml:"image"`
+	Tiles      []xmlTile     `xml:"tile"`
+	Properties xmlProperties `xml:"properties"`
+}
+
+type xmlTile struct {
+	ID          int           `xml:"id,attr"`
+	Class       string        `xml:"class,attr"`
+	Type        string        `xml:"type,attr"`
+	Image       *xmlImage     `xml:"image"`
+	ObjectGroup *xmlLayer     `xml:"objectgroup"`
+	Properties  xmlProperties `xml:"properties"`
+	Animation   *struct {
+		Frames []struct {
+			TileID   int `xml:"tileid,attr"`
+			Duration int `xml:"duration,attr"`
+		} `xml:"frame"`
+	} `xml:"animation"`
+}
+
+type xmlImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type xmlLayer struct {
+	XMLName    xml.Name
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Class      string        `xml:"class,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Opacity    *float64      `xml:"opacity,attr"`
+	OffsetX    float64       `xml:"offsetx,attr"`
+	OffsetY    float64       `xml:"offsety,attr"`
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	Data       *xmlData      `xml:"data"`
+	Objects    []xmlObject   `xml:"object"`
+	Image      *xmlImage     `xml:"image"`
+	Properties xmlProperties `xml:"properties"`
+	Layers     []xmlLayer    `xml:",any"`
+}
+
+type xmlData struct {
+	Encoding    string     `xml:"encoding,attr"`
+	Compression string     `xml:"compression,attr"`
+	Text        string     `xml:",chardata"`
+	Tiles       []xmlGID   `xml:"tile"`
+	Chunks      []xmlChunk `xml:"chunk"`
+}
+
+type xmlChunk struct {
+	X      int      `xml:"x,attr"`
+	Y      int      `xml:"y,attr"`
+	Width  int      `xml:"width,attr"`
+	Height int      `xml:"height,attr"`
+	Text   string   `xml:",chardata"`
+	Tiles  []xmlGID `xml:"tile"`
+}
+
+type xmlGID struct {
+	GID uint32 `xml:"gid,attr"`
+}
+
+type xmlObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Class      string        `xml:"class,attr"`
+	Type       string        `xml:"type,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	Rotation   float64       `xml:"rotation,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Point      *struct{}     `xml:"point"`
+	Polygon    *xmlPoints    `xml:"polygon"`
+	Polyline   *xmlPoints    `xml:"polyline"`
+	Properties xmlProperties `xml:"properties"`
+}
+
+type xmlPoints struct {
+	Points string `xml:"points,attr"`
+}
+
+type xmlProperties struct {
+	Properties []struct {
+		Name  string `xml:"name,attr"`
+		Type  string `xml:"type,attr"`
+		Value string `xml:"value,attr"`
+		Text  string `xml:",chardata"`
+	} `xml:"property"`
+}
+
+// DecodeTMX decodes a map in the XML format.
+func DecodeTMX(r io.Reader) (*Map, error) {
+	var x xmlMap
+	if err := xml.NewDecoder(r).Decode(&x); err != nil {
+		return nil, err
+	}
+
+	m := &Map{
+		Orientation: x.Orientation,
+		RenderOrder: x.RenderOrder,
+		Class:       x.Class,
+		Width:       x.Width,
+		Height:      x.Height,
+		TileWidth:   x.TileWidth,
+		TileHeight:  x.TileHeight,
+		Infinite:    x.Infinite != 0,
+		Properties:  x.Properties.convert(),
+	}
+
+	for _, xt := range x.Tilesets {
+		ref := &TilesetRef{
+			FirstGID: GID(xt.FirstGID),
+			Source:   xt.Source,
+		}
+		if xt.Source == "" {
+			tileset, err := xt.convert()
+			if err != nil {
+				return nil, err
+			}
+			ref.Tileset = tileset
+		}
+		m.Tilesets = append(m.Tilesets, ref)
+	}
+
+	layers, err := convertXMLLayers(x.Layers)
+	if err != nil {
+		return nil, err
+	}
+	m.Layers = layers
+
+	return m, nil
+}
+
+// DecodeTSX decodes an external tileset in the XML format.
+func DecodeTSX(r io.Reader) (*Tileset, error) {
+	var x xmlTileset
+	if err := xml.NewDecoder(r).Decode(&x); err != nil {
+		return nil, err
+	}
+	return x.convert()
+}
+
+func (x xmlTileset) convert() (*Tileset, error) {
+	tileset := &Tileset{
+		Name:       x.Name,
+		Class:      x.Class,
+		TileWidth:  x.TileWidth,
+		TileHeight: x.TileHeight,
+		TileCount:  x.TileCount,
+		Columns:    x.Columns,
+		Spacing:    x.Spacing,
+		Margin:     x.Margin,
+		Image:      x.Image.convert(),
+		Properties: x.Properties.convert(),
+	}
+
+	for _, xt := range x.Tiles {
+		tile := &Tile{
+			ID:         xt.ID,
+			Class:      firstNonEmpty(xt.Class, xt.Type),
+			Image:      xt.Image.convert(),
+			Properties: xt.Properties.convert(),
+		}
+
+		if xt.ObjectGroup != nil {
+			objects, err := convertXMLObjects(xt.ObjectGroup.Objects)
+			if err != nil {
+				return nil, err
+			}
+			tile.Objects = objects
+		}
+
+		if xt.Animation != nil {
+			for _, frame := range xt.Animation.Frames {
+				tile.Animation = append(tile.Animation, Frame{
+					TileID:   frame.TileID,
+					Duration: time.Duration(frame.Duration) * time.Millisecond,
+				})
+			}
+		}
+
+		tileset.Tiles = append(tileset.Tiles, tile)
+	}
+
+	return tileset, nil
+}
+
+func (x *xmlImage) convert() *Image {
+	if x == nil {
+		return nil
+	}
+	return &Image{Source: x.Source, Width: x.Width, Height: x.Height}
+}
+
+func (x xmlProperties) convert() Properties {
+	if len(x.Properties) == 0 {
+		return nil
+	}
+
+	properties := make(Properties, 0, len(x.Properties))
+	for _, p := range x.Properties {
+		value := p.Value
+		if value == "" {
+			value = strings.TrimSpace(p.Text)
+		}
+		properties = append(properties, Property{Name: p.Name, Type: p.Type, Value: value})
+	}
+	return properties
+}
+
+func convertXMLLayers(xs []xmlLayer) ([]*Layer, error) {
+	layers := make([]*Layer, 0, len(xs))
+
+	for _, x := range xs {
+		layer := &Layer{
+			ID:         x.ID,
+			Name:       x.Name,
+			Class:      x.Class,
+			Visible:    x.Visible == nil || *x.Visible != 0,
+			Opacity:    1,
+			OffsetX:    x.OffsetX,
+			OffsetY:    x.OffsetY,
+			Width:      x.Width,
+			Height:     x.Height,
+			Properties: x.Properties.convert(),
+		}
+		if x.Opacity != nil {
+			layer.Opacity = *x.Opacity
+		}
+
+		switch x.XMLName.Local {
+		case "layer":
+			layer.Type = TileLayer
+			if err := layer.convertXMLData(x.Data); err != nil {
+				return nil, fmt.Errorf("layer %s: %w", x.Name, err)
+			}
+		case "objectgroup":
+			layer.Type = ObjectGroup
+			objects, err := convertXMLObjects(x.Objects)
+			if err != nil {
+				return nil, fmt.Errorf("layer %s: %w", x.Name, err)
+			}
+			layer.Objects = objects
+		case "imagelayer":
+			layer.Type = ImageLayer
+			layer.Image = x.Image.convert()
+		case "group":
+			layer.Type = GroupLayer
+			children, err := convertXMLLayers(x.Layers)
+			if err != nil {
+				return nil, err
+			}
+			layer.Layers = children
+		default:
+			continue
+		}
+
+		layers = append(layers, layer)
+	}
+
+	return layers, nil
+}
+
+func (l *Layer) convertXMLData(x *xmlData) error {
+	if x == nil {
+		return nil
+	}
+
+	if len(x.Chunks) == 0 {
+		gids, err := decodeXMLTiles(x.Encoding, x.Compression, x.Text, x.Tiles)
+		if err != nil {
+			return err
+		}
+		if err := checkTileData(gids, l.Width, l.Height); err != nil {
+			return err
+		}
+		l.Data = gids
+		return nil
+	}
+
+	for _, xc := range x.Chunks {
+		gids, err := decodeXMLTiles(x.Encoding, x.Compression, xc.Text, xc.Tiles)
+		if err != nil {
+			return err
+		}
+		if err := checkTileData(gids, xc.Width, xc.Height); err != nil {
+			return err
+		}
+		l.Chunks = append(l.Chunks, Chunk{X: xc.X, Y: xc.Y, Width: xc.Width, Height: xc.Height, Data: gids})
+	}
+
+	return nil
+}
+
+func decodeXMLTiles(encoding, compression, text string, tiles []xmlGID) ([]GID, error) {
+	if encoding != "" {
+		return decodeTileData(encoding, compression, text)
+	}
+
+	gids := make([]GID, len(tiles))
+	for i, tile := range tiles {
+		gids[i] = GID(tile.GID)
+	}
+	return gids, nil
+}
+
+func convertXMLObjects(xs []xmlObject) ([]*Object, error) {
+	objects := make([]*Object, 0, len(xs))
+
+	for _, x := range xs {
+		object := &Object{
+			ID:         x.ID,
+			Name:       x.Name,
+			Class:      firstNonEmpty(x.Class, x.Type),
+			X:          x.X,
+			Y:          x.Y,
+			Width:      x.Width,
+			Height:     x.Height,
+			Rotation:   x.Rotation,
+			GID:        GID(x.GID),
+			Visible:    x.Visible == nil || *x.Visible != 0,
+			Ellipse:    x.Ellipse != nil,
+			Point:      x.Point != nil,
+			Properties: x.Properties.convert(),
+		}
+
+		if x.Polygon != nil {
+			points, err := parsePoints(x.Polygon.Points)
+			if err != nil {
+				return nil, err
+			}
+			object.Polygon = points
+		}
+
+		if x.Polyline != nil {
+			points, err := parsePoints(x.Polyline.Points)
+			if err != nil {
+				return nil, err
+			}
+			object.Polyline = points
+		}
+
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+package tiled
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const levelTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" renderorder="right-down" width="2" height="2" tilewidth="16" tileheight="16" infinite="0">
+ <properties>
+  <property name="gravity" type="float" value="9.8"/>
+  <property name="title">Level One</property>
+ </properties>
+ <tileset firstgid="1" name="tiles" tilewidth="16" tileheight="32" tilecount="4" columns="2">
+  <image source="tiles.png" width="32" height="64"/>
+  <tile id="1" type="wall">
+   <objectgroup draworder="index">
+    <object id="1" x="2" y="4" width="12" height="8"/>
+   </objectgroup>
+  </tile>
+ </tileset>
+ <tileset firstgid="5" source="props.tsx"/>
+ <layer id="1" name="ground" width="2" height="2">
+  <data encoding="csv">
+2,0,
+2147483650,1
+</data>
+ </layer>
+ <group id="2" name="world" offsetx="100" offsety="50">
+  <objectgroup id="3" name="collision" offsetx="10" offsety="5">
+   <object id="1" x="0" y="0" width="32" height="16"/>
+   <object id="2" x="4" y="4"><point/></object>
+  </objectgroup>
+  <objectgroup id="4" name="decor" visible="0">
+   <object id="3" class="collision" x="1" y="2" width="3" height="4"/>
+   <object id="4" name="lamp" x="8" y="8" width="4" height="4">
+    <properties>
+     <property name="light" type="bool" value="true"/>
+    </properties>
+   </object>
+   <object id="5" name="path" x="0" y="0">
+    <polyline points="0,0 10,-5 20,0"/>
+   </object>
+  </objectgroup>
+ </group>
+</map>`
+
+const propsTSX = `<?xml version="1.0" encoding="UTF-8"?>
+<tileset name="props" tilewidth="16" tileheight="16" tilecount="2" columns="2">
+ <image source="props.png" width="32" height="16"/>
+ <tile id="0">
+  <animation>
+   <frame tileid="0" duration="100"/>
+   <frame tileid="1" duration="150"/>
+  </animation>
+ </tile>
+</tileset>`
+
+func TestDecodeTMX(t *testing.T) {
+	m, err := DecodeTMX(strings.NewReader(levelTMX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLevel(t, m)
+
+	err = m.ResolveTilesets(func(source string) (*Tileset, error) {
+		if source != "props.tsx" {
+			return nil, fmt.Errorf("unexpected tileset %q", source)
+		}
+		return DecodeTSX(strings.NewReader(propsTSX))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := m.Tilesets[1].Tileset
+	if props == nil || props.Name != "props" {
+		t.Fatalf("resolved tileset = %+v", props)
+	}
+	if tile, ok := props.Tile(0); !ok || len(tile.Animation) != 2 || tile.Animation[1].Duration.Milliseconds() != 150 {
+		t.Fatalf("animated tile = %+v", tile)
+	}
+}
+
+func TestDecodeTMXTileData(t *testing.T) {
+	gids := []GID{1, 0, 2 | FlipVertical, 3}
+
+	layer := func(data string) string {
+		return `<map width="2" height="2" tilewidth="16" tileheight="16"><layer name="ground" width="2" height="2">` + data + `</layer></map>`
+	}
+
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{name: "csv", data: `<data encoding="csv">1,0,1073741826,3</data>`},
+		{name: "tile elements", data: `<data><tile gid="1"/><tile/><tile gid="1073741826"/><tile gid="3"/></data>`},
+		{name: "base64", data: `<data encoding="base64">` + encodeTiles(t, "", gids...) + `</data>`},
+		{name: "base64 gzip", data: `<data encoding="base64" compression="gzip">` + encodeTiles(t, "gzip", gids...) + `</data>`},
+		{name: "base64 zlib", data: `<data encoding="base64" compression="zlib">` + encodeTiles(t, "zlib", gids...) + `</data>`},
+		{name: "base64 zstd", data: `<data encoding="base64" compression="zstd">` + encodeTiles(t, "zstd", gids...) + `</data>`},
+		{name: "too few tiles", data: `<data encoding="csv">1,0,3</data>`, err: ErrDataSizeMismatch},
+		{name: "unknown compression", data: `<data encoding="base64" compression="lzma">` + encodeTiles(t, "", gids...) + `</data>`, err: ErrEncodingUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := DecodeTMX(strings.NewReader(layer(tt.data)))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("DecodeTMX() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := m.Layers[0].Data; !slices.Equal(got, gids) {
+				t.Fatalf("tiles = %v, want %v", got, gids)
+			}
+		})
+	}
+}
+
+func TestDecodeTMXChunks(t *testing.T) {
+	data := fmt.Sprintf(`<map orientation="orthogonal" width="4" height="1" tilewidth="16" tileheight="16" infinite="1">
+ <layer id="1" name="ground" width="4" height="1">
+  <data encoding="base64" compression="zlib">
+   <chunk x="-2" y="0" width="2" height="1">%s</chunk>
+   <chunk x="0" y="0" width="2" height="1">%s</chunk>
+  </data>
+ </layer>
+</map>`, encodeTiles(t, "zlib", 1, 0), encodeTiles(t, "zlib", 0, 2|FlipDiagonal))
+
+	m, err := DecodeTMX(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Infinite {
+		t.Fatal("map is not infinite")
+	}
+
+	ground := m.Layers[0]
+	if len(ground.Data) != 0 || len(ground.Chunks) != 2 {
+		t.Fatalf("ground has %d tiles and %d chunks, want 2 chunks", len(ground.Data), len(ground.Chunks))
+	}
+	if chunk := ground.Chunks[0]; chunk.X != -2 || chunk.Width != 2 || !slices.Equal(chunk.Data, []GID{1, 0}) {
+		t.Fatalf("first chunk = %+v", chunk)
+	}
+	if gid := ground.TileAt(1, 0); gid.ID() != 2 || !gid.FlippedDiagonally() {
+		t.Fatalf("tile at 1,0 = %#x, want tile 2 flipped diagonally", uint32(gid))
+	}
+
+	short := strings.Replace(data, `width="2" height="1">`, `width="3" height="1">`, 1)
+	if _, err := DecodeTMX(strings.NewReader(short)); !errors.Is(err, ErrDataSizeMismatch) {
+		t.Fatalf("DecodeTMX() of a short chunk error = %v, want %v", err, ErrDataSizeMismatch)
+	}
+}
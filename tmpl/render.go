@@ -12,7 +12,18 @@ import (
 
 // Render processes a text template with the given name, content, and data context,
 func Render(name string, content string, data any) []byte {
-	tmpl := template.New(name)
+	out, err := Execute(name, content, data)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// Execute is Render for templates that may be invalid, such as user provided text, and returns parse
+// and execution errors instead of panicking. Options are passed to template.Option, such as
+// "missingkey=error".
+func Execute(name string, content string, data any, options ...string) ([]byte, error) {
+	tmpl := template.New(name).Option(options...)
 	tmpl.Funcs(template.FuncMap{
 		"array":  func(v ...any) []any { return v },
 		"append": func(slice []any, v ...any) []any { return append(slice, v...) },
@@ -146,12 +157,14 @@ func Render(name string, content string, data any) []byte {
 		},
 		"trim": strings.Trim,
 	})
-	tmpl = template.Must(tmpl.Parse(string(content)))
+	if _, err := tmpl.Parse(content); err != nil {
+		return nil, err
+	}
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
-		panic(err)
+		return nil, err
 	}
-	return buf.Bytes()
+	return buf.Bytes(), nil
 }
 
 // RenderH is like Render but also takes helper templates to be included.